@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	_ "time/tzdata" // Validate time zones against the embedded database, not the host's
+
+	"airport-system/internal/airport"
+	"airport-system/platform/database"
+	"airport-system/platform/logger"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: airportimport <file.csv | ->
+
+Imports airports from an OpenFlights airports.dat file, or from a CSV whose header row names
+the columns (iata_code, icao_code, name, city, country, timezone, latitude, longitude).
+Existing airports are updated. "-" reads the file from standard input.`
+
+func main() {
+	// 1. Load .env
+	if err := godotenv.Load(); err != nil {
+		// Ignore error if file not found
+	}
+
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var in io.Reader = os.Stdin
+	if os.Args[1] != "-" {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	// 2. Initialize Logger
+	log := logger.New()
+
+	// 3. Connect to Database
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		dsn = "host=localhost user=postgres password=postgres dbname=airport_db port=5432 sslmode=disable"
+	}
+	db, err := database.NewPostgresDB(dsn)
+	if err != nil {
+		log.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	service := airport.NewService(airport.NewRepository(db), database.NewTxManager(db), log)
+	result, err := service.Import(ctx, in)
+	if err != nil {
+		log.Error("Import failed", "error", err)
+		os.Exit(1)
+	}
+
+	for _, e := range result.Errors {
+		fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Line, e.Message)
+	}
+	fmt.Printf("format: %s, imported: %d, skipped: %d, rejected: %d\n",
+		result.Format, result.Imported, result.Skipped, len(result.Errors))
+}
@@ -2,17 +2,26 @@ package main
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Airport time zones must resolve even where the host has no zoneinfo
 
+	"airport-system/internal/airport"
 	"airport-system/internal/airportops"
 	"airport-system/internal/auth"
 	"airport-system/internal/booking"
+	"airport-system/internal/fids"
+	"airport-system/internal/fleet"
 	"airport-system/internal/flight"
+	"airport-system/internal/notification"
 	"airport-system/internal/passenger"
+	"airport-system/internal/payment"
+	"airport-system/internal/pricing"
+	"airport-system/internal/seating"
 	"airport-system/platform/database"
 	"airport-system/platform/logger"
 	"airport-system/platform/middleware"
@@ -43,61 +52,176 @@ func main() {
 	defer db.Close()
 	log.Info("Connected to database")
 
-	// 4. Initialize TxManager
+	// 4. Check schema version (AUTO_MIGRATE=true applies pending migrations instead of refusing to start)
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Error("Failed to load migrations", "error", err)
+		os.Exit(1)
+	}
+	if os.Getenv("AUTO_MIGRATE") == "true" {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Error("Failed to apply migrations", "error", err)
+			os.Exit(1)
+		}
+		for _, m := range applied {
+			log.Info("Applied migration", "version", m.Version, "name", m.Name)
+		}
+	} else if err := migrator.EnsureCurrent(context.Background()); err != nil {
+		log.Error("Refusing to start: run `go run ./cmd/migrate up` or set AUTO_MIGRATE=true", "error", err)
+		os.Exit(1)
+	}
+
+	// 5. Initialize TxManager
 	txManager := database.NewTxManager(db)
-	_ = txManager
 
-	// 5. Setup Gin
+	// 6. Setup Gin
 	router := gin.New()
 	router.Use(gin.Recovery())
 	router.Use(middleware.RequestLogger(log))
 	router.Use(middleware.CORS())
+	router.Use(middleware.ErrorHandler(log))
 
-	// 6. Config
+	// 7. Config
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "default-secret-key-change-it"
 	}
+	// Token lifetimes (Go duration strings, e.g. "15m", "720h"); empty means the auth defaults
+	accessTokenTTL, err := parseDurationEnv("ACCESS_TOKEN_TTL")
+	if err != nil {
+		log.Error("Invalid ACCESS_TOKEN_TTL", "error", err)
+		os.Exit(1)
+	}
+	refreshTokenTTL, err := parseDurationEnv("REFRESH_TOKEN_TTL")
+	if err != nil {
+		log.Error("Invalid REFRESH_TOKEN_TTL", "error", err)
+		os.Exit(1)
+	}
+	// How long booked tickets wait for payment; empty means payment.DefaultIntentTTL
+	paymentTimeout, err := parseDurationEnv("PAYMENT_TIMEOUT")
+	if err != nil {
+		log.Error("Invalid PAYMENT_TIMEOUT", "error", err)
+		os.Exit(1)
+	}
+	// How long a locked price quote is honoured; empty means pricing.DefaultQuoteTTL
+	quoteTTL, err := parseDurationEnv("PRICE_QUOTE_TTL")
+	if err != nil {
+		log.Error("Invalid PRICE_QUOTE_TTL", "error", err)
+		os.Exit(1)
+	}
+	var gateway payment.PaymentGateway
+	switch name := os.Getenv("PAYMENT_GATEWAY"); name {
+	case "", "mock":
+		webhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+		if webhookSecret == "" {
+			webhookSecret = "default-webhook-secret-change-it"
+		}
+		gateway = payment.NewMockGateway(webhookSecret)
+	default:
+		log.Error("Unknown PAYMENT_GATEWAY", "gateway", name)
+		os.Exit(1)
+	}
+
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
 
 	// --- MODULE: AUTH ---
 	// Wiring dependencies: Repo -> Service -> Handler
 	authRepo := auth.NewRepository(db)
-	authService := auth.NewService(authRepo, log, jwtSecret)
+	authService := auth.NewService(authRepo, log, auth.Config{
+		JWTSecret:       jwtSecret,
+		AccessTokenTTL:  accessTokenTTL,
+		RefreshTokenTTL: refreshTokenTTL,
+		InvitationURL:   os.Getenv("INVITATION_URL"), // Empty means auth.DefaultInvitationURL
+	})
 	authHandler := auth.NewHandler(authService)
 
 	// API Group
 	v1 := router.Group("/api/v1")
 	{
+		// Middleware
+		authMiddleware := auth.AuthMiddleware(authService)
+
 		// Register Auth Routes
-		auth.RegisterRoutes(v1, authHandler)
+		auth.RegisterRoutes(v1, authHandler, authMiddleware)
 
-		// Middleware
-		authMiddleware := auth.AuthMiddleware(jwtSecret)
+		// Register Airport Routes
+		airportRepo := airport.NewRepository(db)
+		airportService := airport.NewService(airportRepo, txManager, log)
+		airportHandler := airport.NewHandler(airportService)
+		airport.RegisterRoutes(v1, airportHandler, authMiddleware)
+
+		// Register Seating Routes
+		seatRepo := seating.NewRepository(db)
+		seatService := seating.NewService(seatRepo, txManager, log)
+		seatHandler := seating.NewHandler(seatService)
+		seating.RegisterRoutes(v1, seatHandler, authMiddleware)
+
+		// Register Fleet Routes
+		fleetRepo := fleet.NewRepository(db)
+		fleetService := fleet.NewService(fleetRepo, seatService, txManager, log)
+		fleetHandler := fleet.NewHandler(fleetService)
+		fleet.RegisterRoutes(v1, fleetHandler, authMiddleware)
 
 		// Register Flight Routes
 		flightRepo := flight.NewRepository(db)
-		flightService := flight.NewService(flightRepo, log)
+		flightService := flight.NewService(flightRepo, airportService, fleetService, seatService, txManager, log)
 		flightHandler := flight.NewHandler(flightService)
 		flight.RegisterRoutes(v1, flightHandler, authMiddleware)
 
 		// Register Airport Ops Routes
 		opsRepo := airportops.NewRepository(db)
-		opsService := airportops.NewService(opsRepo, log)
+		opsService := airportops.NewService(opsRepo, txManager, log)
 		opsHandler := airportops.NewHandler(opsService)
 		airportops.RegisterRoutes(v1, opsHandler, authMiddleware)
 
-		// Register Passenger Module (Internal dependency, no public routes for now)
+		// Register Passenger Routes
 		passRepo := passenger.NewRepository(db)
-		passService := passenger.NewService(passRepo, log)
+		passService := passenger.NewService(passRepo, txManager, log)
+		passHandler := passenger.NewHandler(passService)
+		passenger.RegisterRoutes(v1, passHandler, authMiddleware)
+
+		// Payments have no routes of their own; bookings drive them
+		paymentService := payment.NewService(payment.NewRepository(db), gateway, log, payment.Config{
+			Currency:  os.Getenv("PAYMENT_CURRENCY"), // Empty means payment.DefaultCurrency
+			IntentTTL: paymentTimeout,
+		})
+
+		// Register Pricing Routes
+		pricingRepo := pricing.NewRepository(db)
+		pricingService := pricing.NewService(pricingRepo, flightRepo, airportService, txManager, log, pricing.Config{
+			Currency: paymentService.Currency(),
+			QuoteTTL: quoteTTL,
+		})
+		pricingHandler := pricing.NewHandler(pricingService)
+		pricing.RegisterRoutes(v1, pricingHandler, authMiddleware)
+
+		// Register Notification Routes
+		notifRepo := notification.NewRepository(db)
+		notifService := notification.NewService(notifRepo, log)
+		notifHandler := notification.NewHandler(notifService)
+		notification.RegisterRoutes(v1, notifHandler, authMiddleware)
+
+		// Register FIDS Routes
+		fidsRepo := fids.NewRepository(db)
+		fidsService := fids.NewService(fidsRepo, airportService, log)
+		fidsHandler := fids.NewHandler(fidsService)
+		fids.RegisterRoutes(v1, fidsHandler, authMiddleware)
 
 		// Register Booking Routes
 		bookingRepo := booking.NewRepository(db)
-		bookingService := booking.NewService(bookingRepo, flightRepo, txManager, opsService, passService, log)
+		bookingService := booking.NewService(bookingRepo, flightRepo, flightService, txManager, opsService, passService, seatService, paymentService, pricingService, notifService, log)
 		bookingHandler := booking.NewHandler(bookingService)
 		booking.RegisterRoutes(v1, bookingHandler, authMiddleware)
+
+		go runBookingJobs(jobsCtx, bookingService, paymentService, log)
+		go runScheduleJobs(jobsCtx, flightService, log)
+		go fidsService.Listen(jobsCtx, dsn)
 	}
 
-	// 7. Run Server
+	// 8. Run Server
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -121,6 +245,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Info("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -132,3 +257,71 @@ func main() {
 
 	log.Info("Server exiting")
 }
+
+// parseDurationEnv reads a duration from the environment, returning 0 if it is unset.
+func parseDurationEnv(key string) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// runBookingJobs periodically releases tickets whose payment deadline has passed, passes on
+// waitlist offers that were not confirmed in time and retries refunds the gateway has not confirmed.
+func runBookingJobs(ctx context.Context, bookingService *booking.Service, paymentService *payment.Service, log *slog.Logger) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := bookingService.ExpirePayments(ctx)
+			if err != nil {
+				log.Error("Failed to expire payments", "error", err)
+			}
+			if n > 0 {
+				log.Info("Expired unpaid bookings", "intents", n)
+			}
+
+			n, err = bookingService.ExpireWaitlistOffers(ctx)
+			if err != nil {
+				log.Error("Failed to expire waitlist offers", "error", err)
+			}
+			if n > 0 {
+				log.Info("Expired waitlist offers", "offers", n)
+			}
+
+			n, err = paymentService.ProcessPendingRefunds(ctx)
+			if err != nil {
+				log.Error("Failed to process pending refunds", "error", err)
+			}
+			if n > 0 {
+				log.Info("Processed pending refunds", "refunds", n)
+			}
+		}
+	}
+}
+
+// runScheduleJobs generates flights from recurring schedules as the horizon rolls forward,
+// once at startup and hourly after that.
+func runScheduleJobs(ctx context.Context, flightService *flight.Service, log *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		n, err := flightService.GenerateScheduledFlights(ctx)
+		if err != nil {
+			log.Error("Failed to generate scheduled flights", "error", err)
+		}
+		if n > 0 {
+			log.Info("Generated scheduled flights", "flights", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
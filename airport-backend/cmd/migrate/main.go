@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"airport-system/platform/database"
+	"airport-system/platform/logger"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down            roll back the most recently applied migration
+  status          list migrations and whether they are applied
+  goto <version>  migrate up or down to the given version (0 rolls back everything)`
+
+func main() {
+	// 1. Load .env
+	if err := godotenv.Load(); err != nil {
+		// Ignore error if file not found
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// 2. Initialize Logger
+	log := logger.New()
+
+	// 3. Connect to Database
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		dsn = "host=localhost user=postgres password=postgres dbname=airport_db port=5432 sslmode=disable"
+	}
+	db, err := database.NewPostgresDB(dsn)
+	if err != nil {
+		log.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Error("Failed to load migrations", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	var applied []database.Migration
+	switch os.Args[1] {
+	case "up":
+		applied, err = migrator.Up(ctx)
+	case "down":
+		applied, err = migrator.Down(ctx)
+	case "goto":
+		if len(os.Args) < 3 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		target, parseErr := strconv.ParseInt(os.Args[2], 10, 64)
+		if parseErr != nil || target < 0 {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", os.Args[2])
+			os.Exit(2)
+		}
+		applied, err = migrator.Goto(ctx, target)
+	case "status":
+		if err := printStatus(ctx, migrator); err != nil {
+			log.Error("Failed to read migration status", "error", err)
+			os.Exit(1)
+		}
+		return
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	for _, m := range applied {
+		log.Info("Migrated", "command", os.Args[1], "version", m.Version, "name", m.Name)
+	}
+	if err != nil {
+		log.Error("Migration failed", "error", err)
+		os.Exit(1)
+	}
+	if len(applied) == 0 {
+		log.Info("Nothing to migrate")
+	}
+}
+
+func printStatus(ctx context.Context, migrator *database.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-8s %-40s %s\n", "VERSION", "NAME", "APPLIED AT")
+	for _, st := range statuses {
+		appliedAt := "pending"
+		if st.AppliedAt != nil {
+			appliedAt = st.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%-8d %-40s %s\n", st.Version, st.Name, appliedAt)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package airport
+
+import (
+	"airport-system/internal/auth"
+	"airport-system/platform/apperror"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize caps the size of an uploaded airport dataset.
+const maxImportSize = 32 << 20
+
+// Handler manages HTTP requests for airports.
+type Handler struct {
+	Service *Service
+}
+
+// NewHandler creates a new airport handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{Service: service}
+}
+
+// List handles airport lookup by code, name, city or country.
+func (h *Handler) List(c *gin.Context) {
+	var params ListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	airports, err := h.Service.ListAirports(c.Request.Context(), params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, airports)
+}
+
+// Get handles retrieving an airport by IATA code.
+func (h *Handler) Get(c *gin.Context) {
+	a, err := h.Service.GetAirport(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, a)
+}
+
+// Save handles adding or replacing an airport (ADMIN only).
+func (h *Handler) Save(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	var req CreateAirportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	a, err := h.Service.SaveAirport(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, a)
+}
+
+// Import handles a bulk airport import (ADMIN only). The CSV is taken from the "file" field of
+// a multipart form, or from the raw request body otherwise.
+func (h *Handler) Import(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result, err := h.Service.Import(c.Request.Context(), body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
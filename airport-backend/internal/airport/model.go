@@ -0,0 +1,79 @@
+package airport
+
+import (
+	"time"
+)
+
+// Airport is an airport's reference data. Flights refer to airports by IATA code.
+type Airport struct {
+	ID        int64     `json:"id"`
+	IATACode  string    `json:"iata_code"`
+	ICAOCode  *string   `json:"icao_code"`
+	Name      string    `json:"name"`
+	City      string    `json:"city"`
+	Country   string    `json:"country"`
+	Timezone  string    `json:"timezone"` // IANA time zone, e.g. Europe/London
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// MinConnectionMinutes is the least time between arriving on one leg and departing on the
+	// next for the two to be sold as a connection here.
+	MinConnectionMinutes int `json:"min_connection_minutes"`
+}
+
+// DefaultMinConnection is the minimum connection time of airports without one of their own,
+// including airports missing from the reference data.
+const DefaultMinConnection = 45 * time.Minute
+
+// MinConnection returns the airport's minimum connection time.
+func (a *Airport) MinConnection() time.Duration {
+	return time.Duration(a.MinConnectionMinutes) * time.Minute
+}
+
+// Location returns the airport's time zone.
+func (a *Airport) Location() (*time.Location, error) {
+	return time.LoadLocation(a.Timezone)
+}
+
+// CreateAirportRequest defines the body for adding or replacing an airport.
+type CreateAirportRequest struct {
+	IATACode  string   `json:"iata_code" binding:"required,len=3"`
+	ICAOCode  string   `json:"icao_code" binding:"omitempty,len=4"`
+	Name      string   `json:"name" binding:"required"`
+	City      string   `json:"city"`
+	Country   string   `json:"country"`
+	Timezone  string   `json:"timezone" binding:"required"`
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+
+	MinConnectionMinutes *int `json:"min_connection_minutes" binding:"omitempty,min=0,max=1440"` // Optional: kept as is, or DefaultMinConnection for new airports
+}
+
+// ListParams defines filters for listing airports.
+type ListParams struct {
+	Query   string `form:"q"`       // Matches the IATA or ICAO code, name or city
+	Country string `form:"country"` // Exact country name
+}
+
+// Import formats. OpenFlights is the headerless airports.dat layout; a CSV with a header row
+// is read by column name instead.
+const (
+	FormatOpenFlights = "openflights"
+	FormatHeader      = "header"
+)
+
+// ImportResult reports what a bulk import did.
+type ImportResult struct {
+	Format   string        `json:"format"`
+	Imported int           `json:"imported"` // Airports added or updated
+	Skipped  int           `json:"skipped"`  // Rows without an IATA code
+	Errors   []ImportError `json:"errors"`   // Rows rejected by validation
+}
+
+// ImportError is a row rejected during an import.
+type ImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
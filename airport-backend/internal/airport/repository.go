@@ -0,0 +1,145 @@
+package airport
+
+import (
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrICAOExists is returned when an ICAO code already belongs to another airport.
+var ErrICAOExists = apperror.Conflict("icao_code_exists", "ICAO code belongs to another airport")
+
+// Repository handles database interactions for airports.
+type Repository struct {
+	DB *sql.DB
+}
+
+// NewRepository creates a new airport repository.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{DB: db}
+}
+
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+const airportColumns = `id, iata_code, icao_code, name, city, country, timezone, latitude, longitude, created_at, updated_at, min_connection_minutes`
+
+func scanAirport(row interface{ Scan(...any) error }, a *Airport) error {
+	return row.Scan(
+		&a.ID, &a.IATACode, &a.ICAOCode, &a.Name, &a.City, &a.Country, &a.Timezone,
+		&a.Latitude, &a.Longitude, &a.CreatedAt, &a.UpdatedAt, &a.MinConnectionMinutes,
+	)
+}
+
+// Upsert inserts an airport or replaces the reference data of the airport with its IATA code.
+// A nil minConnectionMinutes keeps an existing airport's minimum connection time and gives a
+// new one the column default.
+func (r *Repository) Upsert(ctx context.Context, a *Airport, minConnectionMinutes *int) error {
+	query := `
+		INSERT INTO airports (iata_code, icao_code, name, city, country, timezone, latitude, longitude, min_connection_minutes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9::int, $10::int), NOW(), NOW())
+		ON CONFLICT (iata_code) DO UPDATE SET
+			icao_code = EXCLUDED.icao_code,
+			name = EXCLUDED.name,
+			city = EXCLUDED.city,
+			country = EXCLUDED.country,
+			timezone = EXCLUDED.timezone,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			min_connection_minutes = COALESCE($9::int, airports.min_connection_minutes),
+			updated_at = NOW()
+		RETURNING id, min_connection_minutes, created_at, updated_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		a.IATACode, a.ICAOCode, a.Name, a.City, a.Country, a.Timezone, a.Latitude, a.Longitude,
+		minConnectionMinutes, int(DefaultMinConnection/time.Minute),
+	).Scan(&a.ID, &a.MinConnectionMinutes, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrICAOExists
+		}
+		return fmt.Errorf("failed to save airport: %w", err)
+	}
+	return nil
+}
+
+// GetByIATA retrieves an airport by IATA code.
+func (r *Repository) GetByIATA(ctx context.Context, code string) (*Airport, error) {
+	query := `SELECT ` + airportColumns + ` FROM airports WHERE iata_code = $1`
+	var a Airport
+	if err := scanAirport(r.executor(ctx).QueryRowContext(ctx, query, code), &a); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get airport: %w", err)
+	}
+	return &a, nil
+}
+
+// ListByIATA returns the airports with the given IATA codes; unknown codes are left out.
+func (r *Repository) ListByIATA(ctx context.Context, codes []string) ([]Airport, error) {
+	query := `SELECT ` + airportColumns + ` FROM airports WHERE iata_code = ANY($1)`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, pq.Array(codes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list airports: %w", err)
+	}
+	defer rows.Close()
+
+	airports := []Airport{}
+	for rows.Next() {
+		var a Airport
+		if err := scanAirport(rows, &a); err != nil {
+			return nil, fmt.Errorf("failed to scan airport: %w", err)
+		}
+		airports = append(airports, a)
+	}
+	return airports, rows.Err()
+}
+
+// List returns airports matching the filters, ordered by IATA code.
+func (r *Repository) List(ctx context.Context, params ListParams) ([]Airport, error) {
+	query := `SELECT ` + airportColumns + ` FROM airports WHERE 1=1`
+	args := []interface{}{}
+	argID := 1
+
+	if q := strings.TrimSpace(params.Query); q != "" {
+		query += fmt.Sprintf(" AND (iata_code = UPPER($%d) OR icao_code = UPPER($%d) OR LOWER(name) LIKE $%d OR LOWER(city) LIKE $%d)",
+			argID, argID, argID+1, argID+1)
+		args = append(args, q, "%"+strings.ToLower(q)+"%")
+		argID += 2
+	}
+	if params.Country != "" {
+		query += fmt.Sprintf(" AND country = $%d", argID)
+		args = append(args, params.Country)
+		argID++
+	}
+	query += " ORDER BY iata_code LIMIT 100"
+
+	rows, err := r.executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list airports: %w", err)
+	}
+	defer rows.Close()
+
+	airports := []Airport{}
+	for rows.Next() {
+		var a Airport
+		if err := scanAirport(rows, &a); err != nil {
+			return nil, fmt.Errorf("failed to scan airport: %w", err)
+		}
+		airports = append(airports, a)
+	}
+	return airports, nil
+}
@@ -0,0 +1,18 @@
+package airport
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes sets up the airport routes.
+func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	airportGroup := r.Group("/airports")
+	{
+		airportGroup.GET("", h.List)
+		airportGroup.GET("/:code", h.Get)
+
+		// Protected routes
+		airportGroup.POST("", authMiddleware, h.Save)
+		airportGroup.POST("/import", authMiddleware, h.Import)
+	}
+}
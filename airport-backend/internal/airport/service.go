@@ -0,0 +1,308 @@
+package airport
+
+import (
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAirportNotFound is returned when no airport has the given IATA code.
+	ErrAirportNotFound = apperror.NotFound("airport_not_found", "airport not found")
+	// ErrInvalidAirport is returned when an airport fails validation.
+	ErrInvalidAirport = apperror.Validation("invalid_airport", "invalid airport")
+	// ErrInvalidImport is returned when an import file cannot be read at all.
+	ErrInvalidImport = apperror.Validation("invalid_airport_import", "invalid airport import")
+)
+
+// Service handles business logic for airport reference data.
+type Service struct {
+	repo      *Repository
+	txManager database.TxManager
+	log       *slog.Logger
+}
+
+// NewService creates a new airport service.
+func NewService(repo *Repository, txManager database.TxManager, log *slog.Logger) *Service {
+	return &Service{
+		repo:      repo,
+		txManager: txManager,
+		log:       log,
+	}
+}
+
+// SaveAirport adds an airport or replaces the reference data of the airport with its IATA code.
+func (s *Service) SaveAirport(ctx context.Context, req CreateAirportRequest) (*Airport, error) {
+	a := &Airport{
+		IATACode:  req.IATACode,
+		Name:      req.Name,
+		City:      req.City,
+		Country:   req.Country,
+		Timezone:  req.Timezone,
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+	}
+	if req.ICAOCode != "" {
+		a.ICAOCode = &req.ICAOCode
+	}
+	if err := normalize(a); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAirport, err)
+	}
+	if err := s.repo.Upsert(ctx, a, req.MinConnectionMinutes); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Airport saved", "airport_id", a.ID, "iata_code", a.IATACode)
+	return a, nil
+}
+
+// GetAirport retrieves an airport by IATA code.
+func (s *Service) GetAirport(ctx context.Context, code string) (*Airport, error) {
+	a, err := s.repo.GetByIATA(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAirportNotFound, code)
+	}
+	return a, nil
+}
+
+// ListAirports returns airports matching the filters.
+func (s *Service) ListAirports(ctx context.Context, params ListParams) ([]Airport, error) {
+	return s.repo.List(ctx, params)
+}
+
+// Location returns the time zone of the airport with the given IATA code.
+func (s *Service) Location(ctx context.Context, code string) (*time.Location, error) {
+	a, err := s.GetAirport(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return a.Location()
+}
+
+// MinConnections returns the minimum connection time at each of the given airports. Airports
+// missing from the reference data get DefaultMinConnection.
+func (s *Service) MinConnections(ctx context.Context, codes []string) (map[string]time.Duration, error) {
+	airports, err := s.repo.ListByIATA(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	mct := make(map[string]time.Duration, len(codes))
+	for _, code := range codes {
+		mct[code] = DefaultMinConnection
+	}
+	for i := range airports {
+		mct[airports[i].IATACode] = airports[i].MinConnection()
+	}
+	return mct, nil
+}
+
+// Import reads airports from a CSV dataset and saves them in one transaction. The headerless
+// OpenFlights airports.dat layout is assumed unless the first row names an IATA column, in
+// which case columns are matched by name. Rows without an IATA code are skipped; invalid rows
+// are reported and left out, while the rest are added or updated. Minimum connection times are
+// not part of the data sets, so imports leave them as they are.
+func (s *Service) Import(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	result := &ImportResult{Format: FormatOpenFlights, Errors: []ImportError{}}
+	cols := openFlightsColumns
+	var airports []Airport
+	byIATA := map[string]int{}
+	byICAO := map[string]string{}
+
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		line, _ := reader.FieldPos(0)
+		if first {
+			record[0] = strings.TrimPrefix(record[0], "\uFEFF")
+			if header, ok := headerColumns(record); ok {
+				result.Format = FormatHeader
+				cols = header
+				continue
+			}
+		}
+
+		a, err := cols.airport(record)
+		if err != nil {
+			result.Errors = append(result.Errors, ImportError{Line: line, Message: err.Error()})
+			continue
+		}
+		if a == nil {
+			result.Skipped++
+			continue
+		}
+		if a.ICAOCode != nil {
+			if other, ok := byICAO[*a.ICAOCode]; ok && other != a.IATACode {
+				result.Errors = append(result.Errors, ImportError{
+					Line:    line,
+					Message: fmt.Sprintf("icao_code %s is already used by %s", *a.ICAOCode, other),
+				})
+				continue
+			}
+			byICAO[*a.ICAOCode] = a.IATACode
+		}
+		// A later row for the same airport replaces the earlier one.
+		if i, ok := byIATA[a.IATACode]; ok {
+			airports[i] = *a
+			continue
+		}
+		byIATA[a.IATACode] = len(airports)
+		airports = append(airports, *a)
+	}
+
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		for i := range airports {
+			if err := s.repo.Upsert(ctx, &airports[i], nil); err != nil {
+				return fmt.Errorf("%w: %s", err, airports[i].IATACode)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(airports)
+
+	s.log.Info("Airports imported", "format", result.Format, "imported", result.Imported,
+		"skipped", result.Skipped, "rejected", len(result.Errors))
+	return result, nil
+}
+
+// columns holds the position of each airport field in an import row, or -1 when absent.
+type columns struct {
+	iata, icao, name, city, country, timezone, latitude, longitude int
+}
+
+// openFlightsColumns is the OpenFlights airports.dat layout: ID, name, city, country, IATA,
+// ICAO, latitude, longitude, altitude, UTC offset, DST, time zone, type, source.
+var openFlightsColumns = columns{name: 1, city: 2, country: 3, iata: 4, icao: 5, latitude: 6, longitude: 7, timezone: 11}
+
+// headerColumns matches a header row's column names, accepting the names used by common
+// airport datasets. It reports false when the row has no IATA column and so is not a header.
+func headerColumns(record []string) (columns, bool) {
+	c := columns{-1, -1, -1, -1, -1, -1, -1, -1}
+	for i, name := range record {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "iata", "iata_code":
+			c.iata = i
+		case "icao", "icao_code", "gps_code":
+			c.icao = i
+		case "name", "airport", "airport_name":
+			c.name = i
+		case "city", "municipality":
+			c.city = i
+		case "country", "iso_country":
+			c.country = i
+		case "timezone", "time_zone", "tz", "tz_database_time_zone":
+			c.timezone = i
+		case "latitude", "latitude_deg", "lat":
+			c.latitude = i
+		case "longitude", "longitude_deg", "lon", "lng":
+			c.longitude = i
+		}
+	}
+	return c, c.iata >= 0
+}
+
+// airport reads one row. It returns nil without an error for rows that have no IATA code.
+func (c columns) airport(record []string) (*Airport, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		v := strings.TrimSpace(record[i])
+		if v == `\N` { // OpenFlights' null
+			return ""
+		}
+		return v
+	}
+
+	a := &Airport{
+		IATACode: field(c.iata),
+		Name:     field(c.name),
+		City:     field(c.city),
+		Country:  field(c.country),
+		Timezone: field(c.timezone),
+	}
+	if a.IATACode == "" {
+		return nil, nil
+	}
+	if icao := field(c.icao); icao != "" {
+		a.ICAOCode = &icao
+	}
+	var err error
+	if a.Latitude, err = strconv.ParseFloat(field(c.latitude), 64); err != nil {
+		return nil, fmt.Errorf("%s: invalid latitude %q", a.IATACode, field(c.latitude))
+	}
+	if a.Longitude, err = strconv.ParseFloat(field(c.longitude), 64); err != nil {
+		return nil, fmt.Errorf("%s: invalid longitude %q", a.IATACode, field(c.longitude))
+	}
+	if err := normalize(a); err != nil {
+		return nil, fmt.Errorf("%s: %v", a.IATACode, err)
+	}
+	return a, nil
+}
+
+// normalize trims and upper-cases an airport's codes and validates its fields.
+func normalize(a *Airport) error {
+	a.IATACode = strings.ToUpper(strings.TrimSpace(a.IATACode))
+	a.Name = strings.TrimSpace(a.Name)
+	a.City = strings.TrimSpace(a.City)
+	a.Country = strings.TrimSpace(a.Country)
+	a.Timezone = strings.TrimSpace(a.Timezone)
+
+	if len(a.IATACode) != 3 || !isAlnum(a.IATACode, false) {
+		return errors.New("iata_code must be three letters")
+	}
+	if a.ICAOCode != nil {
+		icao := strings.ToUpper(strings.TrimSpace(*a.ICAOCode))
+		if len(icao) != 4 || !isAlnum(icao, true) {
+			return errors.New("icao_code must be four letters or digits")
+		}
+		a.ICAOCode = &icao
+	}
+	if a.Name == "" {
+		return errors.New("name is required")
+	}
+	if a.Timezone == "" || a.Timezone == "Local" {
+		return errors.New("timezone is required")
+	}
+	if _, err := time.LoadLocation(a.Timezone); err != nil {
+		return fmt.Errorf("unknown time zone %q", a.Timezone)
+	}
+	if a.Latitude < -90 || a.Latitude > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if a.Longitude < -180 || a.Longitude > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+func isAlnum(s string, digits bool) bool {
+	for _, ch := range s {
+		if (ch < 'A' || ch > 'Z') && (!digits || ch < '0' || ch > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package airportops
 
 import (
+	"airport-system/platform/apperror"
 	"net/http"
 	"strconv"
 
@@ -25,7 +26,7 @@ func (h *Handler) requireRole(c *gin.Context, allowedRoles ...string) bool {
 			return true
 		}
 	}
-	c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	c.Error(apperror.ErrForbidden)
 	c.Abort()
 	return false
 }
@@ -38,13 +39,13 @@ func (h *Handler) CreateGate(c *gin.Context) {
 
 	var req CreateGateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest(err))
 		return
 	}
 
 	gate, err := h.Service.CreateGate(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -59,13 +60,87 @@ func (h *Handler) ListGates(c *gin.Context) {
 
 	gates, err := h.Service.ListGates(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gates)
 }
 
+// AssignGate handles assigning or reassigning a flight's gate (STAFF, ADMIN).
+func (h *Handler) AssignGate(c *gin.Context) {
+	if !h.requireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req AssignGateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	assignment, err := h.Service.AssignGate(c.Request.Context(), flightID, c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// UnassignGate handles removing a flight's gate assignment (STAFF, ADMIN).
+func (h *Handler) UnassignGate(c *gin.Context) {
+	if !h.requireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	if err := h.Service.UnassignGate(c.Request.Context(), flightID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Gate unassigned"})
+}
+
+// GateTimeline handles listing upcoming flights at a gate (STAFF, ADMIN).
+func (h *Handler) GateTimeline(c *gin.Context) {
+	if !h.requireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	gateID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var params GateTimelineParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	timeline, err := h.Service.GetGateTimeline(c.Request.Context(), gateID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
 // CheckInBaggage handles baggage check-in (STAFF, ADMIN).
 func (h *Handler) CheckInBaggage(c *gin.Context) {
 	if !h.requireRole(c, "STAFF", "ADMIN") {
@@ -74,13 +149,13 @@ func (h *Handler) CheckInBaggage(c *gin.Context) {
 
 	var req CreateBaggageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest(err))
 		return
 	}
 
-	bag, err := h.Service.CheckInBaggage(c.Request.Context(), req.TicketID)
+	bag, err := h.Service.CheckInBaggage(c.Request.Context(), c.GetInt64("userID"), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -96,19 +171,19 @@ func (h *Handler) UpdateBaggage(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		c.Error(apperror.ErrInvalidID)
 		return
 	}
 
 	var req UpdateBaggageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest(err))
 		return
 	}
 
-	bag, err := h.Service.UpdateBaggage(c.Request.Context(), id, req.Status)
+	bag, err := h.Service.UpdateBaggage(c.Request.Context(), id, c.GetInt64("userID"), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -123,9 +198,30 @@ func (h *Handler) ListBaggage(c *gin.Context) {
 
 	baggageList, err := h.Service.ListAllBaggage(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, baggageList)
 }
+
+// TrackBaggage handles retrieving a bag's full status history (STAFF, ADMIN).
+func (h *Handler) TrackBaggage(c *gin.Context) {
+	if !h.requireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	track, err := h.Service.TrackBaggage(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, track)
+}
@@ -10,20 +10,189 @@ type Gate struct {
 	Status     string `json:"status"`
 }
 
+// Gate statuses. Only OPEN gates accept flight assignments.
+const (
+	GateOpen        = "OPEN"
+	GateClosed      = "CLOSED"
+	GateMaintenance = "MAINTENANCE"
+)
+
+const (
+	// DefaultGateLeadTime is how long before departure a flight starts occupying its gate.
+	DefaultGateLeadTime = 60 * time.Minute
+	// DefaultGateTrailTime is how long after departure the gate stays occupied.
+	DefaultGateTrailTime = 15 * time.Minute
+)
+
+// GateAssignment is a flight's occupancy of a gate over a time window.
+type GateAssignment struct {
+	ID            int64     `json:"id"`
+	GateID        int64     `json:"gate_id"`
+	GateCode      string    `json:"gate_code"`
+	FlightID      int64     `json:"flight_id"`
+	OccupiedFrom  time.Time `json:"occupied_from"`
+	OccupiedUntil time.Time `json:"occupied_until"`
+	AssignedBy    *int64    `json:"assigned_by"`
+	AssignedAt    time.Time `json:"assigned_at"`
+}
+
+// GateTimelineEntry is a gate assignment joined with its flight details.
+type GateTimelineEntry struct {
+	GateAssignment
+	FlightNo      string    `json:"flight_no"`
+	Origin        string    `json:"origin"`
+	Destination   string    `json:"destination"`
+	DepartureTime time.Time `json:"departure_time"`
+	FlightStatus  string    `json:"flight_status"`
+}
+
+// GateTimeline lists the flights occupying a gate within a time range.
+type GateTimeline struct {
+	Gate    Gate                `json:"gate"`
+	From    time.Time           `json:"from"`
+	To      time.Time           `json:"to"`
+	Entries []GateTimelineEntry `json:"entries"`
+}
+
+// flightSlot holds the flight attributes needed to place it on a gate.
+type flightSlot struct {
+	ID                     int64
+	FlightNo               string
+	Status                 string
+	DepartureTime          time.Time
+	EstimatedDepartureTime *time.Time
+}
+
 // Baggage represents a baggage item.
 type Baggage struct {
 	ID        int64     `json:"id"`
 	TicketID  int64     `json:"ticket_id"`
 	TagCode   string    `json:"tag_code"`
 	Status    string    `json:"status"`
+	WeightKg  float64   `json:"weight_kg"`
+	LengthCm  int       `json:"length_cm"`
+	WidthCm   int       `json:"width_cm"`
+	HeightCm  int       `json:"height_cm"`
+	ExcessFee float64   `json:"excess_fee"`
+	FeeReason string    `json:"fee_reason,omitempty"` // Why an excess fee was charged
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// LinearCm returns the bag's length + width + height.
+func (b *Baggage) LinearCm() int {
+	return b.LengthCm + b.WidthCm + b.HeightCm
+}
+
+// Hard baggage limits that apply regardless of fare. Heavier or larger items go as cargo.
+const (
+	MaxBagsPerTicket = 5
+	MaxBagWeightKg   = 32
+	MaxBagLinearCm   = 203
+)
+
+// BaggageAllowance is the free baggage included in a fare class and the fees charged beyond it.
+type BaggageAllowance struct {
+	FareClass     string  `json:"fare_class"`
+	FreeBags      int     `json:"free_bags"`
+	MaxWeightKg   float64 `json:"max_weight_kg"`
+	MaxLinearCm   int     `json:"max_linear_cm"`
+	ExcessBagFee  float64 `json:"excess_bag_fee"` // Per bag beyond FreeBags
+	OverweightFee float64 `json:"overweight_fee"` // Per bag heavier than MaxWeightKg
+	OversizeFee   float64 `json:"oversize_fee"`   // Per bag larger than MaxLinearCm
+}
+
+// ticketCheckIn holds the ticket and flight data baggage check-in validates against.
+type ticketCheckIn struct {
+	TicketID               int64
+	Status                 string
+	FareClass              string
+	FlightID               int64
+	FlightStatus           string
+	DepartureTime          time.Time
+	EstimatedDepartureTime *time.Time
+	BagCount               int
+	BagWeightKg            float64
+}
+
+// baggageHold is the checked baggage load of a flight against its aircraft's hold limit.
+type baggageHold struct {
+	MaxKg    *float64 // Nil when the flight has no aircraft assigned
+	LoadedKg float64
+}
+
+// Baggage statuses, in the order a bag normally moves through them.
+const (
+	BaggageReceived   = "RECEIVED"
+	BaggageScreened   = "SCREENED"
+	BaggageSorted     = "SORTED"
+	BaggageLoaded     = "LOADED"
+	BaggageInTransit  = "IN_TRANSIT"
+	BaggageArrived    = "ARRIVED"
+	BaggageOnCarousel = "ON_CAROUSEL"
+	BaggageClaimed    = "CLAIMED"
+	BaggageMishandled = "MISHANDLED"
+)
+
+// baggageTransitions lists the statuses a bag may move to from each status.
+// Any bag in the system can be declared MISHANDLED; a recovered bag re-enters the flow
+// at sorting or arrival. CLAIMED is terminal.
+var baggageTransitions = map[string][]string{
+	BaggageReceived:   {BaggageScreened, BaggageMishandled},
+	BaggageScreened:   {BaggageSorted, BaggageMishandled},
+	BaggageSorted:     {BaggageLoaded, BaggageMishandled},
+	BaggageLoaded:     {BaggageInTransit, BaggageSorted, BaggageMishandled}, // SORTED: offloaded
+	BaggageInTransit:  {BaggageArrived, BaggageMishandled},
+	BaggageArrived:    {BaggageOnCarousel, BaggageClaimed, BaggageMishandled},
+	BaggageOnCarousel: {BaggageClaimed, BaggageMishandled},
+	BaggageMishandled: {BaggageSorted, BaggageArrived, BaggageClaimed},
+}
+
+// CanTransitionBaggage reports whether a bag may move from one status to another.
+func CanTransitionBaggage(from, to string) bool {
+	for _, next := range baggageTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidBaggageStatus reports whether status is a known baggage status.
+func IsValidBaggageStatus(status string) bool {
+	switch status {
+	case BaggageReceived, BaggageScreened, BaggageSorted, BaggageLoaded, BaggageInTransit,
+		BaggageArrived, BaggageOnCarousel, BaggageClaimed, BaggageMishandled:
+		return true
+	}
+	return false
+}
+
+// BaggageEvent is a recorded baggage status change.
+type BaggageEvent struct {
+	ID         int64     `json:"id"`
+	BaggageID  int64     `json:"baggage_id"`
+	FromStatus *string   `json:"from_status"` // Nil for check-in
+	ToStatus   string    `json:"to_status"`
+	Location   string    `json:"location"`
+	ActorID    *int64    `json:"actor_id"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BaggageTrack is a bag with its full status history, oldest event first.
+type BaggageTrack struct {
+	Baggage Baggage        `json:"baggage"`
+	Events  []BaggageEvent `json:"events"`
+}
+
 // BaggageDetail contains baggage info joined with passenger details.
 type BaggageDetail struct {
 	ID            int64     `json:"id"`
+	TicketID      int64     `json:"ticket_id"`
 	TagCode       string    `json:"tag_code"`
 	Status        string    `json:"status"`
+	WeightKg      float64   `json:"weight_kg"`
+	ExcessFee     float64   `json:"excess_fee"`
 	UpdatedAt     time.Time `json:"updated_at"`
 	PassengerName string    `json:"passenger_name"` // From users table
 	UserID        int64     `json:"user_id"`        // From users table
@@ -36,12 +205,33 @@ type CreateGateRequest struct {
 	Status     string `json:"status" binding:"required"` // OPEN, CLOSED, MAINTENANCE
 }
 
+// AssignGateRequest defines the body for assigning a gate to a flight.
+// The occupancy window defaults to DefaultGateLeadTime before and DefaultGateTrailTime after departure.
+type AssignGateRequest struct {
+	GateID        int64  `json:"gate_id" binding:"required"`
+	OccupiedFrom  string `json:"occupied_from"`  // Optional, RFC3339
+	OccupiedUntil string `json:"occupied_until"` // Optional, RFC3339
+}
+
+// GateTimelineParams defines the time range of a gate timeline query.
+type GateTimelineParams struct {
+	From string `form:"from"` // Optional, RFC3339 (default: now)
+	To   string `form:"to"`   // Optional, RFC3339 (default: from + 24h)
+}
+
 // CreateBaggageRequest defines the body for checking in baggage.
 type CreateBaggageRequest struct {
-	TicketID int64 `json:"ticket_id" binding:"required"`
+	TicketID int64   `json:"ticket_id" binding:"required"`
+	WeightKg float64 `json:"weight_kg" binding:"required,gt=0"`
+	LengthCm int     `json:"length_cm" binding:"required,gt=0"`
+	WidthCm  int     `json:"width_cm" binding:"required,gt=0"`
+	HeightCm int     `json:"height_cm" binding:"required,gt=0"`
+	Location string  `json:"location" binding:"max=64"` // Optional, e.g. check-in desk
 }
 
-// UpdateBaggageRequest defines the body for updating baggage status.
+// UpdateBaggageRequest defines the body for moving a bag to its next status.
 type UpdateBaggageRequest struct {
-	Status string `json:"status" binding:"required"`
+	Status   string `json:"status" binding:"required"`
+	Location string `json:"location" binding:"max=64"` // Optional, e.g. "T1 sorter 3"
+	Note     string `json:"note"`                      // Optional, recommended for MISHANDLED
 }
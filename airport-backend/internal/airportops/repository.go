@@ -1,9 +1,14 @@
 package airportops
 
 import (
+	"airport-system/platform/database"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // Repository handles database interactions for airport operations.
@@ -16,6 +21,13 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{DB: db}
 }
 
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
 // CreateGate inserts a new gate.
 func (r *Repository) CreateGate(ctx context.Context, gate *Gate) (int64, error) {
 	query := `
@@ -26,6 +38,9 @@ func (r *Repository) CreateGate(ctx context.Context, gate *Gate) (int64, error)
 	var id int64
 	err := r.DB.QueryRowContext(ctx, query, gate.TerminalID, gate.Code, gate.Status).Scan(&id)
 	if err != nil {
+		if isPQError(err, "23505") {
+			return 0, ErrGateExists
+		}
 		return 0, fmt.Errorf("failed to create gate: %w", err)
 	}
 	return id, nil
@@ -51,49 +66,360 @@ func (r *Repository) ListGates(ctx context.Context) ([]Gate, error) {
 	return gates, nil
 }
 
+// GetGateByID retrieves a gate by ID.
+func (r *Repository) GetGateByID(ctx context.Context, id int64) (*Gate, error) {
+	return r.getGate(ctx, `SELECT id, terminal_id, code, status FROM gates WHERE id = $1`, id)
+}
+
+// GetGateForUpdate retrieves a gate and locks it, serializing assignments to the same gate.
+func (r *Repository) GetGateForUpdate(ctx context.Context, id int64) (*Gate, error) {
+	return r.getGate(ctx, `SELECT id, terminal_id, code, status FROM gates WHERE id = $1 FOR UPDATE`, id)
+}
+
+func (r *Repository) getGate(ctx context.Context, query string, id int64) (*Gate, error) {
+	var g Gate
+	err := r.executor(ctx).QueryRowContext(ctx, query, id).Scan(&g.ID, &g.TerminalID, &g.Code, &g.Status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get gate: %w", err)
+	}
+	return &g, nil
+}
+
+// getFlightSlot retrieves the scheduling details of a flight.
+func (r *Repository) getFlightSlot(ctx context.Context, flightID int64) (*flightSlot, error) {
+	query := `SELECT id, flight_no, status, departure_time, estimated_departure_time FROM flights WHERE id = $1`
+	var f flightSlot
+	err := r.executor(ctx).QueryRowContext(ctx, query, flightID).Scan(&f.ID, &f.FlightNo, &f.Status, &f.DepartureTime, &f.EstimatedDepartureTime)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	return &f, nil
+}
+
+// FindOverlappingAssignment returns another flight's assignment on the gate whose window
+// overlaps [from, until), or nil if the gate is free.
+func (r *Repository) FindOverlappingAssignment(ctx context.Context, gateID int64, from, until time.Time, excludeFlightID int64) (*GateTimelineEntry, error) {
+	query := `
+		SELECT ga.id, ga.gate_id, g.code, ga.flight_id, ga.occupied_from, ga.occupied_until, ga.assigned_by, ga.assigned_at,
+		       f.flight_no, f.origin, f.destination, f.departure_time, f.status
+		FROM gate_assignments ga
+		JOIN gates g ON g.id = ga.gate_id
+		JOIN flights f ON f.id = ga.flight_id
+		WHERE ga.gate_id = $1
+		  AND ga.flight_id <> $2
+		  AND ga.occupied_from < $4
+		  AND ga.occupied_until > $3
+		  AND f.status NOT IN ('CANCELLED', 'ARRIVED')
+		ORDER BY ga.occupied_from
+		LIMIT 1
+	`
+	var e GateTimelineEntry
+	err := r.executor(ctx).QueryRowContext(ctx, query, gateID, excludeFlightID, from, until).Scan(
+		&e.ID, &e.GateID, &e.GateCode, &e.FlightID, &e.OccupiedFrom, &e.OccupiedUntil, &e.AssignedBy, &e.AssignedAt,
+		&e.FlightNo, &e.Origin, &e.Destination, &e.DepartureTime, &e.FlightStatus,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check gate occupancy: %w", err)
+	}
+	return &e, nil
+}
+
+// UpsertAssignment assigns a flight to a gate, replacing its previous assignment,
+// and mirrors the gate onto flights.gate_id.
+func (r *Repository) UpsertAssignment(ctx context.Context, a *GateAssignment) (int64, error) {
+	exec := r.executor(ctx)
+
+	query := `
+		INSERT INTO gate_assignments (gate_id, flight_id, occupied_from, occupied_until, assigned_by, assigned_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (flight_id) DO UPDATE
+		SET gate_id = EXCLUDED.gate_id,
+		    occupied_from = EXCLUDED.occupied_from,
+		    occupied_until = EXCLUDED.occupied_until,
+		    assigned_by = EXCLUDED.assigned_by,
+		    assigned_at = EXCLUDED.assigned_at
+		RETURNING id, assigned_at
+	`
+	var id int64
+	err := exec.QueryRowContext(ctx, query, a.GateID, a.FlightID, a.OccupiedFrom, a.OccupiedUntil, a.AssignedBy).Scan(&id, &a.AssignedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign gate: %w", err)
+	}
+
+	if _, err := exec.ExecContext(ctx, `UPDATE flights SET gate_id = $1, updated_at = NOW() WHERE id = $2`, a.GateID, a.FlightID); err != nil {
+		return 0, fmt.Errorf("failed to update flight gate: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteAssignment removes a flight's gate assignment and clears flights.gate_id.
+// Returns false if the flight had no assignment.
+func (r *Repository) DeleteAssignment(ctx context.Context, flightID int64) (bool, error) {
+	exec := r.executor(ctx)
+
+	res, err := exec.ExecContext(ctx, `DELETE FROM gate_assignments WHERE flight_id = $1`, flightID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove gate assignment: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove gate assignment: %w", err)
+	}
+
+	if _, err := exec.ExecContext(ctx, `UPDATE flights SET gate_id = NULL, updated_at = NOW() WHERE id = $1`, flightID); err != nil {
+		return false, fmt.Errorf("failed to update flight gate: %w", err)
+	}
+	return n > 0, nil
+}
+
+// ListGateTimeline returns the assignments on a gate overlapping [from, to), in time order.
+func (r *Repository) ListGateTimeline(ctx context.Context, gateID int64, from, to time.Time) ([]GateTimelineEntry, error) {
+	query := `
+		SELECT ga.id, ga.gate_id, g.code, ga.flight_id, ga.occupied_from, ga.occupied_until, ga.assigned_by, ga.assigned_at,
+		       f.flight_no, f.origin, f.destination, f.departure_time, f.status
+		FROM gate_assignments ga
+		JOIN gates g ON g.id = ga.gate_id
+		JOIN flights f ON f.id = ga.flight_id
+		WHERE ga.gate_id = $1
+		  AND ga.occupied_from < $3
+		  AND ga.occupied_until > $2
+		ORDER BY ga.occupied_from ASC
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, gateID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gate timeline: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []GateTimelineEntry{}
+	for rows.Next() {
+		var e GateTimelineEntry
+		if err := rows.Scan(
+			&e.ID, &e.GateID, &e.GateCode, &e.FlightID, &e.OccupiedFrom, &e.OccupiedUntil, &e.AssignedBy, &e.AssignedAt,
+			&e.FlightNo, &e.Origin, &e.Destination, &e.DepartureTime, &e.FlightStatus,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan gate timeline entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// baggageColumns is the column list scanned by scanBaggage.
+const baggageColumns = `id, ticket_id, tag_code, status, weight_kg, length_cm, width_cm, height_cm, excess_fee, fee_reason, updated_at`
+
+func scanBaggage(row interface{ Scan(dest ...any) error }) (*Baggage, error) {
+	var b Baggage
+	err := row.Scan(&b.ID, &b.TicketID, &b.TagCode, &b.Status, &b.WeightKg, &b.LengthCm, &b.WidthCm, &b.HeightCm, &b.ExcessFee, &b.FeeReason, &b.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
 // CreateBaggage inserts a new baggage item.
 func (r *Repository) CreateBaggage(ctx context.Context, bag *Baggage) (int64, error) {
 	query := `
-		INSERT INTO baggage (ticket_id, tag_code, status, updated_at)
-		VALUES ($1, $2, $3, NOW())
-		RETURNING id
+		INSERT INTO baggage (ticket_id, tag_code, status, weight_kg, length_cm, width_cm, height_cm, excess_fee, fee_reason, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING id, updated_at
 	`
 	var id int64
-	err := r.DB.QueryRowContext(ctx, query, bag.TicketID, bag.TagCode, bag.Status).Scan(&id)
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		bag.TicketID, bag.TagCode, bag.Status, bag.WeightKg, bag.LengthCm, bag.WidthCm, bag.HeightCm, bag.ExcessFee, bag.FeeReason,
+	).Scan(&id, &bag.UpdatedAt)
 	if err != nil {
+		if isPQError(err, "23503") {
+			return 0, ErrTicketNotFound
+		}
 		return 0, fmt.Errorf("failed to create baggage: %w", err)
 	}
 	return id, nil
 }
 
+// GetTicketForCheckIn retrieves the ticket, its flight and its current bag count, and locks
+// the ticket row so concurrent check-ins for the same ticket are serialized. The bags are
+// counted once the lock is held, so the count includes those of the check-in waited for.
+func (r *Repository) GetTicketForCheckIn(ctx context.Context, ticketID int64) (*ticketCheckIn, error) {
+	query := `
+		SELECT t.id, t.status, t.fare_class, f.id, f.status, f.departure_time, f.estimated_departure_time
+		FROM tickets t
+		JOIN flights f ON f.id = t.flight_id
+		WHERE t.id = $1
+		FOR UPDATE OF t
+	`
+	var tc ticketCheckIn
+	err := r.executor(ctx).QueryRowContext(ctx, query, ticketID).Scan(
+		&tc.TicketID, &tc.Status, &tc.FareClass, &tc.FlightID, &tc.FlightStatus, &tc.DepartureTime, &tc.EstimatedDepartureTime,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ticket for check-in: %w", err)
+	}
+
+	// A separate statement, as a subquery of the locking one would read the rows as they were
+	// before the lock wait
+	query = `SELECT COUNT(*), COALESCE(SUM(weight_kg), 0) FROM baggage WHERE ticket_id = $1`
+	if err := r.executor(ctx).QueryRowContext(ctx, query, ticketID).Scan(&tc.BagCount, &tc.BagWeightKg); err != nil {
+		return nil, fmt.Errorf("failed to count ticket baggage: %w", err)
+	}
+	return &tc, nil
+}
+
+// LockBaggageHold returns the checked baggage weight of a flight and its aircraft's limit, and
+// locks the flight row so concurrent check-ins cannot overfill the hold together. The weight
+// is summed once the lock is held, so it includes the bags of the check-in waited for.
+func (r *Repository) LockBaggageHold(ctx context.Context, flightID int64) (*baggageHold, error) {
+	query := `
+		SELECT at.max_baggage_kg
+		FROM flights f
+		LEFT JOIN aircraft a ON a.id = f.aircraft_id
+		LEFT JOIN aircraft_types at ON at.id = a.aircraft_type_id
+		WHERE f.id = $1
+		FOR NO KEY UPDATE OF f
+	`
+	var h baggageHold
+	if err := r.executor(ctx).QueryRowContext(ctx, query, flightID).Scan(&h.MaxKg); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrFlightNotFound
+		}
+		return nil, fmt.Errorf("failed to lock baggage hold: %w", err)
+	}
+
+	query = `
+		SELECT COALESCE(SUM(b.weight_kg), 0)
+		FROM baggage b
+		JOIN tickets t ON t.id = b.ticket_id
+		WHERE t.flight_id = $1 AND t.status = 'ACTIVE'
+	`
+	if err := r.executor(ctx).QueryRowContext(ctx, query, flightID).Scan(&h.LoadedKg); err != nil {
+		return nil, fmt.Errorf("failed to get baggage load: %w", err)
+	}
+	return &h, nil
+}
+
+// GetBaggageAllowance retrieves the baggage allowance of a fare class.
+func (r *Repository) GetBaggageAllowance(ctx context.Context, fareClass string) (*BaggageAllowance, error) {
+	query := `
+		SELECT fare_class, free_bags, max_weight_kg, max_linear_cm, excess_bag_fee, overweight_fee, oversize_fee
+		FROM baggage_allowances
+		WHERE fare_class = $1
+	`
+	var a BaggageAllowance
+	err := r.executor(ctx).QueryRowContext(ctx, query, fareClass).Scan(
+		&a.FareClass, &a.FreeBags, &a.MaxWeightKg, &a.MaxLinearCm, &a.ExcessBagFee, &a.OverweightFee, &a.OversizeFee,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get baggage allowance: %w", err)
+	}
+	return &a, nil
+}
+
+// AddTicketBaggageFee adds an excess baggage fee to the ticket's booking total.
+func (r *Repository) AddTicketBaggageFee(ctx context.Context, ticketID int64, fee float64) error {
+	query := `UPDATE tickets SET baggage_fees = baggage_fees + $1 WHERE id = $2`
+	if _, err := r.executor(ctx).ExecContext(ctx, query, fee, ticketID); err != nil {
+		return fmt.Errorf("failed to add baggage fee: %w", err)
+	}
+	return nil
+}
+
 // UpdateBaggageStatus updates the status of a baggage item.
 func (r *Repository) UpdateBaggageStatus(ctx context.Context, id int64, status string) error {
 	query := `UPDATE baggage SET status = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.DB.ExecContext(ctx, query, status, id)
+	_, err := r.executor(ctx).ExecContext(ctx, query, status, id)
 	if err != nil {
 		return fmt.Errorf("failed to update baggage status: %w", err)
 	}
 	return nil
 }
 
+// GetBaggageForUpdate retrieves baggage by ID and locks it for the current transaction.
+func (r *Repository) GetBaggageForUpdate(ctx context.Context, id int64) (*Baggage, error) {
+	query := `SELECT ` + baggageColumns + ` FROM baggage WHERE id = $1 FOR UPDATE`
+	b, err := scanBaggage(r.executor(ctx).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get baggage: %w", err)
+	}
+	return b, nil
+}
+
+// CreateBaggageEvent appends an entry to a bag's tracking history.
+func (r *Repository) CreateBaggageEvent(ctx context.Context, e *BaggageEvent) (int64, error) {
+	query := `
+		INSERT INTO baggage_events (baggage_id, from_status, to_status, location, actor_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at
+	`
+	var id int64
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		e.BaggageID, e.FromStatus, e.ToStatus, e.Location, e.ActorID, e.Note,
+	).Scan(&id, &e.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record baggage event: %w", err)
+	}
+	return id, nil
+}
+
+// ListBaggageEvents returns a bag's tracking history, oldest first.
+func (r *Repository) ListBaggageEvents(ctx context.Context, baggageID int64) ([]BaggageEvent, error) {
+	query := `
+		SELECT id, baggage_id, from_status, to_status, location, actor_id, note, created_at
+		FROM baggage_events
+		WHERE baggage_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, baggageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list baggage events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []BaggageEvent{}
+	for rows.Next() {
+		var e BaggageEvent
+		if err := rows.Scan(&e.ID, &e.BaggageID, &e.FromStatus, &e.ToStatus, &e.Location, &e.ActorID, &e.Note, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan baggage event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
 // GetBaggageByID retrieves baggage by ID (helper for service).
 func (r *Repository) GetBaggageByID(ctx context.Context, id int64) (*Baggage, error) {
-	query := `SELECT id, ticket_id, tag_code, status, updated_at FROM baggage WHERE id = $1`
-	var b Baggage
-	err := r.DB.QueryRowContext(ctx, query, id).Scan(&b.ID, &b.TicketID, &b.TagCode, &b.Status, &b.UpdatedAt)
+	query := `SELECT ` + baggageColumns + ` FROM baggage WHERE id = $1`
+	b, err := scanBaggage(r.executor(ctx).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get baggage: %w", err)
 	}
-	return &b, nil
+	return b, nil
 }
 
 // ListAllWithPassengerInfo retrieves all baggage with passenger details (For Admin/Staff).
 func (r *Repository) ListAllWithPassengerInfo(ctx context.Context) ([]BaggageDetail, error) {
 	query := `
-		SELECT b.id, b.tag_code, b.status, b.updated_at, u.full_name, u.id
+		SELECT b.id, b.ticket_id, b.tag_code, b.status, b.weight_kg, b.excess_fee, b.updated_at, COALESCE(p.full_name, u.full_name), u.id
 		FROM baggage b
 		JOIN tickets t ON b.ticket_id = t.id
 		JOIN passengers p ON t.passenger_id = p.id
@@ -109,7 +435,7 @@ func (r *Repository) ListAllWithPassengerInfo(ctx context.Context) ([]BaggageDet
 	var details []BaggageDetail
 	for rows.Next() {
 		var b BaggageDetail
-		if err := rows.Scan(&b.ID, &b.TagCode, &b.Status, &b.UpdatedAt, &b.PassengerName, &b.UserID); err != nil {
+		if err := rows.Scan(&b.ID, &b.TicketID, &b.TagCode, &b.Status, &b.WeightKg, &b.ExcessFee, &b.UpdatedAt, &b.PassengerName, &b.UserID); err != nil {
 			return nil, fmt.Errorf("failed to scan baggage detail: %w", err)
 		}
 		details = append(details, b)
@@ -119,7 +445,7 @@ func (r *Repository) ListAllWithPassengerInfo(ctx context.Context) ([]BaggageDet
 
 // GetByTicketID retrieves baggage items by ticket ID (For Booking module).
 func (r *Repository) GetByTicketID(ctx context.Context, ticketID int64) ([]Baggage, error) {
-	query := `SELECT id, ticket_id, tag_code, status, updated_at FROM baggage WHERE ticket_id = $1`
+	query := `SELECT ` + baggageColumns + ` FROM baggage WHERE ticket_id = $1 ORDER BY id`
 	rows, err := r.DB.QueryContext(ctx, query, ticketID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get baggage by ticket: %w", err)
@@ -128,11 +454,42 @@ func (r *Repository) GetByTicketID(ctx context.Context, ticketID int64) ([]Bagga
 
 	var bags []Baggage
 	for rows.Next() {
-		var b Baggage
-		if err := rows.Scan(&b.ID, &b.TicketID, &b.TagCode, &b.Status, &b.UpdatedAt); err != nil {
+		b, err := scanBaggage(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan baggage: %w", err)
 		}
-		bags = append(bags, b)
+		bags = append(bags, *b)
 	}
 	return bags, nil
 }
+
+// ListByFlight retrieves the baggage checked in on a flight's active tickets.
+func (r *Repository) ListByFlight(ctx context.Context, flightID int64) ([]Baggage, error) {
+	query := `
+		SELECT ` + baggageColumns + `
+		FROM baggage
+		WHERE ticket_id IN (SELECT id FROM tickets WHERE flight_id = $1 AND status = 'ACTIVE')
+		ORDER BY ticket_id, id
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get baggage by flight: %w", err)
+	}
+	defer rows.Close()
+
+	bags := []Baggage{}
+	for rows.Next() {
+		b, err := scanBaggage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan baggage: %w", err)
+		}
+		bags = append(bags, *b)
+	}
+	return bags, rows.Err()
+}
+
+// isPQError reports whether err is a PostgreSQL error with the given SQLSTATE code.
+func isPQError(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
@@ -11,8 +11,12 @@ func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFu
 	{
 		opsGroup.POST("/gates", h.CreateGate)
 		opsGroup.GET("/gates", h.ListGates)
+		opsGroup.GET("/gates/:id/timeline", h.GateTimeline)
+		opsGroup.PUT("/flights/:id/gate", h.AssignGate)
+		opsGroup.DELETE("/flights/:id/gate", h.UnassignGate)
 		opsGroup.POST("/baggage", h.CheckInBaggage)
 		opsGroup.GET("/baggage", h.ListBaggage)
 		opsGroup.PATCH("/baggage/:id", h.UpdateBaggage)
+		opsGroup.GET("/baggage/:id/track", h.TrackBaggage)
 	}
 }
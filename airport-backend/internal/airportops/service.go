@@ -1,29 +1,76 @@
 package airportops
 
 import (
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrGateNotFound is returned when the gate does not exist.
+	ErrGateNotFound = apperror.NotFound("gate_not_found", "gate not found")
+	// ErrFlightNotFound is returned when the flight does not exist.
+	ErrFlightNotFound = apperror.NotFound("flight_not_found", "flight not found")
+	// ErrAssignmentNotFound is returned when the flight has no gate assignment.
+	ErrAssignmentNotFound = apperror.NotFound("gate_assignment_not_found", "gate assignment not found")
+	// ErrGateUnavailable is returned when a gate is CLOSED or under MAINTENANCE.
+	ErrGateUnavailable = apperror.Conflict("gate_unavailable", "gate is not available")
+	// ErrGateConflict is returned when the requested window overlaps another flight on the gate.
+	ErrGateConflict = apperror.Conflict("gate_conflict", "gate is already occupied")
+	// ErrInvalidGateRequest is returned when a gate request is malformed.
+	ErrInvalidGateRequest = apperror.Validation("invalid_gate_request", "invalid gate request")
+	// ErrGateExists is returned when the terminal already has a gate with the same code.
+	ErrGateExists = apperror.Conflict("gate_exists", "gate already exists in this terminal")
+	// ErrTicketNotFound is returned when checking in baggage for an unknown ticket.
+	ErrTicketNotFound = apperror.NotFound("ticket_not_found", "ticket not found")
+	// ErrBaggageNotFound is returned when the baggage item does not exist.
+	ErrBaggageNotFound = apperror.NotFound("baggage_not_found", "baggage not found")
+	// ErrInvalidBaggage is returned for bags that exceed the hard weight or size limits.
+	ErrInvalidBaggage = apperror.Validation("invalid_baggage", "invalid baggage")
+	// ErrTicketNotActive is returned when checking in baggage on a ticket that is not ACTIVE.
+	ErrTicketNotActive = apperror.Conflict("ticket_not_active", "ticket is not active")
+	// ErrFlightDeparted is returned when checking in baggage after the flight has left.
+	ErrFlightDeparted = apperror.Conflict("flight_departed", "flight has already departed")
+	// ErrBaggageLimit is returned when a ticket already has the maximum number of bags.
+	ErrBaggageLimit = apperror.Conflict("baggage_limit_reached", "baggage limit reached")
+	// ErrBaggageHoldFull is returned when a bag would take the flight over its aircraft's baggage weight limit.
+	ErrBaggageHoldFull = apperror.Conflict("baggage_hold_full", "aircraft baggage hold is full")
+	// ErrInvalidBaggageStatus is returned for unknown baggage statuses.
+	ErrInvalidBaggageStatus = apperror.Validation("invalid_baggage_status", "invalid baggage status")
+	// ErrInvalidBaggageTransition is returned for status changes the baggage lifecycle does not allow.
+	ErrInvalidBaggageTransition = apperror.Conflict("invalid_baggage_transition", "invalid baggage status transition")
 )
 
 // Service handles business logic for airport operations.
 type Service struct {
-	repo *Repository
-	log  *slog.Logger
+	repo      *Repository
+	txManager database.TxManager
+	log       *slog.Logger
 }
 
 // NewService creates a new airport ops service.
-func NewService(repo *Repository, log *slog.Logger) *Service {
+func NewService(repo *Repository, txManager database.TxManager, log *slog.Logger) *Service {
 	return &Service{
-		repo: repo,
-		log:  log,
+		repo:      repo,
+		txManager: txManager,
+		log:       log,
 	}
 }
 
 // CreateGate creates a new gate.
 func (s *Service) CreateGate(ctx context.Context, req CreateGateRequest) (*Gate, error) {
+	switch req.Status {
+	case GateOpen, GateClosed, GateMaintenance:
+	default:
+		return nil, fmt.Errorf("%w: status must be OPEN, CLOSED or MAINTENANCE", ErrInvalidGateRequest)
+	}
+
 	gate := &Gate{
 		TerminalID: req.TerminalID,
 		Code:       req.Code,
@@ -43,35 +90,369 @@ func (s *Service) ListGates(ctx context.Context) ([]Gate, error) {
 	return s.repo.ListGates(ctx)
 }
 
-// CheckInBaggage generates a tag and checks in baggage.
-func (s *Service) CheckInBaggage(ctx context.Context, ticketID int64) (*Baggage, error) {
+// AssignGate assigns (or reassigns) a flight to a gate. The gate must be OPEN and its
+// occupancy window must not overlap any other active flight on the same gate.
+func (s *Service) AssignGate(ctx context.Context, flightID, userID int64, req AssignGateRequest) (*GateAssignment, error) {
+	var assignment *GateAssignment
+
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		// Locking the gate serializes concurrent assignments to it
+		gate, err := s.repo.GetGateForUpdate(ctx, req.GateID)
+		if err != nil {
+			return err
+		}
+		if gate == nil {
+			return ErrGateNotFound
+		}
+		if gate.Status != GateOpen {
+			return fmt.Errorf("%w: gate %s is %s", ErrGateUnavailable, gate.Code, gate.Status)
+		}
+
+		f, err := s.repo.getFlightSlot(ctx, flightID)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return ErrFlightNotFound
+		}
+		switch f.Status {
+		case "DEPARTED", "ARRIVED", "DIVERTED", "CANCELLED":
+			return fmt.Errorf("%w: cannot assign a gate to a %s flight", ErrInvalidGateRequest, f.Status)
+		}
+
+		from, until, err := occupancyWindow(f, req)
+		if err != nil {
+			return err
+		}
+
+		overlap, err := s.repo.FindOverlappingAssignment(ctx, gate.ID, from, until, flightID)
+		if err != nil {
+			return err
+		}
+		if overlap != nil {
+			return fmt.Errorf("%w: gate %s is assigned to flight %s from %s to %s",
+				ErrGateConflict, gate.Code, overlap.FlightNo,
+				overlap.OccupiedFrom.Format(time.RFC3339), overlap.OccupiedUntil.Format(time.RFC3339))
+		}
+
+		assignment = &GateAssignment{
+			GateID:        gate.ID,
+			GateCode:      gate.Code,
+			FlightID:      flightID,
+			OccupiedFrom:  from,
+			OccupiedUntil: until,
+			AssignedBy:    &userID,
+		}
+		id, err := s.repo.UpsertAssignment(ctx, assignment)
+		if err != nil {
+			return err
+		}
+		assignment.ID = id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Gate assigned", "flight_id", flightID, "gate_id", assignment.GateID, "from", assignment.OccupiedFrom, "until", assignment.OccupiedUntil, "user_id", userID)
+	return assignment, nil
+}
+
+// occupancyWindow returns the requested window, defaulting to the configured lead and trail
+// times around the flight's estimated (or scheduled) departure.
+func occupancyWindow(f *flightSlot, req AssignGateRequest) (time.Time, time.Time, error) {
+	departure := f.DepartureTime
+	if f.EstimatedDepartureTime != nil {
+		departure = *f.EstimatedDepartureTime
+	}
+	from := departure.Add(-DefaultGateLeadTime)
+	until := departure.Add(DefaultGateTrailTime)
+
+	if req.OccupiedFrom != "" {
+		t, err := time.Parse(time.RFC3339, req.OccupiedFrom)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid occupied_from format (expected RFC3339)", ErrInvalidGateRequest)
+		}
+		from = t
+	}
+	if req.OccupiedUntil != "" {
+		t, err := time.Parse(time.RFC3339, req.OccupiedUntil)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid occupied_until format (expected RFC3339)", ErrInvalidGateRequest)
+		}
+		until = t
+	}
+	if !until.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: occupied_until must be after occupied_from", ErrInvalidGateRequest)
+	}
+	return from, until, nil
+}
+
+// UnassignGate removes a flight's gate assignment.
+func (s *Service) UnassignGate(ctx context.Context, flightID int64) error {
+	removed, err := s.repo.DeleteAssignment(ctx, flightID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrAssignmentNotFound
+	}
+	s.log.Info("Gate unassigned", "flight_id", flightID)
+	return nil
+}
+
+// GetGateTimeline lists the flights occupying a gate within a time range.
+func (s *Service) GetGateTimeline(ctx context.Context, gateID int64, params GateTimelineParams) (*GateTimeline, error) {
+	from := time.Now()
+	if params.From != "" {
+		t, err := time.Parse(time.RFC3339, params.From)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid from format (expected RFC3339)", ErrInvalidGateRequest)
+		}
+		from = t
+	}
+	to := from.Add(24 * time.Hour)
+	if params.To != "" {
+		t, err := time.Parse(time.RFC3339, params.To)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid to format (expected RFC3339)", ErrInvalidGateRequest)
+		}
+		to = t
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrInvalidGateRequest)
+	}
+
+	gate, err := s.repo.GetGateByID(ctx, gateID)
+	if err != nil {
+		return nil, err
+	}
+	if gate == nil {
+		return nil, ErrGateNotFound
+	}
+
+	entries, err := s.repo.ListGateTimeline(ctx, gateID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &GateTimeline{Gate: *gate, From: from, To: to, Entries: entries}, nil
+}
+
+// CheckInBaggage validates a bag against its ticket, flight, fare allowance and the aircraft's
+// baggage weight limit, generates a tag and checks it in. Any excess fee is added to the ticket's booking.
+func (s *Service) CheckInBaggage(ctx context.Context, actorID int64, req CreateBaggageRequest) (*Baggage, error) {
+	bag := &Baggage{
+		TicketID: req.TicketID,
+		Status:   BaggageReceived,
+		WeightKg: req.WeightKg,
+		LengthCm: req.LengthCm,
+		WidthCm:  req.WidthCm,
+		HeightCm: req.HeightCm,
+	}
+	if bag.WeightKg > MaxBagWeightKg {
+		return nil, fmt.Errorf("%w: bags over %d kg must be shipped as cargo", ErrInvalidBaggage, MaxBagWeightKg)
+	}
+	if bag.LinearCm() > MaxBagLinearCm {
+		return nil, fmt.Errorf("%w: bags over %d cm (length + width + height) must be shipped as cargo", ErrInvalidBaggage, MaxBagLinearCm)
+	}
+
 	// Generate unique tag
 	bytes := make([]byte, 4)
 	if _, err := rand.Read(bytes); err != nil {
 		return nil, fmt.Errorf("failed to generate tag: %w", err)
 	}
-	tagCode := fmt.Sprintf("BAG-%s", hex.EncodeToString(bytes))
+	bag.TagCode = fmt.Sprintf("BAG-%s", hex.EncodeToString(bytes))
 
-	bag := &Baggage{
-		TicketID: ticketID,
-		TagCode:  tagCode,
-		Status:   "RECEIVED",
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		tc, err := s.repo.GetTicketForCheckIn(ctx, req.TicketID)
+		if err != nil {
+			return err
+		}
+		if tc == nil {
+			return ErrTicketNotFound
+		}
+		if tc.Status != "ACTIVE" {
+			return fmt.Errorf("%w: ticket is %s", ErrTicketNotActive, tc.Status)
+		}
+		if hasDeparted(tc) {
+			return ErrFlightDeparted
+		}
+		if tc.BagCount >= MaxBagsPerTicket {
+			return fmt.Errorf("%w: at most %d bags per ticket", ErrBaggageLimit, MaxBagsPerTicket)
+		}
+
+		if err := s.reserveHold(ctx, tc.FlightID, bag.WeightKg); err != nil {
+			return err
+		}
+
+		allowance, err := s.repo.GetBaggageAllowance(ctx, tc.FareClass)
+		if err != nil {
+			return err
+		}
+		if allowance == nil {
+			return fmt.Errorf("no baggage allowance configured for fare class %s", tc.FareClass)
+		}
+		bag.ExcessFee, bag.FeeReason = excessBaggageFee(allowance, bag, tc.BagCount+1)
+
+		id, err := s.repo.CreateBaggage(ctx, bag)
+		if err != nil {
+			return err
+		}
+		bag.ID = id
+
+		if bag.ExcessFee > 0 {
+			if err := s.repo.AddTicketBaggageFee(ctx, tc.TicketID, bag.ExcessFee); err != nil {
+				return err
+			}
+		}
+
+		_, err = s.repo.CreateBaggageEvent(ctx, &BaggageEvent{
+			BaggageID: id,
+			ToStatus:  BaggageReceived,
+			Location:  req.Location,
+			ActorID:   &actorID,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Baggage checked in", "baggage_id", bag.ID, "ticket_id", bag.TicketID, "weight_kg", bag.WeightKg, "excess_fee", bag.ExcessFee)
+	return bag, nil
+}
+
+// ReserveHoldForTicket checks that the bags checked on a ticket fit in the baggage hold of the
+// flight it is moving to. The ticket and that flight's hold stay locked until the transaction
+// ends, so no check-in adds bags to either meanwhile. Callers run it in a transaction.
+func (s *Service) ReserveHoldForTicket(ctx context.Context, ticketID, flightID int64) error {
+	tc, err := s.repo.GetTicketForCheckIn(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	if tc == nil {
+		return ErrTicketNotFound
+	}
+	if tc.BagCount == 0 {
+		return nil
+	}
+	return s.reserveHold(ctx, flightID, tc.BagWeightKg)
+}
+
+// reserveHold locks a flight's baggage hold and checks that weightKg more fits in it.
+func (s *Service) reserveHold(ctx context.Context, flightID int64, weightKg float64) error {
+	hold, err := s.repo.LockBaggageHold(ctx, flightID)
+	if err != nil {
+		return err
+	}
+	if hold.MaxKg != nil && hold.LoadedKg+weightKg > *hold.MaxKg {
+		return fmt.Errorf("%w: %.1f kg of %.1f kg already loaded", ErrBaggageHoldFull, hold.LoadedKg, *hold.MaxKg)
+	}
+	return nil
+}
+
+// hasDeparted reports whether the ticket's flight has left or will no longer operate.
+func hasDeparted(tc *ticketCheckIn) bool {
+	switch tc.FlightStatus {
+	case "DEPARTED", "ARRIVED", "DIVERTED", "CANCELLED":
+		return true
+	}
+	departure := tc.DepartureTime
+	if tc.EstimatedDepartureTime != nil {
+		departure = *tc.EstimatedDepartureTime
+	}
+	return !departure.After(time.Now())
+}
+
+// excessBaggageFee returns the fee for the bagNo-th bag on a ticket and why it is charged.
+// Extra-bag, overweight and oversize fees add up.
+func excessBaggageFee(a *BaggageAllowance, bag *Baggage, bagNo int) (float64, string) {
+	var fee float64
+	var reasons []string
+	if bagNo > a.FreeBags {
+		fee += a.ExcessBagFee
+		reasons = append(reasons, fmt.Sprintf("bag %d exceeds %d free bag(s)", bagNo, a.FreeBags))
+	}
+	if bag.WeightKg > a.MaxWeightKg {
+		fee += a.OverweightFee
+		reasons = append(reasons, fmt.Sprintf("overweight (%.1f kg > %.1f kg)", bag.WeightKg, a.MaxWeightKg))
+	}
+	if bag.LinearCm() > a.MaxLinearCm {
+		fee += a.OversizeFee
+		reasons = append(reasons, fmt.Sprintf("oversize (%d cm > %d cm)", bag.LinearCm(), a.MaxLinearCm))
+	}
+	return fee, strings.Join(reasons, "; ")
+}
+
+// UpdateBaggage moves a bag to its next status and appends the change to its history.
+func (s *Service) UpdateBaggage(ctx context.Context, id, actorID int64, req UpdateBaggageRequest) (*Baggage, error) {
+	if !IsValidBaggageStatus(req.Status) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidBaggageStatus, req.Status)
+	}
+
+	var bag *Baggage
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetBaggageForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrBaggageNotFound
+		}
+		if !CanTransitionBaggage(current.Status, req.Status) {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidBaggageTransition, current.Status, req.Status)
+		}
+
+		if err := s.repo.UpdateBaggageStatus(ctx, id, req.Status); err != nil {
+			return err
+		}
+		from := current.Status
+		if _, err := s.repo.CreateBaggageEvent(ctx, &BaggageEvent{
+			BaggageID:  id,
+			FromStatus: &from,
+			ToStatus:   req.Status,
+			Location:   req.Location,
+			ActorID:    &actorID,
+			Note:       req.Note,
+		}); err != nil {
+			return err
+		}
+
+		bag, err = s.repo.GetBaggageByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	id, err := s.repo.CreateBaggage(ctx, bag)
+	s.log.Info("Baggage status changed", "baggage_id", id, "tag_code", bag.TagCode, "status", req.Status, "location", req.Location, "actor_id", actorID)
+	return bag, nil
+}
+
+// GetBaggage retrieves a baggage item by ID.
+func (s *Service) GetBaggage(ctx context.Context, id int64) (*Baggage, error) {
+	bag, err := s.repo.GetBaggageByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	bag.ID = id
+	if bag == nil {
+		return nil, ErrBaggageNotFound
+	}
 	return bag, nil
 }
 
-// UpdateBaggage updates the status of a baggage item.
-func (s *Service) UpdateBaggage(ctx context.Context, id int64, status string) (*Baggage, error) {
-	if err := s.repo.UpdateBaggageStatus(ctx, id, status); err != nil {
+// TrackBaggage returns a bag with its full status history.
+func (s *Service) TrackBaggage(ctx context.Context, id int64) (*BaggageTrack, error) {
+	bag, err := s.GetBaggage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.repo.ListBaggageEvents(ctx, id)
+	if err != nil {
 		return nil, err
 	}
-	return s.repo.GetBaggageByID(ctx, id)
+	return &BaggageTrack{Baggage: *bag, Events: events}, nil
 }
 
 // ListAllBaggage returns detailed baggage info for Staff/Admin.
@@ -79,6 +460,11 @@ func (s *Service) ListAllBaggage(ctx context.Context) ([]BaggageDetail, error) {
 	return s.repo.ListAllWithPassengerInfo(ctx)
 }
 
+// GetFlightBaggage returns the baggage checked in on a flight's active tickets.
+func (s *Service) GetFlightBaggage(ctx context.Context, flightID int64) ([]Baggage, error) {
+	return s.repo.ListByFlight(ctx, flightID)
+}
+
 // GetBaggageByTicketID returns baggage for a specific ticket (Used by Booking module).
 func (s *Service) GetBaggageByTicketID(ctx context.Context, ticketID int64) ([]Baggage, error) {
 	return s.repo.GetByTicketID(ctx, ticketID)
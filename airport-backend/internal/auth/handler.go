@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"airport-system/platform/apperror"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,12 +22,12 @@ func NewHandler(service *Service) *Handler {
 func (h *Handler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest(err))
 		return
 	}
 
 	if err := h.Service.Register(c.Request.Context(), req); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -36,15 +38,218 @@ func (h *Handler) Register(c *gin.Context) {
 func (h *Handler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest(err))
 		return
 	}
 
-	resp, err := h.Service.Login(c.Request.Context(), req)
+	client := ClientInfo{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}
+	resp, err := h.Service.Login(c.Request.Context(), req, client)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
+
+// Refresh handles exchanging a refresh token for a new token pair.
+func (h *Handler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	resp, err := h.Service.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Logout handles revoking the caller's current session.
+func (h *Handler) Logout(c *gin.Context) {
+	if err := h.Service.Logout(c.Request.Context(), c.GetInt64("sessionID")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// RevokeSessions handles revoking every session of a user (ADMIN only).
+func (h *Handler) RevokeSessions(c *gin.Context) {
+	if !RequireRole(c, RoleAdmin) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	revoked, err := h.Service.RevokeUserSessions(c.Request.Context(), userID, "admin_revoked")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked", "revoked": revoked})
+}
+
+// ListUsers handles searching users (ADMIN only).
+func (h *Handler) ListUsers(c *gin.Context) {
+	if !RequireRole(c, RoleAdmin) {
+		return
+	}
+
+	var filter UserFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	list, err := h.Service.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetUser handles retrieving a single user (ADMIN only).
+func (h *Handler) GetUser(c *gin.Context) {
+	if !RequireRole(c, RoleAdmin) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	user, err := h.Service.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateRole handles changing a user's role (ADMIN only).
+func (h *Handler) UpdateRole(c *gin.Context) {
+	if !RequireRole(c, RoleAdmin) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	user, err := h.Service.ChangeRole(c.Request.Context(), c.GetInt64("userID"), userID, req.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DisableUser handles disabling a user account (ADMIN only).
+func (h *Handler) DisableUser(c *gin.Context) {
+	if !RequireRole(c, RoleAdmin) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req DisableUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	user, err := h.Service.DisableUser(c.Request.Context(), c.GetInt64("userID"), userID, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// EnableUser handles re-enabling a disabled user account (ADMIN only).
+func (h *Handler) EnableUser(c *gin.Context) {
+	if !RequireRole(c, RoleAdmin) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	user, err := h.Service.EnableUser(c.Request.Context(), c.GetInt64("userID"), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// InviteStaff handles inviting a STAFF or ADMIN user (ADMIN only).
+func (h *Handler) InviteStaff(c *gin.Context) {
+	if !RequireRole(c, RoleAdmin) {
+		return
+	}
+
+	var req InviteStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	resp, err := h.Service.InviteStaff(c.Request.Context(), c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// AcceptInvitation handles an invited user setting their password.
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	if err := h.Service.AcceptInvitation(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted, you can now log in"})
+}
@@ -1,7 +1,7 @@
 package auth
 
 import (
-	"net/http"
+	"airport-system/platform/apperror"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,7 +15,7 @@ func RequireRole(c *gin.Context, allowedRoles ...string) bool {
 			return true
 		}
 	}
-	c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	c.Error(apperror.ErrForbidden)
 	c.Abort()
 	return false
 }
@@ -1,60 +1,68 @@
 package auth
 
 import (
-	"fmt"
-	"net/http"
+	"airport-system/platform/apperror"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthMiddleware authenticates requests using JWT.
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// AuthMiddleware authenticates requests using JWT access tokens and rejects tokens whose
+// session has been revoked (logout or admin revocation) or whose account is disabled.
+func AuthMiddleware(service *Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Error(apperror.ErrUnauthenticated)
+			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+			c.Error(ErrInvalidToken)
+			c.Abort()
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		claims, err := service.ParseAccessToken(tokenString)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
+		userIDFloat, ok := claims["sub"].(float64)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Error(ErrInvalidToken)
+			c.Abort()
 			return
 		}
+		userID := int64(userIDFloat)
 
-		userIDFloat, ok := claims["sub"].(float64)
+		sessionIDFloat, ok := claims["sid"].(float64)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
+			c.Error(ErrInvalidToken)
+			c.Abort()
 			return
 		}
-		userID := int64(userIDFloat)
+		sessionID := int64(sessionIDFloat)
 
-		role, ok := claims["role"].(string)
-		if !ok {
-			role = "PASSENGER"
+		// The role is read from the database so role changes and disabled accounts apply immediately
+		role, active, err := service.GetSessionPrincipal(c.Request.Context(), sessionID, userID)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !active {
+			c.Error(ErrSessionRevoked)
+			c.Abort()
+			return
 		}
 
 		c.Set("userID", userID)
+		c.Set("sessionID", sessionID)
 		c.Set("role", role)
 		c.Next()
 	}
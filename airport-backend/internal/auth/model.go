@@ -2,14 +2,37 @@ package auth
 
 import "time"
 
+// User roles.
+const (
+	RolePassenger = "PASSENGER"
+	RoleStaff     = "STAFF"
+	RoleAdmin     = "ADMIN"
+)
+
+// User account statuses. Only ACTIVE users can log in.
+const (
+	UserStatusActive   = "ACTIVE"
+	UserStatusInvited  = "INVITED"
+	UserStatusDisabled = "DISABLED"
+)
+
 // User represents a user in the system.
 type User struct {
-	ID           int64     `json:"id"`
-	FullName     string    `json:"full_name"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // Never return password hash in JSON
-	Role         string    `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID             int64      `json:"id"`
+	FullName       string     `json:"full_name"`
+	Email          string     `json:"email"`
+	PasswordHash   string     `json:"-"` // Never return password hash in JSON
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason *string    `json:"disabled_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsValidRole reports whether role is a known user role.
+func IsValidRole(role string) bool {
+	return role == RolePassenger || role == RoleStaff || role == RoleAdmin
 }
 
 // RegisterRequest defines the body for user registration.
@@ -25,7 +48,96 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// AuthResponse is returned by login and refresh.
+// Token is a short-lived access token; RefreshToken is exchanged at /auth/refresh for a new pair.
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  *User  `json:"user"`
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             *User     `json:"user"`
+}
+
+// RefreshRequest defines the body for refreshing an access token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Session is a server-side login whose refresh token rotates on every use.
+type Session struct {
+	ID                int64      `json:"id"`
+	UserID            int64      `json:"user_id"`
+	RefreshTokenHash  string     `json:"-"`
+	PreviousTokenHash *string    `json:"-"`
+	UserAgent         string     `json:"user_agent"`
+	IPAddress         string     `json:"ip_address"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	RotatedAt         *time.Time `json:"rotated_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	RevokedReason     *string    `json:"revoked_reason"`
+}
+
+// ClientInfo identifies the client a session was created from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// UserFilter defines criteria for listing users.
+type UserFilter struct {
+	Query  string `form:"q"`      // Matches name or email (case-insensitive)
+	Role   string `form:"role"`   // PASSENGER, STAFF, ADMIN
+	Status string `form:"status"` // ACTIVE, INVITED, DISABLED
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+// UserList is a page of users.
+type UserList struct {
+	Users  []User `json:"users"`
+	Total  int    `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// UpdateRoleRequest defines the body for changing a user's role.
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// DisableUserRequest defines the body for disabling a user.
+type DisableUserRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// InviteStaffRequest defines the body for inviting a STAFF or ADMIN user.
+type InviteStaffRequest struct {
+	FullName string `json:"full_name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// Invitation is a one-time link that lets an invited user set their password.
+type Invitation struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	InvitedBy  *int64     `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// InvitationResponse is returned when a staff invitation is created.
+// The link is only shown once; the token behind it is stored hashed.
+type InvitationResponse struct {
+	User       *User       `json:"user"`
+	Invitation *Invitation `json:"invitation"`
+	Link       string      `json:"link"`
+}
+
+// AcceptInvitationRequest defines the body for accepting an invitation.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
 }
@@ -3,7 +3,12 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // Repository handles database operations for users.
@@ -16,16 +21,43 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{DB: db}
 }
 
+// userColumns is the column list read by scanUser.
+const userColumns = `id, full_name, email, password_hash, role, status, disabled_at, disabled_reason, created_at, updated_at`
+
+func scanUser(row interface{ Scan(dest ...any) error }) (*User, error) {
+	user := &User{}
+	err := row.Scan(
+		&user.ID,
+		&user.FullName,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Role,
+		&user.Status,
+		&user.DisabledAt,
+		&user.DisabledReason,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // CreateUser inserts a new user into the database and returns the ID.
+// Returns ErrEmailTaken if the email is already registered.
 func (r *Repository) CreateUser(ctx context.Context, user *User) (int64, error) {
 	query := `
-		INSERT INTO users (full_name, email, password_hash, role, created_at)
-		VALUES ($1, $2, $3, $4, NOW())
+		INSERT INTO users (full_name, email, password_hash, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
 		RETURNING id
 	`
 	var id int64
 	err := r.DB.QueryRowContext(ctx, query, user.FullName, user.Email, user.PasswordHash, user.Role).Scan(&id)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrEmailTaken
+		}
 		return 0, fmt.Errorf("failed to create user: %w", err)
 	}
 	return id, nil
@@ -33,20 +65,8 @@ func (r *Repository) CreateUser(ctx context.Context, user *User) (int64, error)
 
 // GetUserByEmail retrieves a user by their email address.
 func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
-	query := `
-		SELECT id, full_name, email, password_hash, role, created_at
-		FROM users
-		WHERE email = $1
-	`
-	user := &User{}
-	err := r.DB.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.FullName,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Role,
-		&user.CreatedAt,
-	)
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	user, err := scanUser(r.DB.QueryRowContext(ctx, query, email))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // User not found
@@ -55,3 +75,274 @@ func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*User, e
 	}
 	return user, nil
 }
+
+// GetUserByID retrieves a user by ID.
+func (r *Repository) GetUserByID(ctx context.Context, id int64) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	user, err := scanUser(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers returns users matching the filter, newest first, and the total number of matches.
+func (r *Repository) ListUsers(ctx context.Context, filter UserFilter) ([]User, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	argID := 1
+
+	if filter.Query != "" {
+		where += fmt.Sprintf(" AND (LOWER(full_name) LIKE $%d OR LOWER(email) LIKE $%d)", argID, argID)
+		args = append(args, "%"+strings.ToLower(filter.Query)+"%")
+		argID++
+	}
+	if filter.Role != "" {
+		where += fmt.Sprintf(" AND role = $%d", argID)
+		args = append(args, filter.Role)
+		argID++
+	}
+	if filter.Status != "" {
+		where += fmt.Sprintf(" AND status = $%d", argID)
+		args = append(args, filter.Status)
+		argID++
+	}
+
+	var total int
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argID, argID+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+	return users, total, nil
+}
+
+// UpdateRole changes a user's role.
+func (r *Repository) UpdateRole(ctx context.Context, id int64, role string) error {
+	query := `UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := r.DB.ExecContext(ctx, query, role, id); err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	return nil
+}
+
+// SetDisabled disables (with a reason) or re-enables a user account. A re-enabled account that
+// never set a password goes back to INVITED, so its invitation can still be accepted.
+func (r *Repository) SetDisabled(ctx context.Context, id int64, disabled bool, reason string) error {
+	query := `
+		UPDATE users
+		SET status = CASE WHEN password_hash = '' THEN 'INVITED' ELSE 'ACTIVE' END,
+		    disabled_at = NULL, disabled_reason = NULL, updated_at = NOW()
+		WHERE id = $1
+	`
+	args := []interface{}{id}
+	if disabled {
+		query = `
+			UPDATE users
+			SET status = 'DISABLED', disabled_at = NOW(), disabled_reason = $2, updated_at = NOW()
+			WHERE id = $1
+		`
+		args = append(args, reason)
+	}
+	if _, err := r.DB.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+	return nil
+}
+
+// CreateInvitedUser inserts a user in INVITED status together with its invitation.
+// Returns ErrEmailTaken if the email is already registered.
+func (r *Repository) CreateInvitedUser(ctx context.Context, user *User, inv *Invitation, tokenHash string) error {
+	query := `
+		WITH new_user AS (
+			INSERT INTO users (full_name, email, password_hash, role, status, created_at, updated_at)
+			VALUES ($1, $2, '', $3, 'INVITED', NOW(), NOW())
+			RETURNING id, created_at
+		)
+		INSERT INTO user_invitations (user_id, token_hash, invited_by, expires_at, created_at)
+		SELECT id, $4, $5, $6, NOW() FROM new_user
+		RETURNING id, user_id, created_at
+	`
+	err := r.DB.QueryRowContext(ctx, query, user.FullName, user.Email, user.Role, tokenHash, inv.InvitedBy, inv.ExpiresAt).
+		Scan(&inv.ID, &inv.UserID, &inv.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+	user.ID = inv.UserID
+	user.Status = UserStatusInvited
+	user.CreatedAt = inv.CreatedAt
+	user.UpdatedAt = inv.CreatedAt
+	return nil
+}
+
+// AcceptInvitation consumes an unexpired invitation, sets the password and activates the user.
+// Returns the user ID, or 0 if the token is unknown, expired or already used.
+func (r *Repository) AcceptInvitation(ctx context.Context, tokenHash, passwordHash string) (int64, error) {
+	query := `
+		WITH inv AS (
+			UPDATE user_invitations
+			SET accepted_at = NOW()
+			WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+			RETURNING user_id
+		)
+		UPDATE users
+		SET password_hash = $2, status = 'ACTIVE', updated_at = NOW()
+		FROM inv
+		WHERE users.id = inv.user_id AND users.status = 'INVITED'
+		RETURNING users.id
+	`
+	var id int64
+	err := r.DB.QueryRowContext(ctx, query, tokenHash, passwordHash).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	return id, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+const sessionColumns = `id, user_id, refresh_token_hash, previous_token_hash, user_agent, ip_address, expires_at, created_at, rotated_at, revoked_at, revoked_reason`
+
+func scanSession(row interface{ Scan(dest ...any) error }) (*Session, error) {
+	var s Session
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.RefreshTokenHash, &s.PreviousTokenHash, &s.UserAgent, &s.IPAddress,
+		&s.ExpiresAt, &s.CreatedAt, &s.RotatedAt, &s.RevokedAt, &s.RevokedReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// CreateSession inserts a new session and returns its ID.
+func (r *Repository) CreateSession(ctx context.Context, s *Session) (int64, error) {
+	query := `
+		INSERT INTO sessions (user_id, refresh_token_hash, user_agent, ip_address, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id
+	`
+	var id int64
+	err := r.DB.QueryRowContext(ctx, query, s.UserID, s.RefreshTokenHash, s.UserAgent, s.IPAddress, s.ExpiresAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create session: %w", err)
+	}
+	return id, nil
+}
+
+// GetSessionByTokenHash retrieves the session whose current refresh token has the given hash.
+func (r *Repository) GetSessionByTokenHash(ctx context.Context, hash string) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE refresh_token_hash = $1`
+	s, err := scanSession(r.DB.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return s, nil
+}
+
+// GetSessionByPreviousHash retrieves the session whose previous (already rotated) refresh token has the given hash.
+func (r *Repository) GetSessionByPreviousHash(ctx context.Context, hash string) (*Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE previous_token_hash = $1`
+	s, err := scanSession(r.DB.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return s, nil
+}
+
+// RotateSession replaces the refresh token of an active session if oldHash is still current.
+// Returns false if the session was rotated or revoked concurrently.
+func (r *Repository) RotateSession(ctx context.Context, id int64, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	query := `
+		UPDATE sessions
+		SET previous_token_hash = refresh_token_hash,
+		    refresh_token_hash = $1,
+		    expires_at = $2,
+		    rotated_at = NOW()
+		WHERE id = $3 AND refresh_token_hash = $4 AND revoked_at IS NULL
+	`
+	res, err := r.DB.ExecContext(ctx, query, newHash, expiresAt, id, oldHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate session: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate session: %w", err)
+	}
+	return n > 0, nil
+}
+
+// RevokeSession marks a session as revoked.
+func (r *Repository) RevokeSession(ctx context.Context, id int64, reason string) error {
+	query := `UPDATE sessions SET revoked_at = NOW(), revoked_reason = $1 WHERE id = $2 AND revoked_at IS NULL`
+	if _, err := r.DB.ExecContext(ctx, query, reason, id); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user and returns how many were revoked.
+func (r *Repository) RevokeUserSessions(ctx context.Context, userID int64, reason string) (int64, error) {
+	query := `UPDATE sessions SET revoked_at = NOW(), revoked_reason = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	res, err := r.DB.ExecContext(ctx, query, reason, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// GetSessionPrincipal returns the current role of the user behind a session, and whether
+// the session is still usable: not revoked or expired, and the user account is ACTIVE.
+func (r *Repository) GetSessionPrincipal(ctx context.Context, sessionID, userID int64) (string, bool, error) {
+	query := `
+		SELECT u.role
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.id = $1 AND s.user_id = $2
+		  AND s.revoked_at IS NULL AND s.expires_at > NOW()
+		  AND u.status = 'ACTIVE'
+	`
+	var role string
+	if err := r.DB.QueryRowContext(ctx, query, sessionID, userID).Scan(&role); err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return role, true, nil
+}
@@ -4,11 +4,28 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// RegisterRoutes sets up the authentication routes.
-func RegisterRoutes(r *gin.RouterGroup, h *Handler) {
+// RegisterRoutes sets up the authentication and user administration routes.
+func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
 	authGroup := r.Group("/auth")
 	{
 		authGroup.POST("/register", h.Register)
 		authGroup.POST("/login", h.Login)
+		authGroup.POST("/refresh", h.Refresh)
+		authGroup.POST("/invitations/accept", h.AcceptInvitation)
+
+		// Protected routes
+		authGroup.POST("/logout", authMiddleware, h.Logout)
+	}
+
+	adminGroup := r.Group("/admin/users")
+	adminGroup.Use(authMiddleware)
+	{
+		adminGroup.GET("", h.ListUsers)
+		adminGroup.POST("/invitations", h.InviteStaff)
+		adminGroup.GET("/:id", h.GetUser)
+		adminGroup.PATCH("/:id/role", h.UpdateRole)
+		adminGroup.POST("/:id/disable", h.DisableUser)
+		adminGroup.POST("/:id/enable", h.EnableUser)
+		adminGroup.POST("/:id/revoke-sessions", h.RevokeSessions)
 	}
 }
@@ -1,29 +1,95 @@
 package auth
 
 import (
+	"airport-system/platform/apperror"
 	"context"
-	"errors"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// DefaultAccessTokenTTL is the lifetime of an access token.
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// DefaultRefreshTokenTTL is how long a session can go without being refreshed.
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// DefaultInvitationTTL is how long a staff invitation link stays valid.
+	DefaultInvitationTTL = 7 * 24 * time.Hour
+	// DefaultInvitationURL is the page invited users open to set their password.
+	DefaultInvitationURL = "http://localhost:5500/accept-invite.html?token="
+)
+
+var (
+	// ErrInvalidCredentials is returned when email or password is wrong.
+	ErrInvalidCredentials = apperror.Unauthorized("invalid_credentials", "invalid credentials")
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or revoked.
+	ErrInvalidRefreshToken = apperror.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	// ErrInvalidToken is returned when an access token is malformed, forged or expired.
+	ErrInvalidToken = apperror.Unauthorized("invalid_token", "invalid token")
+	// ErrSessionRevoked is returned when an access token's session is revoked or its account disabled.
+	ErrSessionRevoked = apperror.Unauthorized("session_revoked", "session has been revoked or account is disabled")
+	// ErrAccountDisabled is returned when a disabled user tries to log in.
+	ErrAccountDisabled = apperror.Forbidden("account_disabled", "account is disabled")
+	// ErrEmailTaken is returned when the email is already registered.
+	ErrEmailTaken = apperror.Conflict("email_taken", "email is already registered")
+	// ErrUserNotFound is returned when the user does not exist.
+	ErrUserNotFound = apperror.NotFound("user_not_found", "user not found")
+	// ErrInvalidInvitation is returned when an invitation token is unknown, expired or used.
+	ErrInvalidInvitation = apperror.Validation("invalid_invitation", "invalid or expired invitation")
+	// ErrInvalidUserRequest is returned for malformed user administration requests.
+	ErrInvalidUserRequest = apperror.Validation("invalid_user_request", "invalid user request")
+)
+
+// Config holds token and invitation settings. Zero values fall back to the defaults above.
+type Config struct {
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	InvitationTTL   time.Duration
+	InvitationURL   string // Prefix the invitation token is appended to
+}
+
 // Service handles authentication business logic.
 type Service struct {
-	repo      *Repository
-	log       *slog.Logger
-	jwtSecret []byte
+	repo            *Repository
+	log             *slog.Logger
+	jwtSecret       []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	invitationTTL   time.Duration
+	invitationURL   string
 }
 
 // NewService creates a new auth service.
-func NewService(repo *Repository, log *slog.Logger, jwtSecret string) *Service {
+func NewService(repo *Repository, log *slog.Logger, cfg Config) *Service {
+	if cfg.AccessTokenTTL <= 0 {
+		cfg.AccessTokenTTL = DefaultAccessTokenTTL
+	}
+	if cfg.RefreshTokenTTL <= 0 {
+		cfg.RefreshTokenTTL = DefaultRefreshTokenTTL
+	}
+	if cfg.InvitationTTL <= 0 {
+		cfg.InvitationTTL = DefaultInvitationTTL
+	}
+	if cfg.InvitationURL == "" {
+		cfg.InvitationURL = DefaultInvitationURL
+	}
 	return &Service{
-		repo:      repo,
-		log:       log,
-		jwtSecret: []byte(jwtSecret),
+		repo:            repo,
+		log:             log,
+		jwtSecret:       []byte(cfg.JWTSecret),
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		refreshTokenTTL: cfg.RefreshTokenTTL,
+		invitationTTL:   cfg.InvitationTTL,
+		invitationURL:   cfg.InvitationURL,
 	}
 }
 
@@ -38,7 +104,7 @@ func (s *Service) Register(ctx context.Context, req RegisterRequest) error {
 		FullName:     req.FullName,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
-		Role:         "PASSENGER", // Default role
+		Role:         RolePassenger, // Default role
 	}
 
 	id, err := s.repo.CreateUser(ctx, user)
@@ -50,38 +116,334 @@ func (s *Service) Register(ctx context.Context, req RegisterRequest) error {
 	return nil
 }
 
-// Login authenticates a user and returns a JWT token.
-func (s *Service) Login(ctx context.Context, req LoginRequest) (*AuthResponse, error) {
+// Login authenticates a user, opens a session and returns an access/refresh token pair.
+func (s *Service) Login(ctx context.Context, req LoginRequest, client ClientInfo) (*AuthResponse, error) {
 	user, err := s.repo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+	switch user.Status {
+	case UserStatusActive:
+	case UserStatusDisabled:
+		return nil, ErrAccountDisabled
+	default:
+		// Invited users have no password yet; treat like a wrong password
+		return nil, ErrInvalidCredentials
+	}
+
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        client.UserAgent,
+		IPAddress:        client.IPAddress,
+		ExpiresAt:        time.Now().Add(s.refreshTokenTTL),
+	}
+	sessionID, err := s.repo.CreateSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, sessionID, refreshToken, session.ExpiresAt)
+}
+
+// Refresh exchanges a refresh token for a new token pair, rotating the refresh token.
+// Presenting an already-rotated token revokes the whole session, since it means the token leaked.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*AuthResponse, error) {
+	hash := hashToken(refreshToken)
+
+	session, err := s.repo.GetSessionByTokenHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		reused, err := s.repo.GetSessionByPreviousHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if reused != nil && reused.RevokedAt == nil {
+			if err := s.repo.RevokeSession(ctx, reused.ID, "refresh_token_reuse"); err != nil {
+				return nil, err
+			}
+			s.log.Warn("Refresh token reuse detected, session revoked", "session_id", reused.ID, "user_id", reused.UserID)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if session.RevokedAt != nil || !session.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Status != UserStatusActive {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.refreshTokenTTL)
+
+	rotated, err := s.repo.RotateSession(ctx, session.ID, hash, newHash, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// A concurrent refresh won the race with the same token
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issueTokens(user, session.ID, newToken, expiresAt)
+}
+
+// Logout revokes the given session.
+func (s *Service) Logout(ctx context.Context, sessionID int64) error {
+	if err := s.repo.RevokeSession(ctx, sessionID, "logout"); err != nil {
+		return err
+	}
+	s.log.Info("Session logged out", "session_id", sessionID)
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user, which also invalidates
+// their outstanding access tokens at AuthMiddleware.
+func (s *Service) RevokeUserSessions(ctx context.Context, userID int64, reason string) (int64, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, ErrUserNotFound
 	}
 
-	token, err := s.generateToken(user)
+	n, err := s.repo.RevokeUserSessions(ctx, userID, reason)
+	if err != nil {
+		return 0, err
+	}
+	s.log.Info("User sessions revoked", "user_id", userID, "count", n, "reason", reason)
+	return n, nil
+}
+
+// ParseAccessToken verifies an access token and returns its claims.
+func (s *Service) ParseAccessToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// GetSessionPrincipal returns the user's current role if the session behind an access
+// token is still valid and the account is active.
+func (s *Service) GetSessionPrincipal(ctx context.Context, sessionID, userID int64) (string, bool, error) {
+	return s.repo.GetSessionPrincipal(ctx, sessionID, userID)
+}
+
+// ListUsers searches users for the admin console.
+func (s *Service) ListUsers(ctx context.Context, filter UserFilter) (*UserList, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 200 {
+		filter.Limit = 200
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if filter.Role != "" && !IsValidRole(filter.Role) {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidUserRequest, filter.Role)
+	}
+
+	users, total, err := s.repo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &UserList{Users: users, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+// GetUser retrieves a user by ID.
+func (s *Service) GetUser(ctx context.Context, userID int64) (*User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// ChangeRole sets a user's role. Admins cannot change their own role, so the last admin
+// cannot lock everyone out. The new role applies to the user's next request.
+func (s *Service) ChangeRole(ctx context.Context, actorID, userID int64, role string) (*User, error) {
+	if !IsValidRole(role) {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidUserRequest, role)
+	}
+	if actorID == userID {
+		return nil, fmt.Errorf("%w: you cannot change your own role", ErrInvalidUserRequest)
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("User role changed", "user_id", userID, "from", user.Role, "to", role, "actor_id", actorID)
+	return s.GetUser(ctx, userID)
+}
+
+// DisableUser blocks a user from logging in and revokes all of their sessions.
+func (s *Service) DisableUser(ctx context.Context, actorID, userID int64, reason string) (*User, error) {
+	if actorID == userID {
+		return nil, fmt.Errorf("%w: you cannot disable your own account", ErrInvalidUserRequest)
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetDisabled(ctx, userID, true, reason); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.RevokeUserSessions(ctx, userID, "account_disabled"); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("User disabled", "user_id", userID, "reason", reason, "actor_id", actorID)
+	return s.GetUser(ctx, userID)
+}
+
+// EnableUser re-enables a disabled user account. Accounts disabled before accepting their
+// invitation return to INVITED.
+func (s *Service) EnableUser(ctx context.Context, actorID, userID int64) (*User, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != UserStatusDisabled {
+		return nil, fmt.Errorf("%w: user is not disabled", ErrInvalidUserRequest)
+	}
+
+	if err := s.repo.SetDisabled(ctx, userID, false, ""); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("User enabled", "user_id", userID, "actor_id", actorID)
+	return s.GetUser(ctx, userID)
+}
+
+// InviteStaff creates a STAFF or ADMIN account in INVITED status and returns a one-time
+// link the invitee uses to set their password.
+func (s *Service) InviteStaff(ctx context.Context, actorID int64, req InviteStaffRequest) (*InvitationResponse, error) {
+	if req.Role != RoleStaff && req.Role != RoleAdmin {
+		return nil, fmt.Errorf("%w: invitations are only for STAFF or ADMIN accounts", ErrInvalidUserRequest)
+	}
+
+	token, tokenHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	user := &User{FullName: req.FullName, Email: req.Email, Role: req.Role}
+	inv := &Invitation{InvitedBy: &actorID, ExpiresAt: time.Now().Add(s.invitationTTL)}
+	if err := s.repo.CreateInvitedUser(ctx, user, inv, tokenHash); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Staff invited", "user_id", user.ID, "email", user.Email, "role", user.Role, "actor_id", actorID)
+	return &InvitationResponse{
+		User:       user,
+		Invitation: inv,
+		Link:       s.invitationURL + url.QueryEscape(token),
+	}, nil
+}
+
+// AcceptInvitation sets the invited user's password and activates the account.
+func (s *Service) AcceptInvitation(ctx context.Context, req AcceptInvitationRequest) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 12)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	userID, err := s.repo.AcceptInvitation(ctx, hashToken(req.Token), string(hashedPassword))
+	if err != nil {
+		return err
+	}
+	if userID == 0 {
+		return ErrInvalidInvitation
+	}
+
+	s.log.Info("Invitation accepted", "user_id", userID)
+	return nil
+}
+
+func (s *Service) issueTokens(user *User, sessionID int64, refreshToken string, refreshExpiresAt time.Time) (*AuthResponse, error) {
+	expiresAt := time.Now().Add(s.accessTokenTTL)
+	token, err := s.generateToken(user, sessionID, expiresAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	return &AuthResponse{
-		Token: token,
-		User:  user,
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		User:             user,
 	}, nil
 }
 
-func (s *Service) generateToken(user *User) (string, error) {
+func (s *Service) generateToken(user *User, sessionID int64, expiresAt time.Time) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":  user.ID,
+		"sid":  sessionID,
 		"role": user.Role,
-		"exp":  time.Now().Add(24 * time.Hour).Unix(),
+		"iat":  time.Now().Unix(),
+		"exp":  expiresAt.Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(s.jwtSecret)
 }
+
+// newRefreshToken returns a random opaque token and its SHA-256 hash.
+// It is used for refresh tokens and invitation tokens.
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
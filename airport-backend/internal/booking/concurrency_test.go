@@ -0,0 +1,331 @@
+package booking_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"airport-system/internal/airport"
+	"airport-system/internal/airportops"
+	"airport-system/internal/booking"
+	"airport-system/internal/fleet"
+	"airport-system/internal/flight"
+	"airport-system/internal/notification"
+	"airport-system/internal/passenger"
+	"airport-system/internal/payment"
+	"airport-system/internal/pricing"
+	"airport-system/internal/seating"
+	"airport-system/platform/database"
+)
+
+// The concurrency suite fires parallel bookings at freshly created flights and checks against
+// the database that a flight is never oversold and no seat is sold twice. It needs a migrated
+// PostgreSQL database named by DATABASE_URL and is skipped otherwise.
+const (
+	stressBookings = 300 // Parallel booking attempts per round
+	stressSeats    = 100 // Seats on each test flight
+	stressRounds   = 3   // Rounds, each on a new flight; one with -short
+)
+
+// testCard is the mock gateway's always-approved card.
+var testCard = payment.Card{Number: payment.MockCardSuccess, ExpMonth: 12, ExpYear: time.Now().Year() + 1, CVC: "123"}
+
+type harness struct {
+	db          *sql.DB
+	flightRepo  *flight.Repository
+	passService *passenger.Service
+	bookService *booking.Service
+	runID       string
+}
+
+type bookedTicket struct {
+	userID int64
+	ticket *booking.Ticket
+}
+
+type tally struct {
+	mu      sync.Mutex
+	booked  []bookedTicket
+	full    int
+	failed  int
+	lastErr error
+}
+
+func TestConcurrentBookingNeverOversells(t *testing.T) {
+	h := newHarness(t)
+
+	rounds := stressRounds
+	if testing.Short() {
+		rounds = 1
+	}
+	for round := 1; round <= rounds; round++ {
+		t.Run(fmt.Sprintf("round %d", round), func(t *testing.T) {
+			h.runRound(t, round)
+		})
+	}
+}
+
+// newHarness connects to the test database and wires the booking service as the API does. The
+// data it creates is deleted when the test ends.
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresDB(dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if err := migrator.EnsureCurrent(context.Background()); err != nil {
+		t.Fatalf("database schema is not current: %v", err)
+	}
+
+	log := slog.New(slog.DiscardHandler)
+	txManager := database.NewTxManager(db)
+	flightRepo := flight.NewRepository(db)
+	passService := passenger.NewService(passenger.NewRepository(db), txManager, log)
+	opsService := airportops.NewService(airportops.NewRepository(db), txManager, log)
+	seatService := seating.NewService(seating.NewRepository(db), txManager, log)
+	airportService := airport.NewService(airport.NewRepository(db), txManager, log)
+	fleetService := fleet.NewService(fleet.NewRepository(db), seatService, txManager, log)
+	flightService := flight.NewService(flightRepo, airportService, fleetService, seatService, txManager, log)
+	payService := payment.NewService(payment.NewRepository(db), payment.NewMockGateway(""), log, payment.Config{})
+	priceService := pricing.NewService(pricing.NewRepository(db), flightRepo, airportService, txManager, log, pricing.Config{})
+	notifService := notification.NewService(notification.NewRepository(db), log)
+
+	h := &harness{
+		db:          db,
+		flightRepo:  flightRepo,
+		passService: passService,
+		bookService: booking.NewService(booking.NewRepository(db), flightRepo, flightService, txManager, opsService, passService, seatService, payService, priceService, notifService, log),
+		runID:       strconv.FormatInt(time.Now().Unix(), 36),
+	}
+	t.Cleanup(func() {
+		if err := h.cleanup(context.Background()); err != nil {
+			t.Errorf("failed to clean up test data: %v", err)
+		}
+	})
+	return h
+}
+
+// runRound books stressBookings times in parallel and checks the invariants, then cancels half
+// of the tickets while the same number of new passengers book, and checks them again.
+func (h *harness) runRound(t *testing.T, round int) {
+	ctx := t.Context()
+	flightID := h.createFlight(t, round)
+
+	// Phase 1: everyone books at once.
+	tl := h.bookAll(ctx, flightID, h.createPassengers(t, fmt.Sprintf("r%d-a", round)))
+	t.Logf("parallel booking: %d booked, %d full, %d failed", len(tl.booked), tl.full, tl.failed)
+
+	if tl.failed > 0 {
+		t.Fatalf("%d bookings failed with unexpected errors (last: %v)", tl.failed, tl.lastErr)
+	}
+	expected := min(stressBookings, stressSeats)
+	if len(tl.booked) != expected {
+		t.Fatalf("expected %d successful bookings, got %d", expected, len(tl.booked))
+	}
+	h.verify(t, flightID, expected)
+
+	// Phase 2: cancel half while a new wave of passengers books concurrently.
+	cancelCount := len(tl.booked) / 2
+	rebookers := h.createPassengers(t, fmt.Sprintf("r%d-b", round))
+
+	var wg sync.WaitGroup
+	var cancelErrs int
+	var cancelMu sync.Mutex
+	start := make(chan struct{})
+	for _, b := range tl.booked[:cancelCount] {
+		wg.Add(1)
+		go func(ticketID, userID int64) {
+			defer wg.Done()
+			<-start
+			if _, err := h.bookService.CancelTicket(ctx, userID, ticketID); err != nil {
+				cancelMu.Lock()
+				cancelErrs++
+				cancelMu.Unlock()
+			}
+		}(b.ticket.ID, b.userID)
+	}
+
+	var t2 *tally
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		t2 = h.bookAll(ctx, flightID, rebookers)
+	}()
+	close(start)
+	wg.Wait()
+	t.Logf("cancel and rebook: %d cancelled, %d rebooked, %d full, %d failed", cancelCount-cancelErrs, len(t2.booked), t2.full, t2.failed)
+
+	if cancelErrs > 0 {
+		t.Fatalf("%d cancellations failed", cancelErrs)
+	}
+	if t2.failed > 0 {
+		t.Fatalf("%d rebookings failed with unexpected errors (last: %v)", t2.failed, t2.lastErr)
+	}
+	h.verify(t, flightID, expected-cancelCount+len(t2.booked))
+}
+
+// bookAll books the flight for every user at once, paying straight away.
+func (h *harness) bookAll(ctx context.Context, flightID int64, users []int64) *tally {
+	tl := &tally{}
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for _, userID := range users {
+		wg.Add(1)
+		go func(userID int64) {
+			defer wg.Done()
+			<-start
+			ticket, err := h.bookService.BookTicket(ctx, userID, booking.BookingRequest{FlightID: flightID})
+			if err == nil {
+				// Pay straight away so the ticket becomes ACTIVE
+				_, err = h.bookService.ConfirmPayment(ctx, userID, *ticket.RecordLocator, *ticket.PaymentIntentID, testCard)
+			}
+
+			tl.mu.Lock()
+			defer tl.mu.Unlock()
+			switch {
+			case err == nil:
+				tl.booked = append(tl.booked, bookedTicket{userID: userID, ticket: ticket})
+			case errors.Is(err, booking.ErrFlightFull):
+				tl.full++
+			default:
+				tl.failed++
+				tl.lastErr = err
+			}
+		}(userID)
+	}
+
+	close(start)
+	wg.Wait()
+	return tl
+}
+
+// verify checks the flight against the database: the inventory counter matches the number of
+// active tickets, neither exceeds capacity, and no seat is assigned twice.
+func (h *harness) verify(t *testing.T, flightID int64, expectedActive int) {
+	t.Helper()
+	ctx := t.Context()
+
+	var active, distinctSeats, unseated, sold, capacity int
+	err := h.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT seat_no), COUNT(*) FILTER (WHERE seat_no IS NULL)
+		FROM tickets
+		WHERE flight_id = $1 AND status = 'ACTIVE'
+	`, flightID).Scan(&active, &distinctSeats, &unseated)
+	if err != nil {
+		t.Fatalf("failed to count tickets: %v", err)
+	}
+	err = h.db.QueryRowContext(ctx, `SELECT sold, capacity FROM flight_inventory WHERE flight_id = $1`, flightID).Scan(&sold, &capacity)
+	if err != nil {
+		t.Fatalf("failed to read inventory: %v", err)
+	}
+
+	if active > stressSeats {
+		t.Errorf("oversold: %d active tickets for %d seats", active, stressSeats)
+	}
+	if active != expectedActive {
+		t.Errorf("expected %d active tickets, found %d", expectedActive, active)
+	}
+	if sold != active {
+		t.Errorf("inventory counter %d does not match %d active tickets", sold, active)
+	}
+	if capacity != stressSeats {
+		t.Errorf("inventory capacity %d does not match flight capacity %d", capacity, stressSeats)
+	}
+	if unseated > 0 {
+		t.Errorf("%d active tickets have no seat", unseated)
+	}
+	if distinctSeats != active {
+		t.Errorf("double-sold seat: %d active tickets share %d seats", active, distinctSeats)
+	}
+	if t.Failed() {
+		t.FailNow()
+	}
+}
+
+func (h *harness) createFlight(t *testing.T, round int) int64 {
+	t.Helper()
+	dep := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Minute)
+	f := &flight.Flight{
+		FlightNo:      fmt.Sprintf("ST%d-%s", round, h.runID),
+		Origin:        "TSA",
+		Destination:   "TSB",
+		DepartureTime: dep,
+		ArrivalTime:   dep.Add(2 * time.Hour),
+		Status:        "SCHEDULED",
+		TotalSeats:    stressSeats,
+	}
+	id, err := h.flightRepo.Create(t.Context(), f, nil)
+	if err != nil {
+		t.Fatalf("failed to create test flight: %v", err)
+	}
+	return id
+}
+
+// createPassengers creates stressBookings users with passenger profiles and returns their IDs.
+func (h *harness) createPassengers(t *testing.T, batch string) []int64 {
+	t.Helper()
+	ctx := t.Context()
+
+	users := make([]int64, 0, stressBookings)
+	for i := 0; i < stressBookings; i++ {
+		var userID int64
+		err := h.db.QueryRowContext(ctx, `
+			INSERT INTO users (full_name, email, password_hash, role, created_at)
+			VALUES ($1, $2, 'x', 'PASSENGER', NOW())
+			RETURNING id
+		`, fmt.Sprintf("Stress %s-%d", batch, i), fmt.Sprintf("stress-%s-%s-%d@example.invalid", h.runID, batch, i)).Scan(&userID)
+		if err != nil {
+			t.Fatalf("failed to create test user: %v", err)
+		}
+
+		if _, err := h.passService.CreateProfile(ctx, userID, fmt.Sprintf("ST%07d", i), ""); err != nil {
+			t.Fatalf("failed to create passenger profile: %v", err)
+		}
+		users = append(users, userID)
+	}
+	return users
+}
+
+func (h *harness) cleanup(ctx context.Context) error {
+	txManager := database.NewTxManager(h.db)
+	return txManager.Run(ctx, func(ctx context.Context) error {
+		tx := database.GetTx(ctx)
+		statements := []string{
+			`DELETE FROM payment_refunds WHERE ticket_id IN (SELECT t.id FROM tickets t JOIN flights f ON f.id = t.flight_id WHERE f.flight_no LIKE '%-' || $1)`,
+			`DELETE FROM tickets WHERE flight_id IN (SELECT id FROM flights WHERE flight_no LIKE '%-' || $1)`,
+			`DELETE FROM payment_captures WHERE intent_id IN (SELECT id FROM payment_intents WHERE user_id IN (SELECT id FROM users WHERE email LIKE 'stress-' || $1 || '-%'))`,
+			`DELETE FROM payment_intents WHERE user_id IN (SELECT id FROM users WHERE email LIKE 'stress-' || $1 || '-%')`,
+			`DELETE FROM price_quotes WHERE user_id IN (SELECT id FROM users WHERE email LIKE 'stress-' || $1 || '-%')`,
+			`DELETE FROM bookings WHERE user_id IN (SELECT id FROM users WHERE email LIKE 'stress-' || $1 || '-%')`,
+			`DELETE FROM flights WHERE flight_no LIKE '%-' || $1`,
+			`DELETE FROM passengers WHERE user_id IN (SELECT id FROM users WHERE email LIKE 'stress-' || $1 || '-%')`,
+			`DELETE FROM users WHERE email LIKE 'stress-' || $1 || '-%'`,
+		}
+		for _, stmt := range statements {
+			if _, err := tx.ExecContext(ctx, stmt, h.runID); err != nil {
+				return fmt.Errorf("cleanup failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
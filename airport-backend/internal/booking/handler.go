@@ -1,6 +1,11 @@
 package booking
 
 import (
+	"airport-system/internal/auth"
+	"airport-system/internal/flight"
+	"airport-system/internal/payment"
+	"airport-system/platform/apperror"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -21,20 +26,20 @@ func NewHandler(service *Service) *Handler {
 func (h *Handler) Book(c *gin.Context) {
 	userIDVal, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(apperror.ErrUnauthenticated)
 		return
 	}
 	userID := userIDVal.(int64)
 
 	var req BookingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest(err))
 		return
 	}
 
 	ticket, err := h.Service.BookTicket(c.Request.Context(), userID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -45,14 +50,14 @@ func (h *Handler) Book(c *gin.Context) {
 func (h *Handler) GetMy(c *gin.Context) {
 	userIDVal, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(apperror.ErrUnauthenticated)
 		return
 	}
 	userID := userIDVal.(int64)
 
 	bookmarks, err := h.Service.GetMyBookings(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -63,7 +68,7 @@ func (h *Handler) GetMy(c *gin.Context) {
 func (h *Handler) Cancel(c *gin.Context) {
 	userIDVal, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(apperror.ErrUnauthenticated)
 		return
 	}
 	userID := userIDVal.(int64)
@@ -71,23 +76,41 @@ func (h *Handler) Cancel(c *gin.Context) {
 	ticketIDStr := c.Param("id")
 	ticketID, err := strconv.ParseInt(ticketIDStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ticket id"})
+		c.Error(apperror.ErrInvalidID)
 		return
 	}
 
-	if err := h.Service.CancelTicket(c.Request.Context(), userID, ticketID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result, err := h.Service.CancelTicket(c.Request.Context(), userID, ticketID)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Ticket cancelled successfully"})
+	c.JSON(http.StatusOK, result)
+}
+
+// RefundQuote handles quoting the refund for cancelling a ticket now.
+func (h *Handler) RefundQuote(c *gin.Context) {
+	ticketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	quote, err := h.Service.GetRefundQuote(c.Request.Context(), c.GetInt64("userID"), ticketID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
 }
 
 // GetMyBaggage handles retrieving user's baggage.
 func (h *Handler) GetMyBaggage(c *gin.Context) {
 	userIDVal, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Error(apperror.ErrUnauthenticated)
 		return
 	}
 	userID := userIDVal.(int64)
@@ -98,7 +121,7 @@ func (h *Handler) GetMyBaggage(c *gin.Context) {
 	if ticketIDStr != "" {
 		id, err := strconv.ParseInt(ticketIDStr, 10, 64)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ticket_id"})
+			c.Error(fmt.Errorf("%w: invalid ticket_id", apperror.ErrInvalidRequest))
 			return
 		}
 		ticketID = id
@@ -106,9 +129,341 @@ func (h *Handler) GetMyBaggage(c *gin.Context) {
 
 	baggage, err := h.Service.GetUserBaggage(c.Request.Context(), userID, ticketID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, baggage)
 }
+
+// TrackBaggage handles retrieving the status history of one of the user's bags.
+func (h *Handler) TrackBaggage(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.Error(apperror.ErrUnauthenticated)
+		return
+	}
+	userID := userIDVal.(int64)
+
+	baggageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	track, err := h.Service.TrackBaggage(c.Request.Context(), userID, baggageID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, track)
+}
+
+// CreatePNR handles booking several travellers under one record locator.
+func (h *Handler) CreatePNR(c *gin.Context) {
+	var req PNRTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	pnr, err := h.Service.CreatePNR(c.Request.Context(), c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, pnr)
+}
+
+// BookItinerary handles booking a connecting itinerary under a new PNR.
+func (h *Handler) BookItinerary(c *gin.Context) {
+	var req ItineraryBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	pnr, err := h.Service.BookItinerary(c.Request.Context(), c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, pnr)
+}
+
+// ListPNRs handles listing the caller's PNRs.
+func (h *Handler) ListPNRs(c *gin.Context) {
+	pnrs, err := h.Service.ListMyPNRs(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pnrs)
+}
+
+// GetPNR handles retrieving a PNR by record locator.
+func (h *Handler) GetPNR(c *gin.Context) {
+	pnr, err := h.Service.GetPNR(c.Request.Context(), c.GetInt64("userID"), c.Param("locator"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pnr)
+}
+
+// AddTravellers handles adding travellers to an existing PNR.
+func (h *Handler) AddTravellers(c *gin.Context) {
+	var req PNRTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	pnr, err := h.Service.AddTravellers(c.Request.Context(), c.GetInt64("userID"), c.Param("locator"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pnr)
+}
+
+// CancelPNR handles cancelling a whole PNR or some of its tickets.
+func (h *Handler) CancelPNR(c *gin.Context) {
+	var req CancelPNRRequest
+	// The body is optional: no body cancels the whole PNR
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+	}
+
+	pnr, err := h.Service.CancelPNR(c.Request.Context(), c.GetInt64("userID"), c.Param("locator"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pnr)
+}
+
+// ConfirmPayment handles paying one of a PNR's payment intents.
+func (h *Handler) ConfirmPayment(c *gin.Context) {
+	intentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req payment.ConfirmPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	pnr, err := h.Service.ConfirmPayment(c.Request.Context(), c.GetInt64("userID"), c.Param("locator"), intentID, req.Card)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pnr)
+}
+
+// PaymentWebhook handles notifications from the payment gateway. Duplicate deliveries are
+// acknowledged so that the gateway stops retrying them; deliveries for payments not known yet
+// are refused with 503 so that it retries them.
+func (h *Handler) PaymentWebhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	if err := h.Service.HandlePaymentWebhook(c.Request.Context(), payload, c.Request.Header); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// JoinWaitlist handles queueing a traveller for a sold-out flight.
+func (h *Handler) JoinWaitlist(c *gin.Context) {
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req JoinWaitlistRequest
+	// The body is optional: no body waitlists the caller's own profile
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+	}
+
+	entry, err := h.Service.JoinWaitlist(c.Request.Context(), c.GetInt64("userID"), flightID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// ListWaitlist handles listing the caller's waitlist entries and their positions.
+func (h *Handler) ListWaitlist(c *gin.Context) {
+	entries, err := h.Service.ListMyWaitlist(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// ConfirmWaitlistOffer handles booking the seat offered to a waitlisted traveller.
+func (h *Handler) ConfirmWaitlistOffer(c *gin.Context) {
+	entryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	pnr, err := h.Service.ConfirmWaitlistOffer(c.Request.Context(), c.GetInt64("userID"), entryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, pnr)
+}
+
+// LeaveWaitlist handles removing a traveller from a waitlist.
+func (h *Handler) LeaveWaitlist(c *gin.Context) {
+	entryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	entry, err := h.Service.LeaveWaitlist(c.Request.Context(), c.GetInt64("userID"), entryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ChangeSeat handles moving a ticket to another seat in its cabin.
+func (h *Handler) ChangeSeat(c *gin.Context) {
+	ticketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req ChangeSeatRequest
+	// The body is optional: no body moves the ticket to the first free seat of its cabin
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+	}
+
+	ticket, err := h.Service.ChangeSeat(c.Request.Context(), c.GetInt64("userID"), ticketID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+// Rebook handles moving a ticket off a cancelled or retimed flight onto another flight.
+func (h *Handler) Rebook(c *gin.Context) {
+	ticketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req RebookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	ticket, err := h.Service.RebookTicket(c.Request.Context(), c.GetInt64("userID"), ticketID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+// UpdateFlight handles retiming a flight and reports the impact on its bookings (ADMIN).
+func (h *Handler) UpdateFlight(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req flight.UpdateFlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	impact, err := h.Service.UpdateFlight(c.Request.Context(), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, impact)
+}
+
+// CancelFlight handles cancelling a flight and reports the impact on its bookings (ADMIN).
+func (h *Handler) CancelFlight(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req flight.CancelFlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	impact, err := h.Service.CancelFlight(c.Request.Context(), id, c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, impact)
+}
@@ -1,25 +1,242 @@
 package booking
 
 import (
+	"airport-system/internal/airportops"
 	"airport-system/internal/flight"
+	"airport-system/internal/passenger"
+	"airport-system/internal/payment"
 	"time"
 )
 
+// PNR statuses. A PNR is cancelled once none of its tickets is active.
+const (
+	PNRStatusActive    = "ACTIVE"
+	PNRStatusCancelled = "CANCELLED"
+)
+
+// MaxPNRTravellers caps the number of travellers on one PNR.
+const MaxPNRTravellers = 9
+
 // Ticket represents a booked flight ticket.
 type Ticket struct {
-	ID          int64          `json:"id"`
-	FlightID    int64          `json:"flight_id"`
-	Flight      *flight.Flight `json:"flight,omitempty"` // For joining flight details
-	PassengerID int64          `json:"passenger_id"`
-	SeatNo      *string        `json:"seat_no"`
-	Price       float64        `json:"price"`
-	Status      string         `json:"status"` // ACTIVE, CANCELLED
-	CreatedAt   time.Time      `json:"created_at"`
+	ID              int64          `json:"id"`
+	BookingID       *int64         `json:"booking_id"`               // PNR the ticket was issued under; nil for legacy tickets
+	RecordLocator   *string        `json:"record_locator,omitempty"` // For joining PNR details
+	FlightID        int64          `json:"flight_id"`
+	Flight          *flight.Flight `json:"flight,omitempty"` // For joining flight details
+	PassengerID     int64          `json:"passenger_id"`
+	PassengerName   string         `json:"passenger_name,omitempty"`
+	UserID          int64          `json:"-"` // Account holding the traveller; decides ownership
+	SeatNo          *string        `json:"seat_no"`
+	ReseatRequired  bool           `json:"reseat_required"` // The seat was lost in an equipment change; pick a new one
+	RebookRequired  bool           `json:"rebook_required"` // The flight was cancelled or retimed; rebook or cancel for a full refund
+	Price           float64        `json:"price"`
+	FareClass       string         `json:"fare_class"`        // Cabin: Y, C or F; decides the baggage allowance and fare rule
+	BookingClass    string         `json:"booking_class"`     // Fare bucket the ticket was sold from, e.g. Y, B, M or Q
+	BaggageFees     float64        `json:"baggage_fees"`      // Excess baggage fees charged at check-in
+	Status          string         `json:"status"`            // PENDING_PAYMENT, ACTIVE, CANCELLED
+	PaymentIntentID *int64         `json:"payment_intent_id"` // Intent that pays for the ticket; nil for legacy tickets
+	FareRuleID      *int64         `json:"fare_rule_id"`      // Rule the ticket was sold under
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+// Refund reasons.
+const (
+	RefundReasonCustomer        = "customer_cancellation"
+	RefundReasonFlightCancelled = "flight_cancelled"
+	RefundReasonScheduleChange  = "schedule_change"
+)
+
+// FareRule decides what a ticket costs to change or cancel.
+type FareRule struct {
+	ID              int64     `json:"id"`
+	FareClass       string    `json:"fare_class"`
+	Refundable      bool      `json:"refundable"`
+	CancellationFee float64   `json:"cancellation_fee"` // Withheld when a refundable ticket is cancelled
+	ChangeFee       float64   `json:"change_fee"`
+	CutoffHours     int       `json:"cutoff_hours"`    // Cancelling later than this before departure counts as a no-show
+	NoShowPenalty   float64   `json:"no_show_penalty"` // Withheld instead of the cancellation fee after the cutoff
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// RefundQuote is what cancelling a ticket now would return.
+type RefundQuote struct {
+	TicketID      int64     `json:"ticket_id"`
+	FareRule      *FareRule `json:"fare_rule"`
+	Paid          float64   `json:"paid"`
+	Penalty       float64   `json:"penalty"`
+	Refund        float64   `json:"refund"`
+	Currency      string    `json:"currency"`
+	DepartureTime time.Time `json:"departure_time"`
+	CutoffAt      time.Time `json:"cutoff_at"`
+	NoShow        bool      `json:"no_show"`     // Cancelling after the cutoff
+	Involuntary   bool      `json:"involuntary"` // The flight was cancelled or significantly retimed, so the fare is refunded in full
+	Reason        string    `json:"reason"`      // customer_cancellation, flight_cancelled or schedule_change
+}
+
+// RebookRequest defines the body for moving a ticket to another flight on its route.
+type RebookRequest struct {
+	FlightID int64 `json:"flight_id" binding:"required"`
+}
+
+// Flight changes reported in a FlightImpact.
+const (
+	FlightChangeRetimed    = "RETIMED"    // The departure or arrival time moved
+	FlightChangeRenumbered = "RENUMBERED" // Only the flight number changed
+	FlightChangeUnchanged  = "UNCHANGED"
+	FlightChangeCancelled  = "CANCELLED"
+)
+
+// FlightImpact summarises what changing or cancelling a flight did to its bookings.
+type FlightImpact struct {
+	Flight                *flight.Flight       `json:"flight"`
+	Change                string               `json:"change"`                  // RETIMED, RENUMBERED, UNCHANGED or CANCELLED
+	DepartureShiftMinutes int                  `json:"departure_shift_minutes"` // Negative when the flight leaves earlier
+	ArrivalShiftMinutes   int                  `json:"arrival_shift_minutes"`
+	RebookRequired        bool                 `json:"rebook_required"` // Active tickets were flagged for rebooking or a full refund
+	GateReleased          bool                 `json:"gate_released"`
+	PaymentsVoided        int                  `json:"payments_voided"` // Payment intents of unpaid tickets cancelled with the flight
+	WaitlistCancelled     int                  `json:"waitlist_cancelled"`
+	Notified              int                  `json:"notified"` // Notifications sent to ticket holders and waitlisted passengers
+	Passengers            []ImpactedPassenger  `json:"passengers"`
+	Baggage               []airportops.Baggage `json:"baggage"` // Checked in on the flight's active tickets
+}
+
+// ImpactedPassenger is a ticket on a changed or cancelled flight.
+type ImpactedPassenger struct {
+	TicketID       int64   `json:"ticket_id"`
+	RecordLocator  *string `json:"record_locator,omitempty"`
+	PassengerID    int64   `json:"passenger_id"`
+	PassengerName  string  `json:"passenger_name"`
+	SeatNo         *string `json:"seat_no"`
+	FareClass      string  `json:"fare_class"`
+	Status         string  `json:"status"` // Ticket status after the change; unpaid tickets are released when the flight is cancelled
+	RebookRequired bool    `json:"rebook_required"`
+	Bags           int     `json:"bags"`
+}
+
+// CancellationResult is returned when a ticket is cancelled.
+type CancellationResult struct {
+	Ticket *Ticket         `json:"ticket"`
+	Refund *payment.Refund `json:"refund"`
+}
+
+// PNR (passenger name record) groups the tickets of several travellers booked together
+// under a six-character record locator.
+type PNR struct {
+	ID            int64            `json:"id"`
+	RecordLocator string           `json:"record_locator"`
+	UserID        int64            `json:"user_id"`
+	Status        string           `json:"status"` // ACTIVE, CANCELLED
+	Tickets       []Ticket         `json:"tickets"`
+	Payments      []payment.Intent `json:"payments"`
+	Refunds       []payment.Refund `json:"refunds"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
 }
 
 // BookingRequest defines the body for booking a ticket.
 type BookingRequest struct {
-	FlightID   int64  `json:"flight_id" binding:"required"`
-	PassportNo string `json:"passport_no"` // Optional: required only if profile doesn't exist
-	Phone      string `json:"phone"`       // Optional
+	FlightID     int64  `json:"flight_id" binding:"required"`
+	PassportNo   string `json:"passport_no"`   // Optional: required only if profile doesn't exist
+	Phone        string `json:"phone"`         // Optional
+	SeatHoldID   *int64 `json:"seat_hold_id"`  // Optional: confirms a seat held via /flights/:id/seats/holds
+	SeatNo       string `json:"seat_no"`       // Optional: requested seat; first free seat is assigned otherwise
+	QuoteID      *int64 `json:"quote_id"`      // Optional: locked price quote from /flights/:id/price-quotes; the current price applies otherwise
+	BookingClass string `json:"booking_class"` // Optional: desired booking class; the quote's class or the cheapest economy class with seats left otherwise
+}
+
+// ChangeSeatRequest defines the body for moving a ticket to another seat in its cabin.
+type ChangeSeatRequest struct {
+	SeatHoldID *int64 `json:"seat_hold_id"` // Optional: confirms a seat held via /flights/:id/seats/holds
+	SeatNo     string `json:"seat_no"`      // Optional: requested seat; first free seat is assigned otherwise
+}
+
+// TravellerRequest names one traveller of a PNR and their optional seat choice.
+type TravellerRequest struct {
+	PassengerID int64  `json:"passenger_id" binding:"required"` // The caller's own profile or one of their companions
+	SeatHoldID  *int64 `json:"seat_hold_id"`                    // Optional: confirms a seat held via /flights/:id/seats/holds
+	SeatNo      string `json:"seat_no"`                         // Optional: requested seat; first free seat is assigned otherwise
+}
+
+// PNRTicketsRequest defines the body for creating a PNR or adding travellers to one.
+type PNRTicketsRequest struct {
+	FlightID     int64              `json:"flight_id" binding:"required"`
+	Travellers   []TravellerRequest `json:"travellers" binding:"required,min=1,max=9,dive"`
+	QuoteID      *int64             `json:"quote_id"`      // Optional: locked price quote honoured for every traveller; the current price applies otherwise
+	BookingClass string             `json:"booking_class"` // Optional: desired booking class for every traveller, as for BookingRequest
+}
+
+// issueRequest issues the request's tickets to the resolved travellers.
+func (r PNRTicketsRequest) issueRequest(travellers []passenger.Passenger) issueRequest {
+	return issueRequest{
+		flightID:     r.FlightID,
+		bookingClass: r.BookingClass,
+		travellers:   travellers,
+		seats:        r.Travellers,
+		quoteID:      r.QuoteID,
+	}
+}
+
+// ItineraryLegRequest names one flight of an itinerary and how to price it.
+type ItineraryLegRequest struct {
+	FlightID     int64  `json:"flight_id" binding:"required"`
+	QuoteID      *int64 `json:"quote_id"`      // Optional: locked price quote for this flight, honoured for every traveller
+	BookingClass string `json:"booking_class"` // Optional: desired booking class on this flight, as for BookingRequest
+}
+
+// ItineraryBookingRequest defines the body for booking a connecting itinerary. Legs are in
+// travel order, as returned by /itineraries.
+type ItineraryBookingRequest struct {
+	Legs         []ItineraryLegRequest `json:"legs" binding:"required,min=1,max=3,dive"`
+	PassengerIDs []int64               `json:"passenger_ids" binding:"required,min=1,max=9"` // The caller's own profile or their companions
+}
+
+// CancelPNRRequest defines the body for cancelling a PNR.
+type CancelPNRRequest struct {
+	TicketIDs []int64 `json:"ticket_ids"` // Optional: tickets to cancel; the whole PNR is cancelled if empty
+}
+
+// Waitlist entry statuses. A WAITING entry is offered a freed seat (OFFERED) and either
+// confirms it before the offer expires (CONFIRMED) or loses it (EXPIRED). Passengers may
+// leave the waitlist at any time before confirming (CANCELLED).
+const (
+	WaitlistWaiting   = "WAITING"
+	WaitlistOffered   = "OFFERED"
+	WaitlistConfirmed = "CONFIRMED"
+	WaitlistExpired   = "EXPIRED"
+	WaitlistCancelled = "CANCELLED"
+)
+
+// WaitlistOfferTTL is how long a promoted passenger has to confirm the offered seat.
+// Offers never outlast the flight's departure.
+const WaitlistOfferTTL = 2 * time.Hour
+
+// waitlistPriority ranks waitlisted passengers by cabin, then by the rank of their booking
+// class within it; equal priorities are served first come, first served.
+var waitlistPriority = map[string]int{"F": 30, "C": 20, "Y": 10}
+
+// WaitlistEntry is a passenger queued for a sold-out flight.
+type WaitlistEntry struct {
+	ID                  int64      `json:"id"`
+	FlightID            int64      `json:"flight_id"`
+	UserID              int64      `json:"-"`
+	PassengerID         int64      `json:"passenger_id"`
+	FareClass           string     `json:"fare_class"`    // Cabin the passenger waits for
+	BookingClass        string     `json:"booking_class"` // Class the passenger asked for
+	Priority            int        `json:"priority"`
+	Status              string     `json:"status"`                // WAITING, OFFERED, CONFIRMED, EXPIRED, CANCELLED
+	Position            *int       `json:"position,omitempty"`    // 1-based place in the queue while WAITING
+	OfferedSeatNo       *string    `json:"offered_seat_no"`       // Seat held for the passenger while OFFERED
+	OfferedBookingClass *string    `json:"offered_booking_class"` // Class of the freed seat, which the ticket is issued in
+	OfferExpiresAt      *time.Time `json:"offer_expires_at"`      // Deadline to confirm the offer
+	TicketID            *int64     `json:"ticket_id"`             // Ticket issued on confirmation
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// JoinWaitlistRequest defines the body for joining a flight's waitlist.
+type JoinWaitlistRequest struct {
+	PassengerID  int64  `json:"passenger_id"`                            // Optional: the caller's own profile or one of their companions; defaults to their own profile
+	BookingClass string `json:"booking_class" binding:"omitempty,len=1"` // Optional: desired class, deciding the cabin; the cheapest economy class by default
 }
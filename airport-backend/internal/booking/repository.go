@@ -2,12 +2,46 @@ package booking
 
 import (
 	"airport-system/internal/flight"
+	"airport-system/platform/apperror"
 	"airport-system/platform/database"
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math/big"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrSeatTaken is returned when another active ticket already has the requested seat.
+	ErrSeatTaken = apperror.Conflict("seat_taken", "seat is already taken")
+	// ErrFlightFull is returned when a flight has no capacity left.
+	ErrFlightFull = apperror.Conflict("flight_full", "flight is full; you can join its waitlist")
+	// ErrAlreadyWaitlisted is returned when a passenger joins a waitlist they are already on.
+	ErrAlreadyWaitlisted = apperror.Conflict("already_waitlisted", "passenger is already on this flight's waitlist")
 )
 
+// recordLocatorAlphabet omits characters easily confused when read aloud or handwritten (0/O, 1/I).
+const recordLocatorAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const recordLocatorAttempts = 5
+
+// newRecordLocator returns a random six-character record locator.
+func newRecordLocator() (string, error) {
+	b := make([]byte, 6)
+	max := big.NewInt(int64(len(recordLocatorAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate record locator: %w", err)
+		}
+		b[i] = recordLocatorAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
 // Repository handles database interactions for bookings.
 type Repository struct {
 	DB *sql.DB
@@ -26,62 +60,143 @@ func (r *Repository) Create(ctx context.Context, ticket *Ticket) (int64, error)
 	}
 
 	query := `
-		INSERT INTO tickets (flight_id, passenger_id, seat_no, price, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, NOW())
+		INSERT INTO tickets (booking_id, flight_id, passenger_id, seat_no, price, fare_class, booking_class, fare_rule_id, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
 		RETURNING id
 	`
 	var id int64
 	err := executor.QueryRowContext(ctx, query,
+		ticket.BookingID,
 		ticket.FlightID,
 		ticket.PassengerID,
 		ticket.SeatNo,
 		ticket.Price,
+		ticket.FareClass,
+		ticket.BookingClass,
+		ticket.FareRuleID,
 		ticket.Status,
 	).Scan(&id)
 
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "uq_tickets_flight_seat" {
+			return 0, ErrSeatTaken
+		}
 		return 0, fmt.Errorf("failed to create ticket: %w", err)
 	}
 	return id, nil
 }
 
-// GetActiveTicketsCount counts active tickets for a flight.
-func (r *Repository) GetActiveTicketsCount(ctx context.Context, flightID int64) (int, error) {
+// UpdateSeat moves a ticket to another seat and clears its reseat flag. Returns ErrSeatTaken
+// if another ticket on the flight already has the seat.
+func (r *Repository) UpdateSeat(ctx context.Context, ticketID int64, seatNo string) error {
 	var executor database.Executor = r.DB
 	if tx := database.GetTx(ctx); tx != nil {
 		executor = tx
 	}
 
-	query := `SELECT COUNT(*) FROM tickets WHERE flight_id = $1 AND status = 'ACTIVE'`
-	var count int
-	if err := executor.QueryRowContext(ctx, query, flightID).Scan(&count); err != nil {
-		return 0, fmt.Errorf("failed to count active tickets: %w", err)
+	query := `
+		UPDATE tickets t
+		SET seat_no = $2, reseat_required = FALSE
+		WHERE t.id = $1 AND NOT EXISTS (
+			SELECT 1 FROM tickets o
+			WHERE o.flight_id = t.flight_id AND o.seat_no = $2 AND o.id <> t.id AND o.status IN ('ACTIVE', 'PENDING_PAYMENT')
+		)
+	`
+	res, err := executor.ExecContext(ctx, query, ticketID, seatNo)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "uq_tickets_flight_seat" {
+			return ErrSeatTaken
+		}
+		return fmt.Errorf("failed to change seat: %w", err)
 	}
-	return count, nil
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSeatTaken
+	}
+	return nil
 }
 
-// GetByPassengerID retrieves all tickets for a specific passenger.
-func (r *Repository) GetByPassengerID(ctx context.Context, passengerID int64) ([]Ticket, error) {
+// ReserveSeat increments the sold counter of a flight if capacity remains.
+// The conditional UPDATE locks the inventory row until the transaction ends, so concurrent
+// bookings for the same flight are serialized here. Returns ErrFlightFull when sold out.
+func (r *Repository) ReserveSeat(ctx context.Context, flightID int64) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
 	query := `
-        SELECT t.id, t.flight_id, t.passenger_id, t.seat_no, t.price, t.status, t.created_at,
-               f.id, f.flight_no, f.origin, f.destination, f.departure_time, f.arrival_time, f.status
-        FROM tickets t
-        JOIN flights f ON t.flight_id = f.id
-        WHERE t.passenger_id = $1
-    `
-	rows, err := r.DB.QueryContext(ctx, query, passengerID)
+		UPDATE flight_inventory
+		SET sold = sold + 1, updated_at = NOW()
+		WHERE flight_id = $1 AND sold < capacity
+		RETURNING sold
+	`
+	var sold int
+	err := executor.QueryRowContext(ctx, query, flightID).Scan(&sold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrFlightFull
+		}
+		return fmt.Errorf("failed to reserve seat: %w", err)
+	}
+	return nil
+}
+
+// ReleaseSeat decrements the sold counter of a flight.
+func (r *Repository) ReleaseSeat(ctx context.Context, flightID int64) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `UPDATE flight_inventory SET sold = sold - 1, updated_at = NOW() WHERE flight_id = $1 AND sold > 0`
+	if _, err := executor.ExecContext(ctx, query, flightID); err != nil {
+		return fmt.Errorf("failed to release seat: %w", err)
+	}
+	return nil
+}
+
+// ticketColumns and ticketJoins select tickets with their traveller and PNR; scanTicket reads them.
+const ticketColumns = `
+	t.id, t.booking_id, b.record_locator, t.flight_id, t.passenger_id, COALESCE(p.full_name, u.full_name), p.user_id,
+	t.seat_no, t.reseat_required, t.rebook_required, t.price, t.fare_class, t.booking_class, t.baggage_fees, t.status, t.payment_intent_id, t.fare_rule_id, t.created_at`
+
+const ticketJoins = `
+	FROM tickets t
+	JOIN passengers p ON p.id = t.passenger_id
+	JOIN users u ON u.id = p.user_id
+	LEFT JOIN bookings b ON b.id = t.booking_id`
+
+func scanTicket(row interface{ Scan(...any) error }, t *Ticket, extra ...any) error {
+	dest := []any{
+		&t.ID, &t.BookingID, &t.RecordLocator, &t.FlightID, &t.PassengerID, &t.PassengerName, &t.UserID,
+		&t.SeatNo, &t.ReseatRequired, &t.RebookRequired, &t.Price, &t.FareClass, &t.BookingClass, &t.BaggageFees, &t.Status, &t.PaymentIntentID, &t.FareRuleID, &t.CreatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// listTicketsWithFlights runs a ticket query extended with the flight columns.
+func (r *Repository) listTicketsWithFlights(ctx context.Context, where string, args ...any) ([]Ticket, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `SELECT ` + ticketColumns + `, f.id, f.flight_no, f.origin, f.destination, f.departure_time, f.arrival_time, f.status` +
+		ticketJoins + ` JOIN flights f ON t.flight_id = f.id ` + where
+	rows, err := executor.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bookings: %w", err)
 	}
 	defer rows.Close()
 
-	var tickets []Ticket
+	tickets := []Ticket{}
 	for rows.Next() {
 		var t Ticket
 		t.Flight = &flight.Flight{}
 		// Scan ticket and embedded flight details
-		if err := rows.Scan(
-			&t.ID, &t.FlightID, &t.PassengerID, &t.SeatNo, &t.Price, &t.Status, &t.CreatedAt,
+		if err := scanTicket(rows, &t,
 			&t.Flight.ID, &t.Flight.FlightNo, &t.Flight.Origin, &t.Flight.Destination,
 			&t.Flight.DepartureTime, &t.Flight.ArrivalTime, &t.Flight.Status,
 		); err != nil {
@@ -92,13 +207,30 @@ func (r *Repository) GetByPassengerID(ctx context.Context, passengerID int64) ([
 	return tickets, nil
 }
 
+// GetByUserID retrieves the tickets of all travellers held by a user account.
+func (r *Repository) GetByUserID(ctx context.Context, userID int64) ([]Ticket, error) {
+	return r.listTicketsWithFlights(ctx, `WHERE p.user_id = $1 ORDER BY f.departure_time, t.id`, userID)
+}
+
+// ListPNRTickets retrieves the tickets issued under a PNR.
+func (r *Repository) ListPNRTickets(ctx context.Context, bookingID int64) ([]Ticket, error) {
+	return r.listTicketsWithFlights(ctx, `WHERE t.booking_id = $1 ORDER BY t.id`, bookingID)
+}
+
+// ListFlightTickets retrieves the active and unpaid tickets on a flight.
+func (r *Repository) ListFlightTickets(ctx context.Context, flightID int64) ([]Ticket, error) {
+	return r.listTicketsWithFlights(ctx, `WHERE t.flight_id = $1 AND t.status IN ('ACTIVE', 'PENDING_PAYMENT') ORDER BY t.id`, flightID)
+}
+
 // GetByID retrieves a ticket by ID.
 func (r *Repository) GetByID(ctx context.Context, id int64) (*Ticket, error) {
-	query := `SELECT id, flight_id, passenger_id, seat_no, price, status, created_at FROM tickets WHERE id = $1`
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
 	var t Ticket
-	err := r.DB.QueryRowContext(ctx, query, id).Scan(
-		&t.ID, &t.FlightID, &t.PassengerID, &t.SeatNo, &t.Price, &t.Status, &t.CreatedAt,
-	)
+	err := scanTicket(executor.QueryRowContext(ctx, `SELECT `+ticketColumns+ticketJoins+` WHERE t.id = $1`, id), &t)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -108,9 +240,528 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*Ticket, error) {
 	return &t, nil
 }
 
+// CreatePNR inserts a new PNR under a freshly generated record locator.
+// Locator collisions are retried a few times before giving up.
+func (r *Repository) CreatePNR(ctx context.Context, pnr *PNR) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `
+		INSERT INTO bookings (record_locator, user_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (record_locator) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+	for attempt := 0; attempt < recordLocatorAttempts; attempt++ {
+		locator, err := newRecordLocator()
+		if err != nil {
+			return err
+		}
+		err = executor.QueryRowContext(ctx, query, locator, pnr.UserID, pnr.Status).
+			Scan(&pnr.ID, &pnr.CreatedAt, &pnr.UpdatedAt)
+		if err == sql.ErrNoRows {
+			continue // Locator already in use
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create pnr: %w", err)
+		}
+		pnr.RecordLocator = locator
+		return nil
+	}
+	return fmt.Errorf("failed to create pnr: no free record locator after %d attempts", recordLocatorAttempts)
+}
+
+const pnrColumns = `id, record_locator, user_id, status, created_at, updated_at`
+
+// GetPNR retrieves a PNR by record locator, without its tickets.
+func (r *Repository) GetPNR(ctx context.Context, locator string) (*PNR, error) {
+	return r.getPNR(ctx, `SELECT `+pnrColumns+` FROM bookings WHERE record_locator = $1`, locator)
+}
+
+// GetPNRForUpdate retrieves a PNR by record locator and locks it, serializing changes to it.
+func (r *Repository) GetPNRForUpdate(ctx context.Context, locator string) (*PNR, error) {
+	return r.getPNR(ctx, `SELECT `+pnrColumns+` FROM bookings WHERE record_locator = $1 FOR UPDATE`, locator)
+}
+
+func (r *Repository) getPNR(ctx context.Context, query string, args ...any) (*PNR, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var p PNR
+	err := executor.QueryRowContext(ctx, query, args...).Scan(
+		&p.ID, &p.RecordLocator, &p.UserID, &p.Status, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pnr: %w", err)
+	}
+	return &p, nil
+}
+
+// ListPNRsByUser retrieves a user's PNRs, newest first, without their tickets.
+func (r *Repository) ListPNRsByUser(ctx context.Context, userID int64) ([]PNR, error) {
+	query := `SELECT ` + pnrColumns + ` FROM bookings WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pnrs: %w", err)
+	}
+	defer rows.Close()
+
+	pnrs := []PNR{}
+	for rows.Next() {
+		var p PNR
+		if err := rows.Scan(&p.ID, &p.RecordLocator, &p.UserID, &p.Status, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pnr: %w", err)
+		}
+		pnrs = append(pnrs, p)
+	}
+	return pnrs, nil
+}
+
+// LockPNR locks a PNR by ID until the transaction ends.
+func (r *Repository) LockPNR(ctx context.Context, bookingID int64) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var id int64
+	if err := executor.QueryRowContext(ctx, `SELECT id FROM bookings WHERE id = $1 FOR UPDATE`, bookingID).Scan(&id); err != nil {
+		return fmt.Errorf("failed to lock pnr: %w", err)
+	}
+	return nil
+}
+
+// ClosePNRIfEmpty marks a PNR as cancelled once none of its tickets is active or awaiting payment.
+// Callers hold the PNR's lock so tickets cannot be added concurrently.
+func (r *Repository) ClosePNRIfEmpty(ctx context.Context, bookingID int64) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `
+		UPDATE bookings SET status = 'CANCELLED', updated_at = NOW()
+		WHERE id = $1 AND status = 'ACTIVE'
+		  AND NOT EXISTS (SELECT 1 FROM tickets WHERE booking_id = $1 AND status IN ('ACTIVE', 'PENDING_PAYMENT'))
+	`
+	if _, err := executor.ExecContext(ctx, query, bookingID); err != nil {
+		return fmt.Errorf("failed to close pnr: %w", err)
+	}
+	return nil
+}
+
+// TouchPNR bumps a PNR's updated_at after its tickets change.
+func (r *Repository) TouchPNR(ctx context.Context, bookingID int64) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if _, err := executor.ExecContext(ctx, `UPDATE bookings SET updated_at = NOW() WHERE id = $1`, bookingID); err != nil {
+		return fmt.Errorf("failed to update pnr: %w", err)
+	}
+	return nil
+}
+
 // Cancel updates ticket status to CANCELLED.
-func (r *Repository) Cancel(ctx context.Context, id int64) error {
-	query := `UPDATE tickets SET status = 'CANCELLED' WHERE id = $1`
-	_, err := r.DB.ExecContext(ctx, query, id)
-	return err
+// Returns false if the ticket was not ACTIVE (e.g. already cancelled by a concurrent request).
+func (r *Repository) Cancel(ctx context.Context, id int64) (bool, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `UPDATE tickets SET status = 'CANCELLED' WHERE id = $1 AND status = 'ACTIVE'`
+	res, err := executor.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel ticket: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel ticket: %w", err)
+	}
+	return n > 0, nil
+}
+
+// FlagRebook marks the active tickets on a flight for rebooking and returns how many it marked.
+func (r *Repository) FlagRebook(ctx context.Context, flightID int64) (int64, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `UPDATE tickets SET rebook_required = TRUE WHERE flight_id = $1 AND status = 'ACTIVE'`
+	res, err := executor.ExecContext(ctx, query, flightID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to flag tickets for rebooking: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to flag tickets for rebooking: %w", err)
+	}
+	return n, nil
+}
+
+// MoveTicket moves an active ticket to a seat and booking class on another flight and clears
+// its rebook and reseat flags. Returns ErrSeatTaken if another ticket on the flight already has
+// the seat.
+func (r *Repository) MoveTicket(ctx context.Context, ticketID, flightID int64, seatNo, bookingClass string) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `
+		UPDATE tickets
+		SET flight_id = $2, seat_no = $3, booking_class = $4, rebook_required = FALSE, reseat_required = FALSE
+		WHERE id = $1 AND status = 'ACTIVE'
+	`
+	res, err := executor.ExecContext(ctx, query, ticketID, flightID, seatNo, bookingClass)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "uq_tickets_flight_seat" {
+			return ErrSeatTaken
+		}
+		return fmt.Errorf("failed to move ticket: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: ticket %d", ErrTicketCancelled, ticketID)
+	}
+	return nil
+}
+
+// SetPaymentIntent links tickets to the payment intent that pays for them.
+func (r *Repository) SetPaymentIntent(ctx context.Context, intentID int64, ticketIDs []int64) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `UPDATE tickets SET payment_intent_id = $1 WHERE id = ANY($2)`
+	if _, err := executor.ExecContext(ctx, query, intentID, pq.Array(ticketIDs)); err != nil {
+		return fmt.Errorf("failed to link payment intent: %w", err)
+	}
+	return nil
+}
+
+// ActivatePaidTickets moves the tickets of a paid intent from PENDING_PAYMENT to ACTIVE.
+func (r *Repository) ActivatePaidTickets(ctx context.Context, intentID int64) (int64, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `UPDATE tickets SET status = 'ACTIVE' WHERE payment_intent_id = $1 AND status = 'PENDING_PAYMENT'`
+	res, err := executor.ExecContext(ctx, query, intentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to activate tickets: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to activate tickets: %w", err)
+	}
+	return n, nil
+}
+
+// CancelUnpaidTickets cancels the tickets of an intent that are still awaiting payment and
+// returns the flight, seat and class of each cancelled ticket.
+func (r *Repository) CancelUnpaidTickets(ctx context.Context, intentID int64) ([]Ticket, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `
+		UPDATE tickets SET status = 'CANCELLED'
+		WHERE payment_intent_id = $1 AND status = 'PENDING_PAYMENT'
+		RETURNING id, flight_id, seat_no, fare_class, booking_class
+	`
+	rows, err := executor.QueryContext(ctx, query, intentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel unpaid tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []Ticket
+	for rows.Next() {
+		var t Ticket
+		if err := rows.Scan(&t.ID, &t.FlightID, &t.SeatNo, &t.FareClass, &t.BookingClass); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		t.Status = "CANCELLED"
+		tickets = append(tickets, t)
+	}
+	return tickets, rows.Err()
+}
+
+const fareRuleColumns = `id, fare_class, refundable, cancellation_fee, change_fee, cutoff_hours, no_show_penalty, created_at`
+
+// GetFareRule retrieves a fare rule by ID.
+func (r *Repository) GetFareRule(ctx context.Context, id int64) (*FareRule, error) {
+	return r.getFareRule(ctx, `SELECT `+fareRuleColumns+` FROM fare_rules WHERE id = $1`, id)
+}
+
+// GetCurrentFareRule retrieves the newest fare rule of a fare class, which new tickets are sold under.
+func (r *Repository) GetCurrentFareRule(ctx context.Context, fareClass string) (*FareRule, error) {
+	return r.getFareRule(ctx, `SELECT `+fareRuleColumns+` FROM fare_rules WHERE fare_class = $1 ORDER BY id DESC LIMIT 1`, fareClass)
+}
+
+func (r *Repository) getFareRule(ctx context.Context, query string, args ...any) (*FareRule, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var fr FareRule
+	err := executor.QueryRowContext(ctx, query, args...).Scan(
+		&fr.ID, &fr.FareClass, &fr.Refundable, &fr.CancellationFee, &fr.ChangeFee, &fr.CutoffHours, &fr.NoShowPenalty, &fr.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get fare rule: %w", err)
+	}
+	return &fr, nil
+}
+
+// IsSoldOut reports whether every seat of a flight is sold or held for a waitlist offer.
+func (r *Repository) IsSoldOut(ctx context.Context, flightID int64) (bool, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var soldOut bool
+	query := `SELECT sold >= capacity FROM flight_inventory WHERE flight_id = $1`
+	err := executor.QueryRowContext(ctx, query, flightID).Scan(&soldOut)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, ErrFlightNotFound
+		}
+		return false, fmt.Errorf("failed to check inventory: %w", err)
+	}
+	return soldOut, nil
+}
+
+// HasTicketOnFlight reports whether the passenger holds an active or unpaid ticket on the flight.
+func (r *Repository) HasTicketOnFlight(ctx context.Context, passengerID, flightID int64) (bool, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM tickets
+			WHERE passenger_id = $1 AND flight_id = $2 AND status IN ('ACTIVE', 'PENDING_PAYMENT')
+		)
+	`
+	if err := executor.QueryRowContext(ctx, query, passengerID, flightID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check tickets: %w", err)
+	}
+	return exists, nil
+}
+
+// waitlistColumns selects a waitlist entry with its queue position; scanWaitlistEntry reads them.
+// The position counts the WAITING entries served before this one on the same flight.
+const waitlistColumns = `
+	w.id, w.flight_id, w.user_id, w.passenger_id, w.fare_class, w.booking_class, w.priority, w.status,
+	CASE WHEN w.status = 'WAITING' THEN (
+		SELECT COUNT(*) + 1 FROM waitlist_entries o
+		WHERE o.flight_id = w.flight_id AND o.status = 'WAITING'
+		  AND (o.priority > w.priority OR (o.priority = w.priority AND o.id < w.id))
+	) END,
+	w.offered_seat_no, w.offered_booking_class, w.offer_expires_at, w.ticket_id, w.created_at, w.updated_at`
+
+func scanWaitlistEntry(row interface{ Scan(...any) error }, e *WaitlistEntry) error {
+	var position sql.NullInt64
+	err := row.Scan(
+		&e.ID, &e.FlightID, &e.UserID, &e.PassengerID, &e.FareClass, &e.BookingClass, &e.Priority, &e.Status,
+		&position, &e.OfferedSeatNo, &e.OfferedBookingClass, &e.OfferExpiresAt, &e.TicketID, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if position.Valid {
+		p := int(position.Int64)
+		e.Position = &p
+	}
+	return nil
+}
+
+// CreateWaitlistEntry queues a passenger for a flight.
+// Returns ErrAlreadyWaitlisted if the passenger is already waiting for or offered a seat on it.
+func (r *Repository) CreateWaitlistEntry(ctx context.Context, e *WaitlistEntry) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `
+		INSERT INTO waitlist_entries (flight_id, user_id, passenger_id, fare_class, booking_class, priority, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+	err := executor.QueryRowContext(ctx, query, e.FlightID, e.UserID, e.PassengerID, e.FareClass, e.BookingClass, e.Priority, e.Status).
+		Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrAlreadyWaitlisted
+		}
+		return fmt.Errorf("failed to create waitlist entry: %w", err)
+	}
+	return nil
+}
+
+// GetWaitlistEntry retrieves a waitlist entry by ID with its queue position.
+func (r *Repository) GetWaitlistEntry(ctx context.Context, id int64) (*WaitlistEntry, error) {
+	return r.getWaitlistEntry(ctx, `SELECT `+waitlistColumns+` FROM waitlist_entries w WHERE w.id = $1`, id)
+}
+
+// GetWaitlistEntryForUpdate retrieves a waitlist entry and locks it until the transaction ends.
+func (r *Repository) GetWaitlistEntryForUpdate(ctx context.Context, id int64) (*WaitlistEntry, error) {
+	return r.getWaitlistEntry(ctx, `SELECT `+waitlistColumns+` FROM waitlist_entries w WHERE w.id = $1 FOR UPDATE OF w`, id)
+}
+
+// NextWaitlisted locks and returns the first WAITING entry for a cabin of a flight whose
+// passenger holds no ticket on it yet, or nil if nobody is eligible. Entries locked by
+// concurrent promotions are skipped.
+func (r *Repository) NextWaitlisted(ctx context.Context, flightID int64, cabin string) (*WaitlistEntry, error) {
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries w
+		WHERE w.flight_id = $1 AND w.fare_class = $2 AND w.status = 'WAITING'
+		  AND NOT EXISTS (
+			SELECT 1 FROM tickets t
+			WHERE t.passenger_id = w.passenger_id AND t.flight_id = w.flight_id
+			  AND t.status IN ('ACTIVE', 'PENDING_PAYMENT')
+		  )
+		ORDER BY w.priority DESC, w.id
+		LIMIT 1
+		FOR UPDATE OF w SKIP LOCKED
+	`
+	return r.getWaitlistEntry(ctx, query, flightID, cabin)
+}
+
+func (r *Repository) getWaitlistEntry(ctx context.Context, query string, args ...any) (*WaitlistEntry, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	var e WaitlistEntry
+	if err := scanWaitlistEntry(executor.QueryRowContext(ctx, query, args...), &e); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	return &e, nil
+}
+
+// ListWaitlistByUser returns the waitlist entries of all the user's travellers, newest first.
+func (r *Repository) ListWaitlistByUser(ctx context.Context, userID int64) ([]WaitlistEntry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries w WHERE w.user_id = $1 ORDER BY w.created_at DESC, w.id DESC`
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list waitlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []WaitlistEntry{}
+	for rows.Next() {
+		var e WaitlistEntry
+		if err := scanWaitlistEntry(rows, &e); err != nil {
+			return nil, fmt.Errorf("failed to scan waitlist entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// LockFlightWaitlist locks and returns the entries still waiting for or offered a seat on a
+// flight, in the order they joined.
+func (r *Repository) LockFlightWaitlist(ctx context.Context, flightID int64) ([]WaitlistEntry, error) {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `
+		SELECT ` + waitlistColumns + `
+		FROM waitlist_entries w
+		WHERE w.flight_id = $1 AND w.status IN ('WAITING', 'OFFERED')
+		ORDER BY w.id
+		FOR UPDATE OF w
+	`
+	rows, err := executor.QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list waitlist entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []WaitlistEntry{}
+	for rows.Next() {
+		var e WaitlistEntry
+		if err := scanWaitlistEntry(rows, &e); err != nil {
+			return nil, fmt.Errorf("failed to scan waitlist entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListDueWaitlistOffers returns the IDs of offers whose confirmation deadline has passed, oldest first.
+func (r *Repository) ListDueWaitlistOffers(ctx context.Context, limit int) ([]int64, error) {
+	query := `
+		SELECT id FROM waitlist_entries
+		WHERE status = 'OFFERED' AND offer_expires_at <= NOW()
+		ORDER BY offer_expires_at
+		LIMIT $1
+	`
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due waitlist offers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan waitlist entry: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UpdateWaitlistEntry stores an entry's status, offer and ticket.
+func (r *Repository) UpdateWaitlistEntry(ctx context.Context, e *WaitlistEntry) error {
+	var executor database.Executor = r.DB
+	if tx := database.GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	query := `
+		UPDATE waitlist_entries
+		SET status = $2, offered_seat_no = $3, offered_booking_class = $4, offer_expires_at = $5, ticket_id = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	err := executor.QueryRowContext(ctx, query, e.ID, e.Status, e.OfferedSeatNo, e.OfferedBookingClass, e.OfferExpiresAt, e.TicketID).Scan(&e.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+	return nil
 }
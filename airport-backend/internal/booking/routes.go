@@ -12,6 +12,47 @@ func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFu
 		bookingGroup.POST("/", h.Book)
 		bookingGroup.GET("/my", h.GetMy)
 		bookingGroup.POST("/:id/cancel", h.Cancel)
+		bookingGroup.GET("/:id/refund-quote", h.RefundQuote)
+		bookingGroup.PUT("/:id/seat", h.ChangeSeat)
+		bookingGroup.POST("/:id/rebook", h.Rebook)
 		bookingGroup.GET("/baggage", h.GetMyBaggage)
+		bookingGroup.GET("/baggage/:id/track", h.TrackBaggage)
 	}
+
+	pnrGroup := r.Group("/pnrs")
+	pnrGroup.Use(authMiddleware)
+	{
+		pnrGroup.POST("", h.CreatePNR)
+		pnrGroup.POST("/itinerary", h.BookItinerary)
+		pnrGroup.GET("", h.ListPNRs)
+		pnrGroup.GET("/:locator", h.GetPNR)
+		pnrGroup.POST("/:locator/travellers", h.AddTravellers)
+		pnrGroup.POST("/:locator/cancel", h.CancelPNR)
+		pnrGroup.POST("/:locator/payments/:id/confirm", h.ConfirmPayment)
+	}
+
+	// Flight changes that reach into bookings live here, as the flight module cannot see them
+	flightChangeGroup := r.Group("/flights/:id")
+	flightChangeGroup.Use(authMiddleware)
+	{
+		flightChangeGroup.PUT("", h.UpdateFlight)
+		flightChangeGroup.POST("/cancel", h.CancelFlight)
+	}
+
+	flightWaitlistGroup := r.Group("/flights/:id/waitlist")
+	flightWaitlistGroup.Use(authMiddleware)
+	{
+		flightWaitlistGroup.POST("", h.JoinWaitlist)
+	}
+
+	waitlistGroup := r.Group("/waitlist")
+	waitlistGroup.Use(authMiddleware)
+	{
+		waitlistGroup.GET("", h.ListWaitlist)
+		waitlistGroup.POST("/:id/confirm", h.ConfirmWaitlistOffer)
+		waitlistGroup.DELETE("/:id", h.LeaveWaitlist)
+	}
+
+	// Called by the payment gateway; authenticated by the gateway's webhook signature
+	r.POST("/payments/webhook", h.PaymentWebhook)
 }
@@ -3,160 +3,1900 @@ package booking
 import (
 	"airport-system/internal/airportops"
 	"airport-system/internal/flight"
+	"airport-system/internal/notification"
 	"airport-system/internal/passenger"
+	"airport-system/internal/payment"
+	"airport-system/internal/pricing"
+	"airport-system/internal/seating"
+	"airport-system/platform/apperror"
 	"airport-system/platform/database"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 )
 
+var (
+	// ErrFlightNotFound is returned when the flight to book does not exist.
+	ErrFlightNotFound = apperror.NotFound("flight_not_found", "flight not found")
+	// ErrTicketNotFound is returned when the ticket does not exist.
+	ErrTicketNotFound = apperror.NotFound("ticket_not_found", "ticket not found")
+	// ErrTicketForbidden is returned when the ticket belongs to another passenger.
+	ErrTicketForbidden = apperror.Forbidden("ticket_forbidden", "ticket belongs to another passenger")
+	// ErrTicketCancelled is returned when cancelling a ticket that is no longer active.
+	ErrTicketCancelled = apperror.Conflict("ticket_cancelled", "ticket is already cancelled")
+	// ErrProfileRequired is returned when a first booking omits passport details.
+	ErrProfileRequired = apperror.Validation("passenger_profile_required", "passenger profile required: please provide passport_no and phone")
+	// ErrPNRNotFound is returned when the record locator does not exist or belongs to another user.
+	ErrPNRNotFound = apperror.NotFound("pnr_not_found", "booking not found")
+	// ErrPNRCancelled is returned when changing a PNR that has been cancelled.
+	ErrPNRCancelled = apperror.Conflict("pnr_cancelled", "booking is cancelled")
+	// ErrInvalidPNR is returned when a PNR request breaks a booking rule.
+	ErrInvalidPNR = apperror.Validation("invalid_pnr", "invalid booking request")
+	// ErrFlightDeparted is returned when cancelling a ticket whose flight has already left.
+	ErrFlightDeparted = apperror.Conflict("flight_departed", "flight has already departed")
+	// ErrTicketPendingPayment is returned when cancelling a single ticket that has not been paid yet.
+	ErrTicketPendingPayment = apperror.Conflict("ticket_pending_payment", "ticket is awaiting payment; cancel the whole booking or let the payment expire")
+	// ErrWaitlistEntryNotFound is returned when the waitlist entry does not exist or belongs to another user.
+	ErrWaitlistEntryNotFound = apperror.NotFound("waitlist_entry_not_found", "waitlist entry not found")
+	// ErrFlightNotFull is returned when joining the waitlist of a flight that still has seats to sell.
+	ErrFlightNotFull = apperror.Conflict("flight_not_full", "flight still has seats available; book it directly")
+	// ErrWaitlistClosed is returned when joining the waitlist of a flight that has departed or been cancelled.
+	ErrWaitlistClosed = apperror.Conflict("waitlist_closed", "flight is no longer open for waitlisting")
+	// ErrAlreadyBooked is returned when waitlisting a passenger who already holds a ticket on the flight.
+	ErrAlreadyBooked = apperror.Conflict("already_booked", "passenger already has a ticket on this flight")
+	// ErrWaitlistEntryClosed is returned when changing a waitlist entry that is no longer waiting or offered.
+	ErrWaitlistEntryClosed = apperror.Conflict("waitlist_entry_closed", "waitlist entry is no longer active")
+	// ErrNoWaitlistOffer is returned when confirming a waitlist entry that has not been offered a seat.
+	ErrNoWaitlistOffer = apperror.Conflict("no_waitlist_offer", "no seat has been offered for this waitlist entry yet")
+	// ErrWaitlistOfferExpired is returned when confirming an offer after its deadline.
+	ErrWaitlistOfferExpired = apperror.Conflict("waitlist_offer_expired", "waitlist offer has expired")
+	// ErrFlightCancelled is returned when booking a flight that has been cancelled.
+	ErrFlightCancelled = apperror.Conflict("flight_cancelled", "flight has been cancelled")
+	// ErrRebookNotOffered is returned when rebooking a ticket whose flight was neither cancelled nor significantly retimed.
+	ErrRebookNotOffered = apperror.Conflict("rebook_not_offered", "ticket can only be rebooked after its flight is cancelled or retimed")
+	// ErrInvalidRebooking is returned when the flight to rebook onto cannot take the ticket.
+	ErrInvalidRebooking = apperror.Validation("invalid_rebooking", "invalid rebooking")
+	// ErrFlightClosed is returned when selling or changing seats on a flight that has started boarding or left.
+	ErrFlightClosed = apperror.Conflict("flight_closed", "flight is no longer open for booking")
+	// ErrFlightBusy is returned when a flight's bookings keep changing while it is being cancelled.
+	ErrFlightBusy = apperror.Conflict("flight_busy", "bookings on the flight are changing, please retry")
+)
+
+// expiryBatchSize caps how many overdue payment intents or waitlist offers one expiry call releases.
+const expiryBatchSize = 100
+
 // Service handles booking business logic.
 type Service struct {
-	repo        *Repository
-	flightRepo  *flight.Repository
-	txManager   database.TxManager
-	opsService  *airportops.Service
-	passService *passenger.Service
-	log         *slog.Logger
+	repo          *Repository
+	flightRepo    *flight.Repository
+	flightService *flight.Service
+	txManager     database.TxManager
+	opsService    *airportops.Service
+	passService   *passenger.Service
+	seatService   *seating.Service
+	payService    *payment.Service
+	priceService  *pricing.Service
+	notifService  *notification.Service
+	log           *slog.Logger
+}
+
+// NewService creates a new booking service.
+func NewService(repo *Repository, flightRepo *flight.Repository, flightService *flight.Service, txManager database.TxManager, opsService *airportops.Service, passService *passenger.Service, seatService *seating.Service, payService *payment.Service, priceService *pricing.Service, notifService *notification.Service, log *slog.Logger) *Service {
+	return &Service{
+		repo:          repo,
+		flightRepo:    flightRepo,
+		flightService: flightService,
+		txManager:     txManager,
+		opsService:    opsService,
+		passService:   passService,
+		seatService:   seatService,
+		payService:    payService,
+		priceService:  priceService,
+		notifService:  notifService,
+		log:           log,
+	}
+}
+
+// BookTicket books a ticket for the user themself on a flight transactionally.
+// The ticket is issued under a new single-traveller PNR and awaits payment of its intent.
+func (s *Service) BookTicket(ctx context.Context, userID int64, req BookingRequest) (*Ticket, error) {
+	var ticket *Ticket
+
+	// 0. Resolve Passenger ID
+	// Check if passenger profile exists
+	passProfile, err := s.passService.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check passenger profile: %w", err)
+	}
+
+	if passProfile == nil {
+		// Profile does not exist, require passport info
+		if req.PassportNo == "" {
+			return nil, ErrProfileRequired
+		}
+		// Create new profile
+		passProfile, err = s.passService.CreateProfile(ctx, userID, req.PassportNo, req.Phone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		pnr := &PNR{UserID: userID, Status: PNRStatusActive}
+		if err := s.repo.CreatePNR(ctx, pnr); err != nil {
+			return err
+		}
+
+		tickets, err := s.issueTickets(ctx, pnr, issueRequest{
+			flightID:     req.FlightID,
+			bookingClass: req.BookingClass,
+			travellers:   []passenger.Passenger{*passProfile},
+			seats:        []TravellerRequest{{PassengerID: passProfile.ID, SeatHoldID: req.SeatHoldID, SeatNo: req.SeatNo}},
+			quoteID:      req.QuoteID,
+		})
+		if err != nil {
+			return err
+		}
+		ticket = &tickets[0]
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
+
+// CreatePNR books several of the user's travellers on a flight under one record locator.
+// Either every traveller gets a ticket or none does. The tickets await payment of one intent.
+func (s *Service) CreatePNR(ctx context.Context, userID int64, req PNRTicketsRequest) (*PNR, error) {
+	travellers, err := s.resolveTravellers(ctx, userID, req.Travellers)
+	if err != nil {
+		return nil, err
+	}
+
+	pnr := &PNR{UserID: userID, Status: PNRStatusActive}
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreatePNR(ctx, pnr); err != nil {
+			return err
+		}
+		_, err := s.issueTickets(ctx, pnr, req.issueRequest(travellers))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("PNR created", "record_locator", pnr.RecordLocator, "user_id", userID, "travellers", len(travellers))
+	return s.GetPNR(ctx, userID, pnr.RecordLocator)
+}
+
+// BookItinerary books the user's travellers on every leg of a connecting itinerary under one
+// record locator. Either every traveller gets a ticket on every leg or nobody gets any, and all
+// the tickets await payment of one intent, so an unpaid itinerary is released as a whole. Seats
+// are assigned on each leg and can be changed afterwards.
+func (s *Service) BookItinerary(ctx context.Context, userID int64, req ItineraryBookingRequest) (*PNR, error) {
+	seats := make([]TravellerRequest, len(req.PassengerIDs))
+	for i, id := range req.PassengerIDs {
+		seats[i] = TravellerRequest{PassengerID: id}
+	}
+	travellers, err := s.resolveTravellers(ctx, userID, seats)
+	if err != nil {
+		return nil, err
+	}
+
+	flightIDs := make([]int64, len(req.Legs))
+	for i, leg := range req.Legs {
+		if slices.Contains(flightIDs[:i], leg.FlightID) {
+			return nil, fmt.Errorf("%w: flight %d is listed twice", flight.ErrInvalidItinerary, leg.FlightID)
+		}
+		flightIDs[i] = leg.FlightID
+	}
+
+	pnr := &PNR{UserID: userID, Status: PNRStatusActive}
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		// Lock every leg's inventory in flight ID order so itineraries sharing legs cannot deadlock
+		for _, id := range slices.Sorted(slices.Values(flightIDs)) {
+			if _, _, err := s.flightRepo.LockInventory(ctx, id); err != nil {
+				if errors.Is(err, flight.ErrFlightNotFound) {
+					return ErrFlightNotFound
+				}
+				return err
+			}
+		}
+		legs := make([]*flight.Flight, len(flightIDs))
+		for i, id := range flightIDs {
+			f, err := s.flightRepo.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if f == nil {
+				return ErrFlightNotFound
+			}
+			legs[i] = f
+		}
+		if err := s.flightService.CheckItinerary(ctx, legs); err != nil {
+			return err
+		}
+
+		if err := s.repo.CreatePNR(ctx, pnr); err != nil {
+			return err
+		}
+		var tickets []Ticket
+		for _, leg := range req.Legs {
+			issued, err := s.createTickets(ctx, pnr, issueRequest{
+				flightID:     leg.FlightID,
+				bookingClass: leg.BookingClass,
+				travellers:   travellers,
+				seats:        seats,
+				quoteID:      leg.QuoteID,
+			})
+			if err != nil {
+				return err
+			}
+			tickets = append(tickets, issued...)
+		}
+		return s.openIntent(ctx, pnr, tickets)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Itinerary booked", "record_locator", pnr.RecordLocator, "user_id", userID, "legs", len(req.Legs), "travellers", len(travellers))
+	return s.GetPNR(ctx, userID, pnr.RecordLocator)
+}
+
+// AddTravellers issues tickets for more travellers under an existing PNR.
+func (s *Service) AddTravellers(ctx context.Context, userID int64, locator string, req PNRTicketsRequest) (*PNR, error) {
+	travellers, err := s.resolveTravellers(ctx, userID, req.Travellers)
+	if err != nil {
+		return nil, err
+	}
+
+	var pnr *PNR
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		var err error
+		pnr, err = s.lockOwnPNR(ctx, userID, locator)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.repo.ListPNRTickets(ctx, pnr.ID)
+		if err != nil {
+			return err
+		}
+		onPNR := make(map[int64]bool)
+		for _, t := range existing {
+			if t.Status == "CANCELLED" {
+				continue
+			}
+			onPNR[t.PassengerID] = true
+			if t.FlightID == req.FlightID {
+				for _, tr := range req.Travellers {
+					if tr.PassengerID == t.PassengerID {
+						return fmt.Errorf("%w: passenger %d already holds a ticket on this flight", ErrInvalidPNR, t.PassengerID)
+					}
+				}
+			}
+		}
+		for _, tr := range req.Travellers {
+			onPNR[tr.PassengerID] = true
+		}
+		if len(onPNR) > MaxPNRTravellers {
+			return fmt.Errorf("%w: a PNR holds at most %d travellers", ErrInvalidPNR, MaxPNRTravellers)
+		}
+
+		if _, err := s.issueTickets(ctx, pnr, req.issueRequest(travellers)); err != nil {
+			return err
+		}
+		return s.repo.TouchPNR(ctx, pnr.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Travellers added to PNR", "record_locator", pnr.RecordLocator, "user_id", userID, "travellers", len(travellers))
+	return s.GetPNR(ctx, userID, pnr.RecordLocator)
+}
+
+// CancelPNR cancels some or all of a PNR's active tickets in one transaction, returns their
+// seats to inventory and records a refund for each under its fare rule. Cancelling the whole
+// PNR also voids its unpaid payment intents. The PNR itself is cancelled once no active
+// ticket remains. Nothing is cancelled if any of the tickets' flights has departed.
+func (s *Service) CancelPNR(ctx context.Context, userID int64, locator string, req CancelPNRRequest) (*PNR, error) {
+	var pnr *PNR
+	var refunds []*payment.Refund
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		var err error
+		pnr, err = s.lockOwnPNR(ctx, userID, locator)
+		if err != nil {
+			return err
+		}
+
+		tickets, err := s.repo.ListPNRTickets(ctx, pnr.ID)
+		if err != nil {
+			return err
+		}
+		targets, err := ticketsToCancel(tickets, req.TicketIDs)
+		if err != nil {
+			return err
+		}
+
+		if len(req.TicketIDs) == 0 {
+			if err := s.cancelOpenPayments(ctx, pnr.ID); err != nil {
+				return err
+			}
+		}
+
+		refunds = refunds[:0]
+		for _, t := range targets {
+			refund, err := s.cancelWithRefund(ctx, &t)
+			if err != nil {
+				return err
+			}
+			refunds = append(refunds, refund)
+		}
+
+		if err := s.repo.ClosePNRIfEmpty(ctx, pnr.ID); err != nil {
+			return err
+		}
+		return s.repo.TouchPNR(ctx, pnr.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("PNR cancelled", "record_locator", pnr.RecordLocator, "user_id", userID, "partial", len(req.TicketIDs) > 0)
+	s.processRefunds(ctx, refunds)
+	return s.GetPNR(ctx, userID, pnr.RecordLocator)
+}
+
+// GetPNR returns one of the user's PNRs with its tickets.
+func (s *Service) GetPNR(ctx context.Context, userID int64, locator string) (*PNR, error) {
+	pnr, err := s.repo.GetPNR(ctx, normalizeLocator(locator))
+	if err != nil {
+		return nil, err
+	}
+	if pnr == nil || pnr.UserID != userID {
+		return nil, ErrPNRNotFound
+	}
+
+	pnr.Tickets, err = s.repo.ListPNRTickets(ctx, pnr.ID)
+	if err != nil {
+		return nil, err
+	}
+	pnr.Payments, err = s.payService.ListByBooking(ctx, pnr.ID)
+	if err != nil {
+		return nil, err
+	}
+	pnr.Refunds, err = s.payService.ListRefundsByBooking(ctx, pnr.ID)
+	if err != nil {
+		return nil, err
+	}
+	return pnr, nil
+}
+
+// ListMyPNRs returns the user's PNRs with their tickets, newest first.
+func (s *Service) ListMyPNRs(ctx context.Context, userID int64) ([]PNR, error) {
+	pnrs, err := s.repo.ListPNRsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pnrs {
+		pnrs[i].Tickets, err = s.repo.ListPNRTickets(ctx, pnrs[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		pnrs[i].Payments, err = s.payService.ListByBooking(ctx, pnrs[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		pnrs[i].Refunds, err = s.payService.ListRefundsByBooking(ctx, pnrs[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pnrs, nil
+}
+
+// resolveTravellers checks that a request names each traveller once and that all of them
+// belong to the user, returning their profiles in request order.
+func (s *Service) resolveTravellers(ctx context.Context, userID int64, reqs []TravellerRequest) ([]passenger.Passenger, error) {
+	ids := make([]int64, 0, len(reqs))
+	seen := make(map[int64]bool, len(reqs))
+	for _, tr := range reqs {
+		if seen[tr.PassengerID] {
+			return nil, fmt.Errorf("%w: passenger %d is listed twice", ErrInvalidPNR, tr.PassengerID)
+		}
+		seen[tr.PassengerID] = true
+		ids = append(ids, tr.PassengerID)
+	}
+	return s.passService.ResolveTravellers(ctx, userID, ids)
+}
+
+// lockOwnPNR locks one of the user's active PNRs for the rest of the transaction.
+func (s *Service) lockOwnPNR(ctx context.Context, userID int64, locator string) (*PNR, error) {
+	pnr, err := s.repo.GetPNRForUpdate(ctx, normalizeLocator(locator))
+	if err != nil {
+		return nil, err
+	}
+	if pnr == nil || pnr.UserID != userID {
+		return nil, ErrPNRNotFound
+	}
+	if pnr.Status != PNRStatusActive {
+		return nil, ErrPNRCancelled
+	}
+	return pnr, nil
+}
+
+// issueRequest describes tickets to issue on one flight.
+type issueRequest struct {
+	flightID     int64
+	bookingClass string // Empty takes the quote's class or the cheapest economy class with seats left
+	travellers   []passenger.Passenger
+	seats        []TravellerRequest // Seat choice of each traveller, in the same order
+	quoteID      *int64             // Locked price quote; the current price applies otherwise
+	reserved     bool               // Capacity is already held, as for a waitlist offer
+}
+
+// issueTickets issues tickets on one flight and opens one payment intent for all of them. It
+// must run inside a transaction so a failure for any traveller undoes the others.
+func (s *Service) issueTickets(ctx context.Context, pnr *PNR, req issueRequest) ([]Ticket, error) {
+	tickets, err := s.createTickets(ctx, pnr, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.openIntent(ctx, pnr, tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+// createTickets reserves capacity in the flight and the booking class, claims a seat and
+// creates a ticket awaiting payment for each traveller. Sales close once the flight starts
+// boarding or its departure time has passed. Tickets are priced by the locked quote
+// if one is given, or at the class's current price.
+func (s *Service) createTickets(ctx context.Context, pnr *PNR, req issueRequest) ([]Ticket, error) {
+	flightID, travellers := req.flightID, req.travellers
+
+	// Get Flight details to check capacity
+	f, err := s.flightRepo.GetByID(ctx, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	if f == nil {
+		return nil, ErrFlightNotFound
+	}
+	if f.Status == flight.StatusCancelled {
+		return nil, ErrFlightCancelled
+	}
+	if !f.Bookable() || !f.DepartureTime.After(time.Now()) {
+		return nil, ErrFlightClosed
+	}
+
+	// Price the tickets, which also settles their booking class
+	bookingClass := strings.ToUpper(req.bookingClass)
+	var quote *pricing.Quote
+	if req.quoteID != nil {
+		quote, err = s.priceService.UseQuote(ctx, pnr.UserID, *req.quoteID, flightID, bookingClass, pnr.ID)
+	} else {
+		if bookingClass == "" {
+			// Lock the inventory first so concurrent bookings cannot take the class picked
+			if !req.reserved {
+				if _, _, err := s.flightRepo.LockInventory(ctx, flightID); err != nil {
+					return nil, err
+				}
+			}
+			bookingClass, err = s.cheapestClass(ctx, flightID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		quote, err = s.priceService.CurrentPrice(ctx, flightID, bookingClass)
+	}
+	if err != nil {
+		return nil, err
+	}
+	bookingClass = quote.BookingClass
+
+	buckets, err := s.flightRepo.ListFareBuckets(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	bucket := flight.FindBucket(buckets, bookingClass)
+	if bucket == nil {
+		return nil, flight.ErrBookingClassNotOffered
+	}
+	fareClass := bucket.Cabin
+	rule, err := s.repo.GetCurrentFareRule(ctx, fareClass)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("no fare rule configured for fare class %s", fareClass)
+	}
+
+	tickets := make([]Ticket, 0, len(travellers))
+	for i, traveller := range travellers {
+		// Reserve capacity (locks the flight's inventory row, then its fare buckets, until commit)
+		if !req.reserved {
+			if err := s.repo.ReserveSeat(ctx, flightID); err != nil {
+				return nil, err
+			}
+			if err := s.reserveBucket(ctx, flightID, bookingClass); err != nil {
+				return nil, err
+			}
+		}
+
+		// Resolve seat (held, requested or first available)
+		seatNo, err := s.seatService.ClaimSeat(ctx, pnr.UserID, flightID, req.seats[i].SeatHoldID, req.seats[i].SeatNo, fareClass)
+		if err != nil {
+			return nil, err
+		}
+
+		ticket := Ticket{
+			BookingID:     &pnr.ID,
+			RecordLocator: &pnr.RecordLocator,
+			FlightID:      flightID,
+			PassengerID:   traveller.ID,
+			UserID:        pnr.UserID,
+			SeatNo:        &seatNo,
+			Price:         quote.Amount,
+			FareClass:     fareClass,
+			BookingClass:  bookingClass,
+			FareRuleID:    &rule.ID,
+			Status:        "PENDING_PAYMENT",
+		}
+		if traveller.FullName != nil {
+			ticket.PassengerName = *traveller.FullName
+		}
+
+		id, err := s.repo.Create(ctx, &ticket)
+		if err != nil {
+			return nil, err
+		}
+		ticket.ID = id
+		ticket.Flight = f // Attach flight details for response
+		tickets = append(tickets, ticket)
+	}
+	return tickets, nil
+}
+
+// openIntent opens one payment intent for tickets, which are released together if it is not paid.
+func (s *Service) openIntent(ctx context.Context, pnr *PNR, tickets []Ticket) error {
+	var amount float64
+	ticketIDs := make([]int64, len(tickets))
+	for i, t := range tickets {
+		amount += t.Price
+		ticketIDs[i] = t.ID
+	}
+	intent, err := s.payService.CreateIntent(ctx, pnr.ID, pnr.UserID, amount)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetPaymentIntent(ctx, intent.ID, ticketIDs); err != nil {
+		return err
+	}
+	for i := range tickets {
+		tickets[i].PaymentIntentID = &intent.ID
+	}
+	return nil
+}
+
+// cheapestClass returns the cheapest economy class of a flight with a seat left.
+func (s *Service) cheapestClass(ctx context.Context, flightID int64) (string, error) {
+	buckets, err := s.flightRepo.ListFareBuckets(ctx, flightID)
+	if err != nil {
+		return "", err
+	}
+	bucket := flight.CheapestAvailable(buckets, flight.CabinEconomy)
+	if bucket == nil {
+		return "", ErrFlightFull
+	}
+	return bucket.BookingClass, nil
+}
+
+// reserveBucket sells one seat of a booking class, nesting into lower classes' allocations
+// when its own is used up. Callers hold the flight's inventory lock.
+func (s *Service) reserveBucket(ctx context.Context, flightID int64, bookingClass string) error {
+	buckets, err := s.flightRepo.LockFareBuckets(ctx, flightID)
+	if err != nil {
+		return err
+	}
+	bucket := flight.FindBucket(buckets, bookingClass)
+	if bucket == nil {
+		return flight.ErrBookingClassNotOffered
+	}
+	if bucket.Available < 1 {
+		return fmt.Errorf("%w: %s", flight.ErrBookingClassFull, bookingClass)
+	}
+	return s.flightRepo.AdjustBucketSold(ctx, flightID, bookingClass, 1)
+}
+
+// ticketsToCancel picks the PNR tickets named in ids, or every active ticket if ids is empty.
+func ticketsToCancel(tickets []Ticket, ids []int64) ([]Ticket, error) {
+	if len(ids) == 0 {
+		var active []Ticket
+		for _, t := range tickets {
+			if t.Status == "ACTIVE" {
+				active = append(active, t)
+			}
+		}
+		return active, nil
+	}
+
+	byID := make(map[int64]Ticket, len(tickets))
+	for _, t := range tickets {
+		byID[t.ID] = t
+	}
+	targets := make([]Ticket, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		t, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: ticket %d is not on this PNR", ErrTicketNotFound, id)
+		}
+		if t.Status == "PENDING_PAYMENT" {
+			return nil, fmt.Errorf("%w: ticket %d", ErrTicketPendingPayment, id)
+		}
+		if t.Status != "ACTIVE" {
+			return nil, fmt.Errorf("%w: ticket %d", ErrTicketCancelled, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+// normalizeLocator upper-cases a record locator typed by a user.
+func normalizeLocator(locator string) string {
+	return strings.ToUpper(strings.TrimSpace(locator))
+}
+
+// ConfirmPayment pays one of a PNR's payment intents with a card. On success the intent's
+// tickets become ACTIVE; if the card is declined they are released. Test cards that the
+// gateway settles asynchronously leave the intent PROCESSING until its webhook arrives.
+func (s *Service) ConfirmPayment(ctx context.Context, userID int64, locator string, intentID int64, card payment.Card) (*PNR, error) {
+	pnr, err := s.repo.GetPNR(ctx, normalizeLocator(locator))
+	if err != nil {
+		return nil, err
+	}
+	if pnr == nil || pnr.UserID != userID {
+		return nil, ErrPNRNotFound
+	}
+	intent, err := s.payService.GetIntent(ctx, intentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent.BookingID != pnr.ID {
+		return nil, payment.ErrPaymentNotFound
+	}
+
+	// 1. Mark the intent PROCESSING so concurrent attempts cannot charge twice
+	expired := false
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.LockPNR(ctx, pnr.ID); err != nil {
+			return err
+		}
+		var err error
+		intent, err = s.payService.Begin(ctx, intentID, card)
+		if errors.Is(err, payment.ErrPaymentExpired) {
+			// Release the tickets now rather than waiting for the expiry sweep
+			expired = true
+			return s.expireIntent(ctx, intentID)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, payment.ErrPaymentExpired
+	}
+
+	// 2. Charge the card outside any transaction
+	outcome, err := s.payService.Charge(ctx, intent, card)
+	if err != nil {
+		if reopenErr := s.payService.Reopen(ctx, intentID); reopenErr != nil {
+			s.log.Error("Failed to reopen payment intent", "intent_id", intentID, "error", reopenErr)
+		}
+		return nil, err
+	}
+
+	// 3. Record the outcome and apply it to the tickets
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.LockPNR(ctx, pnr.ID); err != nil {
+			return err
+		}
+		settled, changed, err := s.payService.Settle(ctx, intentID, outcome)
+		if err != nil {
+			return err
+		}
+		if changed {
+			return s.applyPaymentOutcome(ctx, settled)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if outcome.Status == payment.IntentFailed {
+		return nil, fmt.Errorf("%w: %s", payment.ErrPaymentDeclined, outcome.FailureCode)
+	}
+	return s.GetPNR(ctx, userID, pnr.RecordLocator)
+}
+
+// HandlePaymentWebhook applies a gateway notification. Each event is applied at most once;
+// redeliveries and events for intents that are already settled are acknowledged without effect,
+// apart from refunding money captured after the intent was closed. Events for references no
+// intent has yet return payment.ErrWebhookUnmatched.
+func (s *Service) HandlePaymentWebhook(ctx context.Context, payload []byte, header http.Header) error {
+	event, err := s.payService.ParseWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+	outcome, known := event.Outcome()
+
+	return s.txManager.Run(ctx, func(ctx context.Context) error {
+		intent, fresh, err := s.payService.RecordWebhook(ctx, event)
+		if err != nil {
+			return err
+		}
+		if !fresh || !known {
+			return nil
+		}
+
+		if err := s.repo.LockPNR(ctx, intent.BookingID); err != nil {
+			return err
+		}
+		settled, changed, err := s.payService.Settle(ctx, intent.ID, outcome)
+		if err != nil {
+			return err
+		}
+		if changed {
+			return s.applyPaymentOutcome(ctx, settled)
+		}
+		return nil
+	})
+}
+
+// ExpirePayments releases the tickets of payment intents whose deadline has passed and
+// returns how many intents it expired. It is meant to be called periodically.
+func (s *Service) ExpirePayments(ctx context.Context) (int, error) {
+	due, err := s.payService.ListDue(ctx, expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, intent := range due {
+		err := s.txManager.Run(ctx, func(ctx context.Context) error {
+			if err := s.repo.LockPNR(ctx, intent.BookingID); err != nil {
+				return err
+			}
+			return s.expireIntent(ctx, intent.ID)
+		})
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire payment intent %d: %w", intent.ID, err)
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// expireIntent expires an overdue intent and releases its tickets. Callers hold the PNR's lock.
+func (s *Service) expireIntent(ctx context.Context, intentID int64) error {
+	intent, changed, err := s.payService.Expire(ctx, intentID)
+	if err != nil {
+		return err
+	}
+	if changed {
+		return s.applyPaymentOutcome(ctx, intent)
+	}
+	return nil
+}
+
+// cancelOpenPayments voids the PNR's intents that are still awaiting payment and releases
+// their tickets. It fails while an intent is being processed by the gateway.
+func (s *Service) cancelOpenPayments(ctx context.Context, bookingID int64) error {
+	intents, err := s.payService.ListByBooking(ctx, bookingID)
+	if err != nil {
+		return err
+	}
+	for _, intent := range intents {
+		if !intent.IsOpen() {
+			continue
+		}
+		cancelled, changed, err := s.payService.Cancel(ctx, intent.ID)
+		if err != nil {
+			return err
+		}
+		if changed {
+			if err := s.applyPaymentOutcome(ctx, cancelled); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyPaymentOutcome activates or releases an intent's tickets according to its new status.
+// Callers hold the PNR's lock.
+func (s *Service) applyPaymentOutcome(ctx context.Context, intent *payment.Intent) error {
+	switch intent.Status {
+	case payment.IntentSucceeded:
+		n, err := s.repo.ActivatePaidTickets(ctx, intent.ID)
+		if err != nil {
+			return err
+		}
+		s.log.Info("Tickets paid", "intent_id", intent.ID, "booking_id", intent.BookingID, "tickets", n)
+		return nil
+
+	case payment.IntentFailed, payment.IntentExpired, payment.IntentCancelled:
+		released, err := s.repo.CancelUnpaidTickets(ctx, intent.ID)
+		if err != nil {
+			return err
+		}
+		// Offer the seats to the flights' waitlists or return them to inventory
+		for _, t := range released {
+			if err := s.freeSeat(ctx, t.FlightID, t.SeatNo, t.FareClass, t.BookingClass); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.ClosePNRIfEmpty(ctx, intent.BookingID); err != nil {
+			return err
+		}
+		s.log.Info("Unpaid tickets released", "intent_id", intent.ID, "booking_id", intent.BookingID, "status", intent.Status, "tickets", len(released))
+		return nil
+	}
+	return nil
+}
+
+// JoinWaitlist queues one of the user's travellers for a sold-out flight. Passengers are served
+// by fare class priority, then in the order they joined. Without a passenger_id the user's own
+// profile is queued.
+func (s *Service) JoinWaitlist(ctx context.Context, userID, flightID int64, req JoinWaitlistRequest) (*WaitlistEntry, error) {
+	var traveller *passenger.Passenger
+	if req.PassengerID == 0 {
+		profile, err := s.passService.GetProfile(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check passenger profile: %w", err)
+		}
+		if profile == nil {
+			return nil, ErrProfileRequired
+		}
+		traveller = profile
+	} else {
+		travellers, err := s.passService.ResolveTravellers(ctx, userID, []int64{req.PassengerID})
+		if err != nil {
+			return nil, err
+		}
+		traveller = &travellers[0]
+	}
+
+	f, err := s.flightRepo.GetByID(ctx, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	if f == nil {
+		return nil, ErrFlightNotFound
+	}
+	if !waitlistOpen(f, time.Now()) {
+		return nil, ErrWaitlistClosed
+	}
+
+	booked, err := s.repo.HasTicketOnFlight(ctx, traveller.ID, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if booked {
+		return nil, ErrAlreadyBooked
+	}
+
+	buckets, err := s.flightRepo.ListFareBuckets(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	var bucket *flight.FareBucket
+	if req.BookingClass == "" {
+		// The cheapest economy class unless a class was asked for
+		for i := range buckets {
+			if buckets[i].Cabin == flight.CabinEconomy && (bucket == nil || buckets[i].Rank > bucket.Rank) {
+				bucket = &buckets[i]
+			}
+		}
+	} else {
+		bucket = flight.FindBucket(buckets, strings.ToUpper(req.BookingClass))
+	}
+	if bucket == nil {
+		return nil, flight.ErrBookingClassNotOffered
+	}
+
+	// Waiting is allowed once the flight or the class's cabin has no seat left
+	soldOut, err := s.repo.IsSoldOut(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	unsold := 0
+	for _, b := range buckets {
+		if b.Cabin == bucket.Cabin {
+			unsold += b.Allocation - b.Sold
+		}
+	}
+	if !soldOut && unsold > 0 {
+		return nil, ErrFlightNotFull
+	}
+
+	entry := &WaitlistEntry{
+		FlightID:     flightID,
+		UserID:       userID,
+		PassengerID:  traveller.ID,
+		FareClass:    bucket.Cabin,
+		BookingClass: bucket.BookingClass,
+		// Higher booking classes are served first within a cabin
+		Priority: waitlistPriority[bucket.Cabin] - bucket.Rank,
+		Status:   WaitlistWaiting,
+	}
+	if err := s.repo.CreateWaitlistEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Passenger waitlisted", "flight_id", flightID, "passenger_id", traveller.ID, "entry_id", entry.ID)
+	return s.repo.GetWaitlistEntry(ctx, entry.ID)
+}
+
+// ListMyWaitlist returns the waitlist entries of all the user's travellers with their positions.
+func (s *Service) ListMyWaitlist(ctx context.Context, userID int64) ([]WaitlistEntry, error) {
+	return s.repo.ListWaitlistByUser(ctx, userID)
+}
+
+// ConfirmWaitlistOffer books the seat offered to a waitlisted passenger. The ticket is issued
+// under a new PNR and awaits payment like any other booking.
+func (s *Service) ConfirmWaitlistOffer(ctx context.Context, userID, entryID int64) (*PNR, error) {
+	pnr := &PNR{UserID: userID, Status: PNRStatusActive}
+	expired := false
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		entry, err := s.ownWaitlistEntry(ctx, userID, entryID)
+		if err != nil {
+			return err
+		}
+		if entry.Status != WaitlistOffered {
+			return ErrNoWaitlistOffer
+		}
+		if !entry.OfferExpiresAt.After(time.Now()) {
+			// Pass the seat on now rather than waiting for the expiry sweep
+			expired = true
+			return s.expireOffer(ctx, entry)
+		}
+
+		travellers, err := s.passService.ResolveTravellers(ctx, userID, []int64{entry.PassengerID})
+		if err != nil {
+			return err
+		}
+		if err := s.repo.CreatePNR(ctx, pnr); err != nil {
+			return err
+		}
+		tr := TravellerRequest{PassengerID: entry.PassengerID}
+		if entry.OfferedSeatNo != nil {
+			tr.SeatNo = *entry.OfferedSeatNo
+		}
+		bookingClass := entry.BookingClass
+		if entry.OfferedBookingClass != nil {
+			bookingClass = *entry.OfferedBookingClass
+		}
+		tickets, err := s.issueTickets(ctx, pnr, issueRequest{
+			flightID:     entry.FlightID,
+			bookingClass: bookingClass,
+			travellers:   travellers,
+			seats:        []TravellerRequest{tr},
+			reserved:     true,
+		})
+		if err != nil {
+			return err
+		}
+
+		entry.Status = WaitlistConfirmed
+		entry.TicketID = &tickets[0].ID
+		return s.repo.UpdateWaitlistEntry(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrWaitlistOfferExpired
+	}
+
+	s.log.Info("Waitlist offer confirmed", "entry_id", entryID, "record_locator", pnr.RecordLocator, "user_id", userID)
+	return s.GetPNR(ctx, userID, pnr.RecordLocator)
+}
+
+// LeaveWaitlist removes one of the user's travellers from a waitlist. A seat they had been
+// offered passes to the next passenger in line.
+func (s *Service) LeaveWaitlist(ctx context.Context, userID, entryID int64) (*WaitlistEntry, error) {
+	var entry *WaitlistEntry
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		var err error
+		entry, err = s.ownWaitlistEntry(ctx, userID, entryID)
+		if err != nil {
+			return err
+		}
+
+		switch entry.Status {
+		case WaitlistWaiting:
+			entry.Status = WaitlistCancelled
+			entry.Position = nil
+			return s.repo.UpdateWaitlistEntry(ctx, entry)
+		case WaitlistOffered:
+			return s.withdrawOffer(ctx, entry, WaitlistCancelled)
+		default:
+			return ErrWaitlistEntryClosed
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Passenger left waitlist", "entry_id", entryID, "flight_id", entry.FlightID, "user_id", userID)
+	return entry, nil
+}
+
+// ExpireWaitlistOffers passes on the seats of waitlist offers whose deadline has passed and
+// returns how many offers it expired. It is meant to be called periodically.
+func (s *Service) ExpireWaitlistOffers(ctx context.Context) (int, error) {
+	due, err := s.repo.ListDueWaitlistOffers(ctx, expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, id := range due {
+		err := s.txManager.Run(ctx, func(ctx context.Context) error {
+			entry, err := s.repo.GetWaitlistEntryForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			// Confirmed or withdrawn since it was listed
+			if entry == nil || entry.Status != WaitlistOffered || entry.OfferExpiresAt.After(time.Now()) {
+				return nil
+			}
+			return s.expireOffer(ctx, entry)
+		})
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire waitlist offer %d: %w", id, err)
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// ownWaitlistEntry locks one of the user's waitlist entries for the rest of the transaction.
+func (s *Service) ownWaitlistEntry(ctx context.Context, userID, entryID int64) (*WaitlistEntry, error) {
+	entry, err := s.repo.GetWaitlistEntryForUpdate(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || entry.UserID != userID {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	return entry, nil
+}
+
+// expireOffer closes an overdue offer and passes its seat on. Callers hold the entry's lock.
+func (s *Service) expireOffer(ctx context.Context, entry *WaitlistEntry) error {
+	if err := s.withdrawOffer(ctx, entry, WaitlistExpired); err != nil {
+		return err
+	}
+	s.log.Info("Waitlist offer expired", "entry_id", entry.ID, "flight_id", entry.FlightID)
+	return nil
+}
+
+// withdrawOffer closes an open offer with the given status, drops the seat hold made for it
+// and passes the seat to the next passenger in line. Callers hold the entry's lock.
+func (s *Service) withdrawOffer(ctx context.Context, entry *WaitlistEntry, status string) error {
+	seatNo, bookingClass := entry.OfferedSeatNo, entry.BookingClass
+	if entry.OfferedBookingClass != nil {
+		bookingClass = *entry.OfferedBookingClass
+	}
+	if seatNo != nil {
+		if err := s.seatService.DropHold(ctx, entry.UserID, entry.FlightID, *seatNo); err != nil {
+			return err
+		}
+	}
+
+	entry.Status = status
+	entry.OfferedSeatNo = nil
+	entry.OfferedBookingClass = nil
+	entry.OfferExpiresAt = nil
+	if err := s.repo.UpdateWaitlistEntry(ctx, entry); err != nil {
+		return err
+	}
+	return s.freeSeat(ctx, entry.FlightID, seatNo, entry.FareClass, bookingClass)
+}
+
+// freeSeat hands a seat given up on a flight to the first eligible passenger waitlisted for its
+// cabin, keeping it counted as sold in its booking class while the offer is open, or returns it
+// to the flight's inventory and fare bucket if nobody is waiting. It must run inside a transaction.
+func (s *Service) freeSeat(ctx context.Context, flightID int64, seatNo *string, cabin, bookingClass string) error {
+	promoted, err := s.promoteWaitlisted(ctx, flightID, seatNo, cabin, bookingClass)
+	if err != nil {
+		return err
+	}
+	if promoted {
+		return nil
+	}
+	if err := s.repo.ReleaseSeat(ctx, flightID); err != nil {
+		return err
+	}
+	return s.flightRepo.AdjustBucketSold(ctx, flightID, bookingClass, -1)
+}
+
+// promoteWaitlisted offers a freed seat to the next eligible passenger waitlisted for its cabin
+// and holds it for them until the offer expires. The offer is in the booking class the seat was
+// sold in. It reports whether anybody was promoted.
+func (s *Service) promoteWaitlisted(ctx context.Context, flightID int64, seatNo *string, cabin, bookingClass string) (bool, error) {
+	f, err := s.flightRepo.GetByID(ctx, flightID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get flight: %w", err)
+	}
+	now := time.Now()
+	if f == nil || !waitlistOpen(f, now) {
+		return false, nil
+	}
+
+	entry, err := s.repo.NextWaitlisted(ctx, flightID, cabin)
+	if err != nil || entry == nil {
+		return false, err
+	}
+
+	deadline := now.Add(WaitlistOfferTTL)
+	if f.DepartureTime.Before(deadline) {
+		deadline = f.DepartureTime
+	}
+	if seatNo != nil {
+		hold, err := s.seatService.HoldFor(ctx, entry.UserID, flightID, *seatNo, deadline)
+		if err != nil {
+			return false, err
+		}
+		seatNo = &hold.SeatNo
+	}
+
+	entry.Status = WaitlistOffered
+	entry.Position = nil
+	entry.OfferedSeatNo = seatNo
+	entry.OfferedBookingClass = &bookingClass
+	entry.OfferExpiresAt = &deadline
+	if err := s.repo.UpdateWaitlistEntry(ctx, entry); err != nil {
+		return false, err
+	}
+
+	s.log.Info("Waitlisted passenger offered a seat", "entry_id", entry.ID, "flight_id", flightID, "passenger_id", entry.PassengerID, "offer_expires_at", deadline)
+	return true, nil
+}
+
+// waitlistOpen reports whether a flight still takes waitlisted passengers.
+func waitlistOpen(f *flight.Flight, now time.Time) bool {
+	switch f.Status {
+	case flight.StatusDeparted, flight.StatusArrived, flight.StatusDiverted, flight.StatusCancelled:
+		return false
+	}
+	return f.DepartureTime.After(now)
+}
+
+// UpdateFlight retimes a flight, and renumbers it if asked, and reports the impact on its
+// bookings. Every ticket holder is notified of the change. Moving the departure by
+// flight.SignificantRetime or more flags the active tickets for rebooking or a full refund, and
+// moving it at all releases the flight's gate, which has to be assigned again for the new times.
+func (s *Service) UpdateFlight(ctx context.Context, flightID int64, req flight.UpdateFlightRequest) (*FlightImpact, error) {
+	var impact *FlightImpact
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		if _, _, err := s.flightRepo.LockInventory(ctx, flightID); err != nil {
+			return err
+		}
+		before, after, err := s.flightService.ReviseFlight(ctx, flightID, req)
+		if err != nil {
+			return err
+		}
+		impact = &FlightImpact{Flight: after, Change: FlightChangeUnchanged}
+		if after.Version == before.Version {
+			impact.Passengers, impact.Baggage = []ImpactedPassenger{}, []airportops.Baggage{}
+			return nil
+		}
+
+		shift := after.DepartureTime.Sub(before.DepartureTime)
+		impact.DepartureShiftMinutes = int(shift.Minutes())
+		impact.ArrivalShiftMinutes = int(after.ArrivalTime.Sub(before.ArrivalTime).Minutes())
+		impact.Change = FlightChangeRenumbered
+		if shift != 0 || impact.ArrivalShiftMinutes != 0 {
+			impact.Change = FlightChangeRetimed
+		}
+		if shift != 0 {
+			if impact.GateReleased, err = s.releaseGate(ctx, flightID); err != nil {
+				return err
+			}
+			if impact.GateReleased {
+				after.GateID = nil
+			}
+		}
+
+		tickets, err := s.repo.ListFlightTickets(ctx, flightID)
+		if err != nil {
+			return err
+		}
+		if shift.Abs() >= flight.SignificantRetime {
+			flagged, err := s.repo.FlagRebook(ctx, flightID)
+			if err != nil {
+				return err
+			}
+			impact.RebookRequired = flagged > 0
+			for i := range tickets {
+				tickets[i].RebookRequired = tickets[i].RebookRequired || tickets[i].Status == "ACTIVE"
+			}
+		}
+
+		message := retimeMessage(before, after, req.Reason, impact.RebookRequired)
+		return s.reportImpact(ctx, impact, tickets, notification.KindFlightRetimed, func(Ticket) string { return message })
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Flight updated", "flight_id", flightID, "change", impact.Change, "passengers", len(impact.Passengers),
+		"rebook_required", impact.RebookRequired, "gate_released", impact.GateReleased)
+	return impact, nil
 }
 
-// NewService creates a new booking service.
-func NewService(repo *Repository, flightRepo *flight.Repository, txManager database.TxManager, opsService *airportops.Service, passService *passenger.Service, log *slog.Logger) *Service {
-	return &Service{
-		repo:        repo,
-		flightRepo:  flightRepo,
-		txManager:   txManager,
-		opsService:  opsService,
-		passService: passService,
-		log:         log,
+// CancelFlight cancels a flight and reports the impact on its bookings. Active tickets are
+// flagged for rebooking onto another flight or cancelling with a full refund, tickets awaiting
+// payment are released and their payments voided, waitlisted passengers are dropped and the
+// gate is released. Ticket holders and waitlisted passengers are notified. It fails while a
+// payment for the flight is being processed by the gateway, and with ErrFlightBusy if unpaid
+// bookings keep appearing on the flight while it is being cancelled.
+func (s *Service) CancelFlight(ctx context.Context, flightID, userID int64, req flight.CancelFlightRequest) (*FlightImpact, error) {
+	// Lock the PNRs of unpaid tickets ahead of the inventory, as bookings do
+	unpaid, err := s.repo.ListFlightTickets(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	bookingIDs := unpaidBookings(unpaid, nil)
+
+	var impact *FlightImpact
+	for attempt := 1; ; attempt++ {
+		var missed []int64
+		err = s.txManager.Run(ctx, func(ctx context.Context) error {
+			var err error
+			impact, missed, err = s.cancelFlight(ctx, flightID, userID, req, bookingIDs)
+			return err
+		})
+		if len(missed) == 0 || attempt == cancelFlightAttempts {
+			break
+		}
+		// Start over with the PNRs of tickets booked since the listing
+		bookingIDs = append(bookingIDs, missed...)
+		slices.Sort(bookingIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Flight cancelled with bookings", "flight_id", flightID, "passengers", len(impact.Passengers),
+		"payments_voided", impact.PaymentsVoided, "waitlist_cancelled", impact.WaitlistCancelled, "user_id", userID)
+	return impact, nil
+}
+
+// cancelFlightAttempts caps how many times CancelFlight starts over because unpaid tickets
+// were booked on the flight in PNRs it had not locked.
+const cancelFlightAttempts = 3
+
+// unpaidBookings returns the PNRs of unpaid tickets, in order, leaving out those in locked.
+func unpaidBookings(tickets []Ticket, locked []int64) []int64 {
+	var ids []int64
+	for _, t := range tickets {
+		if t.Status == "PENDING_PAYMENT" && t.BookingID != nil && !slices.Contains(locked, *t.BookingID) {
+			ids = append(ids, *t.BookingID)
+		}
 	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
 }
 
-// BookTicket books a ticket for a user on a flight transactionally.
-func (s *Service) BookTicket(ctx context.Context, userID int64, req BookingRequest) (*Ticket, error) {
-	var ticket *Ticket
+// cancelFlight is CancelFlight's transaction, with the given PNRs locked ahead of the inventory.
+// If the flight has unpaid tickets in other PNRs, locking those now would take a PNR after the
+// inventory, against the order bookings use; it returns them with ErrFlightBusy instead.
+func (s *Service) cancelFlight(ctx context.Context, flightID, userID int64, req flight.CancelFlightRequest, bookingIDs []int64) (*FlightImpact, []int64, error) {
+	for _, id := range bookingIDs {
+		if err := s.repo.LockPNR(ctx, id); err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, _, err := s.flightRepo.LockInventory(ctx, flightID); err != nil {
+		return nil, nil, err
+	}
 
-	// 0. Resolve Passenger ID
-	// Check if passenger profile exists
-	passProfile, err := s.passService.GetProfile(ctx, userID)
+	// Listed again under the lock: tickets may have been paid or booked since
+	tickets, err := s.repo.ListFlightTickets(ctx, flightID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check passenger profile: %w", err)
+		return nil, nil, err
+	}
+	if missed := unpaidBookings(tickets, bookingIDs); len(missed) > 0 {
+		return nil, missed, ErrFlightBusy
 	}
 
-	var passengerID int64
-	if passProfile != nil {
-		passengerID = passProfile.ID
-	} else {
-		// Profile does not exist, require passport info
-		if req.PassportNo == "" {
-			return nil, errors.New("passenger profile required: please provide passport_no and phone")
+	f, err := s.flightService.CancelFlight(ctx, flightID, userID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	impact := &FlightImpact{Flight: f, Change: FlightChangeCancelled}
+
+	voided := map[int64]bool{}
+	for _, t := range tickets {
+		if t.Status != "PENDING_PAYMENT" || t.PaymentIntentID == nil || voided[*t.PaymentIntentID] {
+			continue
 		}
-		// Create new profile
-		newProfile, err := s.passService.CreateProfile(ctx, userID, req.PassportNo, req.Phone)
+		intent, changed, err := s.payService.Cancel(ctx, *t.PaymentIntentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if changed {
+			if err := s.applyPaymentOutcome(ctx, intent); err != nil {
+				return nil, nil, err
+			}
+		}
+		voided[*t.PaymentIntentID] = true
+	}
+	impact.PaymentsVoided = len(voided)
+
+	flagged, err := s.repo.FlagRebook(ctx, flightID)
+	if err != nil {
+		return nil, nil, err
+	}
+	impact.RebookRequired = flagged > 0
+	for i := range tickets {
+		switch tickets[i].Status {
+		case "ACTIVE":
+			tickets[i].RebookRequired = true
+		case "PENDING_PAYMENT":
+			tickets[i].Status = "CANCELLED"
+		}
+	}
+
+	if impact.WaitlistCancelled, err = s.cancelWaitlist(ctx, f); err != nil {
+		return nil, nil, err
+	}
+	impact.Notified = impact.WaitlistCancelled
+	if impact.GateReleased, err = s.releaseGate(ctx, flightID); err != nil {
+		return nil, nil, err
+	}
+	if impact.GateReleased {
+		f.GateID = nil
+	}
+
+	err = s.reportImpact(ctx, impact, tickets, notification.KindFlightCancelled, func(t Ticket) string {
+		return cancellationMessage(f, req.Reason, t.Status == "ACTIVE")
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return impact, nil, nil
+}
+
+// reportImpact lists the flight's tickets and baggage in the impact and notifies each ticket's
+// holder with the message for it. Callers run it in a transaction.
+func (s *Service) reportImpact(ctx context.Context, impact *FlightImpact, tickets []Ticket, kind string, message func(Ticket) string) error {
+	bags, err := s.opsService.GetFlightBaggage(ctx, impact.Flight.ID)
+	if err != nil {
+		return err
+	}
+	bagCount := map[int64]int{}
+	for _, b := range bags {
+		bagCount[b.TicketID]++
+	}
+	impact.Baggage = bags
+
+	impact.Passengers = make([]ImpactedPassenger, 0, len(tickets))
+	for _, t := range tickets {
+		impact.Passengers = append(impact.Passengers, ImpactedPassenger{
+			TicketID:       t.ID,
+			RecordLocator:  t.RecordLocator,
+			PassengerID:    t.PassengerID,
+			PassengerName:  t.PassengerName,
+			SeatNo:         t.SeatNo,
+			FareClass:      t.FareClass,
+			Status:         t.Status,
+			RebookRequired: t.RebookRequired,
+			Bags:           bagCount[t.ID],
+		})
+
+		err := s.notifService.Notify(ctx, &notification.Notification{
+			UserID:   t.UserID,
+			Kind:     kind,
+			FlightID: &impact.Flight.ID,
+			TicketID: &t.ID,
+			Message:  fmt.Sprintf("%s: %s", t.PassengerName, message(t)),
+		})
+		if err != nil {
+			return err
+		}
+		impact.Notified++
+	}
+	return nil
+}
+
+// cancelWaitlist drops the passengers waiting for or offered a seat on a cancelled flight and
+// notifies them, returning how many were dropped. Callers hold the flight's inventory lock.
+func (s *Service) cancelWaitlist(ctx context.Context, f *flight.Flight) (int, error) {
+	entries, err := s.repo.LockFlightWaitlist(ctx, f.ID)
+	if err != nil {
+		return 0, err
+	}
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Status == WaitlistOffered {
+			err = s.withdrawOffer(ctx, entry, WaitlistCancelled)
+		} else {
+			entry.Status = WaitlistCancelled
+			entry.Position = nil
+			err = s.repo.UpdateWaitlistEntry(ctx, entry)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		err = s.notifService.Notify(ctx, &notification.Notification{
+			UserID:   entry.UserID,
+			Kind:     notification.KindWaitlistCancelled,
+			FlightID: &f.ID,
+			Message: fmt.Sprintf("Flight %s from %s to %s departing %s has been cancelled, so its waitlist is closed.",
+				f.FlightNo, f.Origin, f.Destination, formatFlightTime(f.DepartureTime)),
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), nil
+}
+
+// releaseGate removes a flight's gate assignment, reporting whether it had one.
+func (s *Service) releaseGate(ctx context.Context, flightID int64) (bool, error) {
+	err := s.opsService.UnassignGate(ctx, flightID)
+	if errors.Is(err, airportops.ErrAssignmentNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// retimeMessage tells ticket holders how their flight changed.
+func retimeMessage(before, after *flight.Flight, reason string, rebook bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Flight %s from %s to %s departing %s", before.FlightNo, before.Origin, before.Destination, formatFlightTime(before.DepartureTime))
+	if after.FlightNo != before.FlightNo {
+		fmt.Fprintf(&b, " is now flight %s and", after.FlightNo)
+	}
+	fmt.Fprintf(&b, " departs %s and arrives %s.", formatFlightTime(after.DepartureTime), formatFlightTime(after.ArrivalTime))
+	if reason != "" {
+		fmt.Fprintf(&b, " Reason: %s.", strings.TrimSuffix(reason, "."))
+	}
+	if rebook {
+		b.WriteString(" You may move to another flight on the route or cancel the ticket for a full refund.")
+	}
+	return b.String()
+}
+
+// cancellationMessage tells a ticket holder their flight was cancelled and what happens next.
+func cancellationMessage(f *flight.Flight, reason string, paid bool) string {
+	msg := fmt.Sprintf("Flight %s from %s to %s departing %s has been cancelled: %s.",
+		f.FlightNo, f.Origin, f.Destination, formatFlightTime(f.DepartureTime), strings.TrimSuffix(reason, "."))
+	if paid {
+		return msg + " You may move to another flight on the route or cancel the ticket for a full refund."
+	}
+	return msg + " The unpaid booking has been released and nothing will be charged."
+}
+
+// formatFlightTime formats a flight time for notifications.
+func formatFlightTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 UTC")
+}
+
+// GetMyBookings returns the tickets of all the user's travellers.
+func (s *Service) GetMyBookings(ctx context.Context, userID int64) ([]Ticket, error) {
+	return s.repo.GetByUserID(ctx, userID)
+}
+
+// GetRefundQuote returns what cancelling one of the user's tickets now would refund.
+func (s *Service) GetRefundQuote(ctx context.Context, userID, ticketID int64) (*RefundQuote, error) {
+	ticket, err := s.ownTicket(ctx, userID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return s.quoteRefund(ctx, ticket)
+}
+
+// CancelTicket cancels a user's ticket and records its refund under the ticket's fare rule.
+func (s *Service) CancelTicket(ctx context.Context, userID, ticketID int64) (*CancellationResult, error) {
+	ticket, err := s.ownTicket(ctx, userID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	var refund *payment.Refund
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		if ticket.BookingID != nil {
+			if err := s.repo.LockPNR(ctx, *ticket.BookingID); err != nil {
+				return err
+			}
+		}
+		// Read again under the lock, as a rebooking may have moved the ticket to another flight
+		current, err := s.repo.GetByID(ctx, ticketID)
+		if err != nil {
+			return err
+		}
+		switch current.Status {
+		case "CANCELLED":
+			return ErrTicketCancelled
+		case "PENDING_PAYMENT":
+			return ErrTicketPendingPayment
+		}
+		if _, _, err := s.flightRepo.LockInventory(ctx, current.FlightID); err != nil {
+			if errors.Is(err, flight.ErrFlightNotFound) {
+				return ErrFlightNotFound
+			}
+			return err
+		}
+
+		refund, err = s.cancelWithRefund(ctx, current)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create passenger profile: %w", err)
+			return err
+		}
+		ticket = current
+		if ticket.BookingID != nil {
+			return s.repo.ClosePNRIfEmpty(ctx, *ticket.BookingID)
 		}
-		passengerID = newProfile.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.processRefunds(ctx, []*payment.Refund{refund})
+	ticket.Status = "CANCELLED"
+	return &CancellationResult{Ticket: ticket, Refund: refund}, nil
+}
+
+// ownTicket loads one of the user's tickets.
+func (s *Service) ownTicket(ctx context.Context, userID, ticketID int64) (*Ticket, error) {
+	ticket, err := s.repo.GetByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, ErrTicketNotFound
+	}
+
+	// Verify ownership via the account holding the traveller
+	if ticket.UserID != userID {
+		return nil, ErrTicketForbidden
+	}
+	return ticket, nil
+}
+
+// ChangeSeat moves one of the user's tickets to another seat in its cabin, from a hold, by
+// seat number or to the first free seat, and clears the reseat flag an equipment change may
+// have left on it. Seats can be changed until the flight starts boarding.
+func (s *Service) ChangeSeat(ctx context.Context, userID, ticketID int64, req ChangeSeatRequest) (*Ticket, error) {
+	ticket, err := s.ownTicket(ctx, userID, ticketID)
+	if err != nil {
+		return nil, err
 	}
 
 	err = s.txManager.Run(ctx, func(ctx context.Context) error {
-		// 1. Get Flight details to check capacity
-		f, err := s.flightRepo.GetByID(ctx, req.FlightID)
+		if ticket.BookingID != nil {
+			if err := s.repo.LockPNR(ctx, *ticket.BookingID); err != nil {
+				return err
+			}
+		}
+		// Read again under the lock, as a rebooking may have moved the ticket to another flight
+		current, err := s.repo.GetByID(ctx, ticketID)
+		if err != nil {
+			return err
+		}
+		if current.Status == "CANCELLED" {
+			return ErrTicketCancelled
+		}
+		f, err := s.flightRepo.GetByID(ctx, current.FlightID)
 		if err != nil {
 			return fmt.Errorf("failed to get flight: %w", err)
 		}
 		if f == nil {
-			return errors.New("flight not found")
+			return ErrFlightNotFound
+		}
+		if !f.Bookable() || !f.DepartureTime.After(time.Now()) {
+			return ErrFlightClosed
 		}
 
-		// 2. Check capacity (Optimistic locking via count)
-		activeCount, err := s.repo.GetActiveTicketsCount(ctx, req.FlightID)
+		seatNo, err := s.seatService.ClaimSeat(ctx, userID, current.FlightID, req.SeatHoldID, req.SeatNo, current.FareClass)
 		if err != nil {
-			return fmt.Errorf("failed to count tickets: %w", err)
+			return err
 		}
+		return s.repo.UpdateSeat(ctx, current.ID, seatNo)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Seat changed", "ticket_id", ticketID, "user_id", userID)
+	return s.repo.GetByID(ctx, ticketID)
+}
+
+// RebookTicket moves one of the user's tickets off a cancelled or significantly retimed flight
+// onto another open flight on the same route at no charge. The ticket keeps its booking class
+// if the new flight has seats left in it, or takes the cheapest class of its cabin with seats
+// left otherwise, and gets the first free seat of its cabin. Checked baggage moves with it and
+// must fit in the new flight's hold, and the seat it leaves is offered to the old flight's
+// waitlist or returned to inventory.
+func (s *Service) RebookTicket(ctx context.Context, userID, ticketID int64, req RebookRequest) (*Ticket, error) {
+	ticket, err := s.ownTicket(ctx, userID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if req.FlightID == ticket.FlightID {
+		return nil, fmt.Errorf("%w: the ticket is already on this flight", ErrInvalidRebooking)
+	}
 
-		if activeCount >= f.TotalSeats {
-			return errors.New("flight is full")
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		if ticket.BookingID != nil {
+			if err := s.repo.LockPNR(ctx, *ticket.BookingID); err != nil {
+				return err
+			}
+		}
+		// Lock both inventories in flight ID order, as itinerary bookings do
+		flightIDs := []int64{ticket.FlightID, req.FlightID}
+		slices.Sort(flightIDs)
+		for _, id := range flightIDs {
+			if _, _, err := s.flightRepo.LockInventory(ctx, id); err != nil {
+				if errors.Is(err, flight.ErrFlightNotFound) {
+					return ErrFlightNotFound
+				}
+				return err
+			}
 		}
 
-		// 3. Create Ticket using resolved PassengerID
-		ticket = &Ticket{
-			FlightID:    req.FlightID,
-			PassengerID: passengerID, // Use PassengerID, not UserID
-			Price:       f.BasePrice,
-			Status:      "ACTIVE",
+		// Read again under the locks
+		current, err := s.repo.GetByID(ctx, ticketID)
+		if err != nil {
+			return err
+		}
+		switch current.Status {
+		case "CANCELLED":
+			return ErrTicketCancelled
+		case "PENDING_PAYMENT":
+			return ErrTicketPendingPayment
+		}
+		from, err := s.flightRepo.GetByID(ctx, current.FlightID)
+		if err != nil {
+			return fmt.Errorf("failed to get flight: %w", err)
+		}
+		if !current.RebookRequired && from.Status != flight.StatusCancelled {
+			return ErrRebookNotOffered
+		}
+		to, err := s.flightRepo.GetByID(ctx, req.FlightID)
+		if err != nil {
+			return fmt.Errorf("failed to get flight: %w", err)
+		}
+		if to.Origin != from.Origin || to.Destination != from.Destination {
+			return fmt.Errorf("%w: flight %s does not fly %s to %s", ErrInvalidRebooking, to.FlightNo, from.Origin, from.Destination)
+		}
+		if !to.Bookable() || !to.DepartureTime.After(time.Now()) {
+			return fmt.Errorf("%w: flight %s is no longer open", ErrInvalidRebooking, to.FlightNo)
+		}
+		booked, err := s.repo.HasTicketOnFlight(ctx, current.PassengerID, to.ID)
+		if err != nil {
+			return err
+		}
+		if booked {
+			return ErrAlreadyBooked
 		}
 
-		id, err := s.repo.Create(ctx, ticket)
+		bookingClass, err := s.rebookClass(ctx, to.ID, current.FareClass, current.BookingClass)
 		if err != nil {
 			return err
 		}
-		ticket.ID = id
-		ticket.Flight = f // Attach flight details for response
+		if err := s.repo.ReserveSeat(ctx, to.ID); err != nil {
+			return err
+		}
+		if err := s.reserveBucket(ctx, to.ID, bookingClass); err != nil {
+			return err
+		}
+		seatNo, err := s.seatService.ClaimSeat(ctx, userID, to.ID, nil, "", current.FareClass)
+		if err != nil {
+			return err
+		}
+		if err := s.opsService.ReserveHoldForTicket(ctx, current.ID, to.ID); err != nil {
+			return err
+		}
+		if err := s.repo.MoveTicket(ctx, current.ID, to.ID, seatNo, bookingClass); err != nil {
+			return err
+		}
+		if err := s.freeSeat(ctx, from.ID, current.SeatNo, current.FareClass, current.BookingClass); err != nil {
+			return err
+		}
+		if current.BookingID != nil {
+			return s.repo.TouchPNR(ctx, *current.BookingID)
+		}
 		return nil
 	})
-
 	if err != nil {
 		return nil, err
 	}
 
-	return ticket, nil
+	s.log.Info("Ticket rebooked", "ticket_id", ticketID, "from_flight_id", ticket.FlightID, "to_flight_id", req.FlightID, "user_id", userID)
+	return s.repo.GetByID(ctx, ticketID)
 }
 
-// GetMyBookings returns bookings for the current user.
-func (s *Service) GetMyBookings(ctx context.Context, userID int64) ([]Ticket, error) {
-	// First get passenger ID for user
-	passProfile, err := s.passService.GetProfile(ctx, userID)
+// rebookClass picks the booking class a rebooked ticket takes on its new flight: its own class
+// if the flight has seats left in it, or the cheapest class of its cabin with seats left.
+func (s *Service) rebookClass(ctx context.Context, flightID int64, cabin, bookingClass string) (string, error) {
+	buckets, err := s.flightRepo.ListFareBuckets(ctx, flightID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get passenger profile: %w", err)
+		return "", err
 	}
-	if passProfile == nil {
-		return []Ticket{}, nil // No profile means no bookings
+	if bucket := flight.FindBucket(buckets, bookingClass); bucket != nil && bucket.Cabin == cabin && bucket.Available > 0 {
+		return bookingClass, nil
+	}
+	if bucket := flight.CheapestAvailable(buckets, cabin); bucket != nil {
+		return bucket.BookingClass, nil
+	}
+	return "", ErrFlightFull
+}
+
+// cancelWithRefund cancels an active ticket, offers its seat to the flight's waitlist or returns
+// it to inventory, and records its refund. Callers run it in a transaction and hold the
+// ticket's PNR lock.
+func (s *Service) cancelWithRefund(ctx context.Context, ticket *Ticket) (*payment.Refund, error) {
+	quote, err := s.quoteRefund(ctx, ticket)
+	if err != nil {
+		return nil, err
 	}
 
-	// Fetch bookings using passenger ID
-	return s.repo.GetByPassengerID(ctx, passProfile.ID)
+	cancelled, err := s.repo.Cancel(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, fmt.Errorf("%w: ticket %d", ErrTicketCancelled, ticket.ID)
+	}
+	if err := s.freeSeat(ctx, ticket.FlightID, ticket.SeatNo, ticket.FareClass, ticket.BookingClass); err != nil {
+		return nil, err
+	}
+
+	return s.payService.CreateRefund(ctx, ticket.ID, ticket.PaymentIntentID, quote.Refund, quote.Penalty, quote.Reason)
 }
 
-// CancelTicket cancels a user's ticket.
-func (s *Service) CancelTicket(ctx context.Context, userID, ticketID int64) error {
-	ticket, err := s.repo.GetByID(ctx, ticketID)
+// quoteRefund applies the ticket's fare rule to a cancellation made now.
+func (s *Service) quoteRefund(ctx context.Context, ticket *Ticket) (*RefundQuote, error) {
+	switch ticket.Status {
+	case "CANCELLED":
+		return nil, ErrTicketCancelled
+	case "PENDING_PAYMENT":
+		return nil, ErrTicketPendingPayment
+	}
+
+	f, err := s.flightRepo.GetByID(ctx, ticket.FlightID)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get flight: %w", err)
 	}
-	if ticket == nil {
-		return errors.New("ticket not found")
+	if f == nil {
+		return nil, ErrFlightNotFound
 	}
 
-	// Verify ownership via passenger profile
-	passProfile, err := s.passService.GetProfile(ctx, userID)
+	var rule *FareRule
+	if ticket.FareRuleID != nil {
+		rule, err = s.repo.GetFareRule(ctx, *ticket.FareRuleID)
+	} else {
+		rule, err = s.repo.GetCurrentFareRule(ctx, ticket.FareClass)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if passProfile == nil || ticket.PassengerID != passProfile.ID {
-		return errors.New("unauthorized to cancel this ticket")
+	if rule == nil {
+		return nil, fmt.Errorf("no fare rule found for ticket %d", ticket.ID)
 	}
 
-	if ticket.Status == "CANCELLED" {
-		return errors.New("ticket is already cancelled")
+	currency := s.payService.Currency()
+	if ticket.PaymentIntentID != nil {
+		intent, err := s.payService.GetIntent(ctx, *ticket.PaymentIntentID)
+		if err != nil {
+			return nil, err
+		}
+		currency = intent.Currency
 	}
 
-	return s.repo.Cancel(ctx, ticketID)
+	quote, err := computeRefund(ticket, f, rule, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	quote.Currency = currency
+	return quote, nil
 }
 
-// GetUserBaggage returns all baggage for the current user across all bookings.
-func (s *Service) GetUserBaggage(ctx context.Context, userID, ticketID int64) ([]airportops.Baggage, error) {
-	// 1. Get Passenger Profile
-	passProfile, err := s.passService.GetProfile(ctx, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get passenger profile: %w", err)
+// computeRefund works out the refund for cancelling a ticket at now. A flight cancelled by the
+// airline refunds the whole fare, as does a ticket flagged for rebooking after its flight was
+// retimed, until the flight leaves; otherwise non-refundable fares refund nothing, and
+// refundable fares withhold the cancellation fee, or the no-show penalty after the cutoff.
+func computeRefund(ticket *Ticket, f *flight.Flight, rule *FareRule, now time.Time) (*RefundQuote, error) {
+	departure := f.DepartureTime
+	if f.EstimatedDepartureTime != nil {
+		departure = *f.EstimatedDepartureTime
 	}
-	if passProfile == nil {
-		return []airportops.Baggage{}, nil
+
+	quote := &RefundQuote{
+		TicketID:      ticket.ID,
+		FareRule:      rule,
+		Paid:          ticket.Price,
+		DepartureTime: departure,
+		CutoffAt:      departure.Add(-time.Duration(rule.CutoffHours) * time.Hour),
+	}
+
+	if f.Status == flight.StatusCancelled {
+		quote.Involuntary = true
+		quote.Reason = RefundReasonFlightCancelled
+		quote.Refund = quote.Paid
+		return quote, nil
+	}
+	switch f.Status {
+	case flight.StatusDeparted, flight.StatusArrived, flight.StatusDiverted:
+		return nil, ErrFlightDeparted
+	}
+	if !departure.After(now) {
+		return nil, ErrFlightDeparted
+	}
+	if ticket.RebookRequired {
+		quote.Involuntary = true
+		quote.Reason = RefundReasonScheduleChange
+		quote.Refund = quote.Paid
+		return quote, nil
+	}
+
+	quote.Reason = RefundReasonCustomer
+	quote.NoShow = !now.Before(quote.CutoffAt)
+	switch {
+	case !rule.Refundable:
+		quote.Penalty = quote.Paid
+	case quote.NoShow:
+		quote.Penalty = min(rule.NoShowPenalty, quote.Paid)
+	default:
+		quote.Penalty = min(rule.CancellationFee, quote.Paid)
+	}
+	quote.Refund = quote.Paid - quote.Penalty
+	return quote, nil
+}
+
+// processRefunds sends freshly recorded refunds to the gateway. Failures are left PENDING
+// for the periodic retry, since the cancellation itself has already committed.
+func (s *Service) processRefunds(ctx context.Context, refunds []*payment.Refund) {
+	for _, refund := range refunds {
+		if refund.Status != payment.RefundPending {
+			continue
+		}
+		processed, err := s.payService.ProcessRefund(ctx, refund.ID)
+		if err != nil {
+			s.log.Warn("Refund left pending", "refund_id", refund.ID, "error", err)
+			continue
+		}
+		*refund = *processed
 	}
+}
 
-	// 2. Get Bookings
+// GetUserBaggage returns all baggage for the current user across all bookings.
+func (s *Service) GetUserBaggage(ctx context.Context, userID, ticketID int64) ([]airportops.Baggage, error) {
+	// 1. Get Bookings
 	// If ticketID is provided, verify it belongs to the user
 	var targetBookingIDs []int64
 
@@ -166,15 +1906,15 @@ func (s *Service) GetUserBaggage(ctx context.Context, userID, ticketID int64) ([
 			return nil, err
 		}
 		if ticket == nil {
-			return nil, errors.New("ticket not found")
+			return nil, ErrTicketNotFound
 		}
-		if ticket.PassengerID != passProfile.ID {
-			return nil, errors.New("unauthorized to view baggage for this ticket")
+		if ticket.UserID != userID {
+			return nil, ErrTicketForbidden
 		}
 		targetBookingIDs = append(targetBookingIDs, ticketID)
 	} else {
 		// Fetch all bookings
-		bookings, err := s.repo.GetByPassengerID(ctx, passProfile.ID)
+		bookings, err := s.repo.GetByUserID(ctx, userID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get bookings: %w", err)
 		}
@@ -183,8 +1923,8 @@ func (s *Service) GetUserBaggage(ctx context.Context, userID, ticketID int64) ([
 		}
 	}
 
-	// 3. Aggregate Baggage
-	var allBaggage []airportops.Baggage
+	// 2. Aggregate Baggage
+	allBaggage := []airportops.Baggage{}
 	for _, bookingID := range targetBookingIDs {
 		bags, err := s.opsService.GetBaggageByTicketID(ctx, bookingID)
 		if err != nil {
@@ -195,3 +1935,22 @@ func (s *Service) GetUserBaggage(ctx context.Context, userID, ticketID int64) ([
 
 	return allBaggage, nil
 }
+
+// TrackBaggage returns the tracking history of a bag checked in on one of the user's tickets.
+// Other passengers' bags are reported as not found, so bag IDs cannot be probed.
+func (s *Service) TrackBaggage(ctx context.Context, userID, baggageID int64) (*airportops.BaggageTrack, error) {
+	track, err := s.opsService.TrackBaggage(ctx, baggageID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.repo.GetByID(ctx, track.Baggage.TicketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil || ticket.UserID != userID {
+		return nil, airportops.ErrBaggageNotFound
+	}
+
+	return track, nil
+}
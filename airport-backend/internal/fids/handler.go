@@ -0,0 +1,95 @@
+package fids
+
+import (
+	"airport-system/platform/apperror"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages HTTP requests for flight information display boards.
+type Handler struct {
+	Service *Service
+}
+
+// NewHandler creates a new FIDS handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{Service: service}
+}
+
+// Departures handles retrieving an airport's departures board.
+func (h *Handler) Departures(c *gin.Context) {
+	h.board(c, Departures)
+}
+
+// Arrivals handles retrieving an airport's arrivals board.
+func (h *Handler) Arrivals(c *gin.Context) {
+	h.board(c, Arrivals)
+}
+
+func (h *Handler) board(c *gin.Context, direction string) {
+	var params BoardParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	board, err := h.Service.Board(c.Request.Context(), direction, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// Stream handles following an airport's boards as server-sent events. A snapshot event carries
+// each board, then a flight event carries every change to a flight on it. A reset event means
+// changes may have been missed and the boards should be reloaded. The stream ends if the client
+// falls too far behind; reconnecting starts over with fresh snapshots.
+func (h *Handler) Stream(c *gin.Context) {
+	var params StreamParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	sub, boards, err := h.Service.Subscribe(c.Request.Context(), params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer h.Service.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Stops proxies such as nginx from buffering events
+	c.Status(http.StatusOK)
+	for i := range boards {
+		c.SSEvent(EventSnapshot, boards[i])
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case u, ok := <-sub.Updates:
+			if !ok {
+				return
+			}
+			c.SSEvent(u.Event, u)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			// A comment keeps idle connections from being closed by proxies
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package fids
+
+import "time"
+
+// Board directions.
+const (
+	Departures = "departures"
+	Arrivals   = "arrivals"
+)
+
+// Board windows. Boards show flights from an hour before now unless asked otherwise.
+const (
+	DefaultLookback = time.Hour
+	DefaultWindow   = 12 * time.Hour
+	MaxWindow       = 48 * time.Hour
+	DefaultLimit    = 100
+)
+
+// HeartbeatInterval is how often an idle stream sends a comment to keep proxies from closing it.
+const HeartbeatInterval = 25 * time.Second
+
+// Stream events.
+const (
+	EventSnapshot = "snapshot" // The board as it is when the stream opens
+	EventFlight   = "flight"   // A flight on the board changed or was added
+	EventReset    = "reset"    // Changes may have been missed; reload the board
+)
+
+// BoardFlight is one line of a departures or arrivals board.
+type BoardFlight struct {
+	FlightID      int64      `json:"flight_id"`
+	FlightNo      string     `json:"flight_no"`
+	Origin        string     `json:"origin"`
+	Destination   string     `json:"destination"`
+	City          string     `json:"city"`           // City of the destination on departures and of the origin on arrivals; empty if unknown
+	ScheduledTime time.Time  `json:"scheduled_time"` // Departure on departures, arrival on arrivals
+	EstimatedTime *time.Time `json:"estimated_time,omitempty"`
+	Gate          *string    `json:"gate"` // Code of the assigned gate, if any
+	Status        string     `json:"status"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Board lists the flights leaving or reaching an airport within a time window, in scheduled order.
+type Board struct {
+	Airport   string        `json:"airport"`
+	Timezone  string        `json:"timezone"` // Airport's IANA time zone, for showing local times
+	Direction string        `json:"direction"`
+	From      time.Time     `json:"from"`
+	To        time.Time     `json:"to"`
+	Flights   []BoardFlight `json:"flights"`
+}
+
+// BoardParams defines the airport and time window of a board.
+type BoardParams struct {
+	Airport string `form:"airport" binding:"required,len=3"`
+	From    string `form:"from"`                                    // RFC3339; DefaultLookback before now otherwise
+	To      string `form:"to"`                                      // RFC3339; DefaultWindow after from otherwise, at most MaxWindow
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=500"` // DefaultLimit otherwise
+}
+
+// StreamParams defines the boards a stream follows.
+type StreamParams struct {
+	Airport   string `form:"airport" binding:"required,len=3"`
+	Direction string `form:"direction"` // departures or arrivals; both otherwise
+}
+
+// Update is a change pushed to a stream.
+type Update struct {
+	Event     string       `json:"-"`
+	Direction string       `json:"direction,omitempty"`
+	Flight    *BoardFlight `json:"flight,omitempty"`
+}
+
+// boardQuery selects one board's flights.
+type boardQuery struct {
+	airport   string
+	direction string
+	from, to  time.Time
+	limit     int
+}
@@ -0,0 +1,85 @@
+package fids
+
+import (
+	"airport-system/platform/database"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Repository handles database queries for flight information boards.
+type Repository struct {
+	DB *sql.DB
+}
+
+// NewRepository creates a new FIDS repository.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{DB: db}
+}
+
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+// boardColumns are the flight columns a board reads in each direction: the airport the board
+// is for, the airport at the other end, and the scheduled and estimated times shown.
+var boardColumns = map[string]struct{ airport, other, scheduled, estimated string }{
+	Departures: {"f.origin", "f.destination", "f.departure_time", "f.estimated_departure_time"},
+	Arrivals:   {"f.destination", "f.origin", "f.arrival_time", "f.estimated_arrival_time"},
+}
+
+// boardSelect selects board lines in a direction; scanBoardFlight reads them.
+func boardSelect(direction string) string {
+	c := boardColumns[direction]
+	return fmt.Sprintf(`
+		SELECT f.id, f.flight_no, f.origin, f.destination, COALESCE(a.city, ''), %[2]s, %[3]s, g.code, f.status, f.updated_at
+		FROM flights f
+		LEFT JOIN airports a ON a.iata_code = %[1]s
+		LEFT JOIN gates g ON g.id = f.gate_id`, c.other, c.scheduled, c.estimated)
+}
+
+func scanBoardFlight(row interface{ Scan(dest ...any) error }, f *BoardFlight) error {
+	return row.Scan(&f.FlightID, &f.FlightNo, &f.Origin, &f.Destination, &f.City,
+		&f.ScheduledTime, &f.EstimatedTime, &f.Gate, &f.Status, &f.UpdatedAt)
+}
+
+// ListBoard returns the flights of a board whose scheduled or estimated time falls in its window.
+func (r *Repository) ListBoard(ctx context.Context, q boardQuery) ([]BoardFlight, error) {
+	c := boardColumns[q.direction]
+	query := boardSelect(q.direction) + fmt.Sprintf(`
+		WHERE %[1]s = $1
+		  AND ((%[2]s >= $2 AND %[2]s < $3) OR (%[3]s >= $2 AND %[3]s < $3))
+		ORDER BY %[2]s, f.flight_no, f.id
+		LIMIT $4`, c.airport, c.scheduled, c.estimated)
+	rows, err := r.executor(ctx).QueryContext(ctx, query, q.airport, q.from, q.to, q.limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list board: %w", err)
+	}
+	defer rows.Close()
+
+	flights := []BoardFlight{}
+	for rows.Next() {
+		var f BoardFlight
+		if err := scanBoardFlight(rows, &f); err != nil {
+			return nil, fmt.Errorf("failed to scan board flight: %w", err)
+		}
+		flights = append(flights, f)
+	}
+	return flights, rows.Err()
+}
+
+// GetBoardFlight returns a flight as a line of the board in a direction, or nil if it does not exist.
+func (r *Repository) GetBoardFlight(ctx context.Context, direction string, flightID int64) (*BoardFlight, error) {
+	var f BoardFlight
+	err := scanBoardFlight(r.executor(ctx).QueryRowContext(ctx, boardSelect(direction)+` WHERE f.id = $1`, flightID), &f)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get board flight: %w", err)
+	}
+	return &f, nil
+}
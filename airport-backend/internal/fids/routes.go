@@ -0,0 +1,15 @@
+package fids
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes sets up the FIDS routes. Boards are public, for terminal screens.
+func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	fidsGroup := r.Group("/fids")
+	{
+		fidsGroup.GET("/departures", h.Departures)
+		fidsGroup.GET("/arrivals", h.Arrivals)
+		fidsGroup.GET("/stream", h.Stream)
+	}
+}
@@ -0,0 +1,211 @@
+package fids
+
+import (
+	"airport-system/internal/airport"
+	"airport-system/platform/apperror"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrInvalidBoard is returned when a board or stream request fails validation.
+	ErrInvalidBoard = apperror.Validation("invalid_board", "invalid flight information board request")
+)
+
+// Service handles business logic for flight information display boards.
+type Service struct {
+	repo           *Repository
+	airportService *airport.Service
+	hub            *hub
+	log            *slog.Logger
+}
+
+// NewService creates a new FIDS service.
+func NewService(repo *Repository, airportService *airport.Service, log *slog.Logger) *Service {
+	return &Service{
+		repo:           repo,
+		airportService: airportService,
+		hub:            newHub(),
+		log:            log,
+	}
+}
+
+// Board returns the departures or arrivals board of an airport. A flight is on the board if its
+// scheduled or estimated time falls in the window, and is listed in scheduled order.
+func (s *Service) Board(ctx context.Context, direction string, params BoardParams) (*Board, error) {
+	a, err := s.airportService.GetAirport(ctx, params.Airport)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Now().Add(-DefaultLookback).Truncate(time.Minute)
+	if params.From != "" {
+		if from, err = time.Parse(time.RFC3339, params.From); err != nil {
+			return nil, fmt.Errorf("%w: invalid from format (expected RFC3339)", ErrInvalidBoard)
+		}
+	}
+	to := from.Add(DefaultWindow)
+	if params.To != "" {
+		if to, err = time.Parse(time.RFC3339, params.To); err != nil {
+			return nil, fmt.Errorf("%w: invalid to format (expected RFC3339)", ErrInvalidBoard)
+		}
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrInvalidBoard)
+	}
+	if to.Sub(from) > MaxWindow {
+		return nil, fmt.Errorf("%w: the window is longer than %d hours", ErrInvalidBoard, int(MaxWindow.Hours()))
+	}
+	limit := params.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	flights, err := s.repo.ListBoard(ctx, boardQuery{airport: a.IATACode, direction: direction, from: from, to: to, limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return &Board{
+		Airport:   a.IATACode,
+		Timezone:  a.Timezone,
+		Direction: direction,
+		From:      from,
+		To:        to,
+		Flights:   flights,
+	}, nil
+}
+
+// Subscribe starts following the boards of an airport and returns the subscription with a
+// snapshot of each board over the default window. The snapshot is read after subscribing, so
+// no change is missed in between. Callers Unsubscribe when the stream ends.
+func (s *Service) Subscribe(ctx context.Context, params StreamParams) (*Subscription, []Board, error) {
+	var directions []string
+	switch params.Direction {
+	case "":
+		directions = []string{Departures, Arrivals}
+	case Departures, Arrivals:
+		directions = []string{params.Direction}
+	default:
+		return nil, nil, fmt.Errorf("%w: direction must be %s or %s", ErrInvalidBoard, Departures, Arrivals)
+	}
+	a, err := s.airportService.GetAirport(ctx, params.Airport)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sub := s.hub.subscribe(a.IATACode, directions)
+	boards := make([]Board, 0, len(directions))
+	for _, direction := range directions {
+		board, err := s.Board(ctx, direction, BoardParams{Airport: a.IATACode})
+		if err != nil {
+			s.hub.unsubscribe(sub)
+			return nil, nil, err
+		}
+		boards = append(boards, *board)
+	}
+	return sub, boards, nil
+}
+
+// Unsubscribe stops a subscription.
+func (s *Service) Unsubscribe(sub *Subscription) {
+	s.hub.unsubscribe(sub)
+}
+
+// Listen relays the flight and gate assignment changes the database announces to the streams
+// following the flights' airports, until ctx is done, when the streams are ended. The connection is re-established on its
+// own if it drops, after which streams are told to reload their boards, and listening is
+// retried with backoff if the database is not ready for it at startup.
+func (s *Service) Listen(ctx context.Context, dsn string) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			s.log.Warn("FIDS listener connection problem", "error", err)
+		}
+	})
+	defer listener.Close()
+	defer s.hub.close()
+	if !s.listen(ctx, listener) {
+		return
+	}
+
+	ping := time.NewTicker(time.Minute)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				// Reconnected: notifications sent while disconnected are lost
+				s.hub.reset()
+				continue
+			}
+			flightID, err := strconv.ParseInt(strings.TrimSpace(n.Extra), 10, 64)
+			if err != nil {
+				s.log.Warn("Ignoring malformed flight change notification", "payload", n.Extra)
+				continue
+			}
+			if err := s.publish(ctx, flightID); err != nil {
+				s.log.Error("Failed to publish flight change", "flight_id", flightID, "error", err)
+			}
+		case <-ping.C:
+			// Detects a dead connection that would otherwise go unnoticed while idle
+			go listener.Ping()
+		}
+	}
+}
+
+// listen subscribes the listener to flight changes, retrying with backoff until it succeeds or
+// ctx is done, and reports whether it succeeded.
+func (s *Service) listen(ctx context.Context, listener *pq.Listener) bool {
+	backoff := time.Second
+	for {
+		// Listen waits for the connection to come up, so it is raced against ctx. Closing the
+		// listener on return releases it.
+		result := make(chan error, 1)
+		go func() {
+			result <- listener.Listen(changeChannel)
+		}()
+		select {
+		case <-ctx.Done():
+			return false
+		case err := <-result:
+			if err == nil || errors.Is(err, pq.ErrChannelAlreadyOpen) {
+				return true
+			}
+			s.log.Warn("Failed to listen for flight changes, retrying", "error", err, "retry_in", backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, time.Minute)
+	}
+}
+
+// publish pushes a changed flight to the streams following its departure or arrival board.
+func (s *Service) publish(ctx context.Context, flightID int64) error {
+	f, err := s.repo.GetBoardFlight(ctx, Departures, flightID)
+	if err != nil || f == nil {
+		return err
+	}
+	if s.hub.follows(f.Origin, Departures) {
+		s.hub.publish(f.Origin, Update{Event: EventFlight, Direction: Departures, Flight: f})
+	}
+	if s.hub.follows(f.Destination, Arrivals) {
+		arrival, err := s.repo.GetBoardFlight(ctx, Arrivals, flightID)
+		if err != nil || arrival == nil {
+			return err
+		}
+		s.hub.publish(arrival.Destination, Update{Event: EventFlight, Direction: Arrivals, Flight: arrival})
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package fids
+
+import (
+	"slices"
+	"sync"
+)
+
+// changeChannel is the PostgreSQL notification channel on which flight and gate assignment
+// changes announce the flight's ID.
+const changeChannel = "fids_changes"
+
+// subscriberBuffer is how many updates a stream may fall behind before it is dropped.
+const subscriberBuffer = 64
+
+// Subscription follows the boards of one airport. Updates is closed if the stream falls too
+// far behind, in which case the screen should reconnect for a fresh snapshot.
+type Subscription struct {
+	Updates <-chan Update
+
+	airport    string
+	directions []string
+	updates    chan Update
+}
+
+// hub fans board updates out to the streams following each airport.
+type hub struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subs: map[*Subscription]struct{}{}}
+}
+
+func (h *hub) subscribe(airport string, directions []string) *Subscription {
+	updates := make(chan Update, subscriberBuffer)
+	sub := &Subscription{Updates: updates, airport: airport, directions: directions, updates: updates}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe removes a stream and closes its updates, unless it was already dropped.
+func (h *hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(sub)
+}
+
+// drop removes a stream and closes its updates. Callers hold h.mu.
+func (h *hub) drop(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.updates)
+	}
+}
+
+// follows reports whether any stream follows the airport's board in the direction.
+func (h *hub) follows(airport, direction string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if sub.airport == airport && slices.Contains(sub.directions, direction) {
+			return true
+		}
+	}
+	return false
+}
+
+// publish sends an update of the airport's board to the streams following it, dropping those
+// too far behind to take it.
+func (h *hub) publish(airport string, u Update) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if sub.airport == airport && slices.Contains(sub.directions, u.Direction) {
+			h.send(sub, u)
+		}
+	}
+}
+
+// close ends every stream.
+func (h *hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		h.drop(sub)
+	}
+}
+
+// reset tells every stream that changes may have been missed.
+func (h *hub) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		h.send(sub, Update{Event: EventReset})
+	}
+}
+
+// send queues an update without blocking. Callers hold h.mu.
+func (h *hub) send(sub *Subscription, u Update) {
+	select {
+	case sub.updates <- u:
+	default:
+		h.drop(sub)
+	}
+}
@@ -0,0 +1,134 @@
+package fleet
+
+import (
+	"airport-system/internal/auth"
+	"airport-system/platform/apperror"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages HTTP requests for aircraft types and aircraft.
+type Handler struct {
+	Service *Service
+}
+
+// NewHandler creates a new fleet handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{Service: service}
+}
+
+// CreateType handles aircraft type registration (ADMIN only).
+func (h *Handler) CreateType(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	var req CreateAircraftTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	t, err := h.Service.CreateType(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, t)
+}
+
+// ListTypes lists all aircraft types (STAFF, ADMIN).
+func (h *Handler) ListTypes(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleStaff, auth.RoleAdmin) {
+		return
+	}
+
+	types, err := h.Service.ListTypes(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types)
+}
+
+// CreateAircraft handles aircraft registration (ADMIN only).
+func (h *Handler) CreateAircraft(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	var req CreateAircraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	aircraft, err := h.Service.CreateAircraft(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, aircraft)
+}
+
+// ListAircraft lists the fleet (STAFF, ADMIN).
+func (h *Handler) ListAircraft(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleStaff, auth.RoleAdmin) {
+		return
+	}
+
+	aircraft, err := h.Service.ListAircraft(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, aircraft)
+}
+
+// GetAircraft handles retrieving an aircraft with its seat configuration (STAFF, ADMIN).
+func (h *Handler) GetAircraft(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleStaff, auth.RoleAdmin) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	aircraft, err := h.Service.GetAircraft(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, aircraft)
+}
+
+// RetireAircraft handles taking an aircraft out of service (ADMIN only).
+func (h *Handler) RetireAircraft(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	aircraft, err := h.Service.RetireAircraft(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, aircraft)
+}
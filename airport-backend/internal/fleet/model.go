@@ -0,0 +1,50 @@
+package fleet
+
+import "time"
+
+// Aircraft statuses. Only ACTIVE aircraft can be assigned to flights.
+const (
+	AircraftActive  = "ACTIVE"
+	AircraftRetired = "RETIRED"
+)
+
+// AircraftType is an aircraft model, identified by its ICAO type designator.
+type AircraftType struct {
+	ID           int64     `json:"id"`
+	Code         string    `json:"code"` // e.g. "A320"
+	Name         string    `json:"name"`
+	Manufacturer string    `json:"manufacturer"`
+	MaxBaggageKg float64   `json:"max_baggage_kg"` // Checked baggage the hold takes
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Aircraft is an airframe in the fleet. Its cabin layout is its seat configuration.
+type Aircraft struct {
+	ID             int64          `json:"id"`
+	TailNumber     string         `json:"tail_number"`
+	AircraftTypeID int64          `json:"aircraft_type_id"`
+	TypeCode       string         `json:"type_code"`
+	MaxBaggageKg   float64        `json:"max_baggage_kg"`
+	CabinLayoutID  int64          `json:"cabin_layout_id"`
+	LayoutName     string         `json:"layout_name"`
+	TotalSeats     int            `json:"total_seats"`
+	SeatConfig     map[string]int `json:"seat_config"` // Sellable seats per cabin
+	Status         string         `json:"status"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// CreateAircraftTypeRequest defines the body for registering an aircraft type.
+type CreateAircraftTypeRequest struct {
+	Code         string  `json:"code" binding:"required,min=2,max=4"`
+	Name         string  `json:"name" binding:"required"`
+	Manufacturer string  `json:"manufacturer" binding:"required"`
+	MaxBaggageKg float64 `json:"max_baggage_kg" binding:"required,gt=0"`
+}
+
+// CreateAircraftRequest defines the body for registering an aircraft.
+type CreateAircraftRequest struct {
+	TailNumber     string `json:"tail_number" binding:"required,max=10"`
+	AircraftTypeID int64  `json:"aircraft_type_id" binding:"required"`
+	CabinLayoutID  int64  `json:"cabin_layout_id" binding:"required"`
+}
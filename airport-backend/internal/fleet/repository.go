@@ -0,0 +1,188 @@
+package fleet
+
+import (
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrTypeExists is returned when registering an aircraft type code twice.
+	ErrTypeExists = apperror.Conflict("aircraft_type_exists", "aircraft type already exists")
+	// ErrTailExists is returned when registering a tail number twice.
+	ErrTailExists = apperror.Conflict("tail_number_exists", "an aircraft with this tail number already exists")
+)
+
+// Repository handles database interactions for aircraft types and aircraft.
+type Repository struct {
+	DB *sql.DB
+}
+
+// NewRepository creates a new fleet repository.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{DB: db}
+}
+
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+// CreateType inserts a new aircraft type.
+func (r *Repository) CreateType(ctx context.Context, t *AircraftType) error {
+	query := `
+		INSERT INTO aircraft_types (code, name, manufacturer, max_baggage_kg, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query, t.Code, t.Name, t.Manufacturer, t.MaxBaggageKg).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrTypeExists
+		}
+		return fmt.Errorf("failed to create aircraft type: %w", err)
+	}
+	return nil
+}
+
+// ListTypes returns all aircraft types.
+func (r *Repository) ListTypes(ctx context.Context) ([]AircraftType, error) {
+	query := `SELECT id, code, name, manufacturer, max_baggage_kg, created_at FROM aircraft_types ORDER BY code`
+	rows, err := r.executor(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aircraft types: %w", err)
+	}
+	defer rows.Close()
+
+	types := []AircraftType{}
+	for rows.Next() {
+		var t AircraftType
+		if err := rows.Scan(&t.ID, &t.Code, &t.Name, &t.Manufacturer, &t.MaxBaggageKg, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan aircraft type: %w", err)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// GetType retrieves an aircraft type by ID.
+func (r *Repository) GetType(ctx context.Context, id int64) (*AircraftType, error) {
+	query := `SELECT id, code, name, manufacturer, max_baggage_kg, created_at FROM aircraft_types WHERE id = $1`
+	var t AircraftType
+	err := r.executor(ctx).QueryRowContext(ctx, query, id).Scan(&t.ID, &t.Code, &t.Name, &t.Manufacturer, &t.MaxBaggageKg, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get aircraft type: %w", err)
+	}
+	return &t, nil
+}
+
+// aircraftColumns and aircraftJoins select aircraft with their type and layout names.
+const aircraftColumns = `a.id, a.tail_number, a.aircraft_type_id, at.code, at.max_baggage_kg, a.cabin_layout_id, cl.name, a.status, a.created_at, a.updated_at`
+
+const aircraftJoins = `
+	FROM aircraft a
+	JOIN aircraft_types at ON at.id = a.aircraft_type_id
+	JOIN cabin_layouts cl ON cl.id = a.cabin_layout_id`
+
+func scanAircraft(row interface{ Scan(...any) error }, a *Aircraft) error {
+	return row.Scan(
+		&a.ID, &a.TailNumber, &a.AircraftTypeID, &a.TypeCode, &a.MaxBaggageKg, &a.CabinLayoutID, &a.LayoutName,
+		&a.Status, &a.CreatedAt, &a.UpdatedAt,
+	)
+}
+
+// CreateAircraft inserts a new aircraft.
+func (r *Repository) CreateAircraft(ctx context.Context, a *Aircraft) error {
+	query := `
+		INSERT INTO aircraft (tail_number, aircraft_type_id, cabin_layout_id, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query, a.TailNumber, a.AircraftTypeID, a.CabinLayoutID, a.Status).Scan(&a.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrTailExists
+		}
+		return fmt.Errorf("failed to create aircraft: %w", err)
+	}
+	return nil
+}
+
+// ListAircraft returns all aircraft, active ones first.
+func (r *Repository) ListAircraft(ctx context.Context) ([]Aircraft, error) {
+	query := `SELECT ` + aircraftColumns + aircraftJoins + ` ORDER BY a.status, a.tail_number`
+	rows, err := r.executor(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aircraft: %w", err)
+	}
+	defer rows.Close()
+
+	aircraft := []Aircraft{}
+	for rows.Next() {
+		var a Aircraft
+		if err := scanAircraft(rows, &a); err != nil {
+			return nil, fmt.Errorf("failed to scan aircraft: %w", err)
+		}
+		aircraft = append(aircraft, a)
+	}
+	return aircraft, nil
+}
+
+// GetAircraft retrieves an aircraft by ID.
+func (r *Repository) GetAircraft(ctx context.Context, id int64) (*Aircraft, error) {
+	return r.getAircraft(ctx, `SELECT `+aircraftColumns+aircraftJoins+` WHERE a.id = $1`, id)
+}
+
+// GetAircraftForUpdate retrieves an aircraft by ID and locks it for the current transaction.
+func (r *Repository) GetAircraftForUpdate(ctx context.Context, id int64) (*Aircraft, error) {
+	return r.getAircraft(ctx, `SELECT `+aircraftColumns+aircraftJoins+` WHERE a.id = $1 FOR UPDATE OF a`, id)
+}
+
+func (r *Repository) getAircraft(ctx context.Context, query string, args ...any) (*Aircraft, error) {
+	var a Aircraft
+	if err := scanAircraft(r.executor(ctx).QueryRowContext(ctx, query, args...), &a); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get aircraft: %w", err)
+	}
+	return &a, nil
+}
+
+// SetAircraftStatus updates an aircraft's status.
+func (r *Repository) SetAircraftStatus(ctx context.Context, id int64, status string) error {
+	query := `UPDATE aircraft SET status = $2, updated_at = NOW() WHERE id = $1`
+	if _, err := r.executor(ctx).ExecContext(ctx, query, id, status); err != nil {
+		return fmt.Errorf("failed to update aircraft: %w", err)
+	}
+	return nil
+}
+
+// CountUpcomingFlights counts the flights assigned to an aircraft that have not yet left.
+func (r *Repository) CountUpcomingFlights(ctx context.Context, aircraftID int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM flights
+		WHERE aircraft_id = $1 AND status IN ('SCHEDULED', 'CHECK_IN_OPEN', 'BOARDING', 'DELAYED')
+	`
+	var n int
+	if err := r.executor(ctx).QueryRowContext(ctx, query, aircraftID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count aircraft flights: %w", err)
+	}
+	return n, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
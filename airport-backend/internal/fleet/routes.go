@@ -0,0 +1,24 @@
+package fleet
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes sets up the aircraft type and aircraft routes.
+func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	typeGroup := r.Group("/aircraft-types")
+	typeGroup.Use(authMiddleware)
+	{
+		typeGroup.POST("", h.CreateType)
+		typeGroup.GET("", h.ListTypes)
+	}
+
+	aircraftGroup := r.Group("/aircraft")
+	aircraftGroup.Use(authMiddleware)
+	{
+		aircraftGroup.POST("", h.CreateAircraft)
+		aircraftGroup.GET("", h.ListAircraft)
+		aircraftGroup.GET("/:id", h.GetAircraft)
+		aircraftGroup.POST("/:id/retire", h.RetireAircraft)
+	}
+}
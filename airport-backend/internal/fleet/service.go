@@ -0,0 +1,192 @@
+package fleet
+
+import (
+	"airport-system/internal/seating"
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+var (
+	// ErrTypeNotFound is returned when the aircraft type does not exist.
+	ErrTypeNotFound = apperror.NotFound("aircraft_type_not_found", "aircraft type not found")
+	// ErrAircraftNotFound is returned when the aircraft does not exist.
+	ErrAircraftNotFound = apperror.NotFound("aircraft_not_found", "aircraft not found")
+	// ErrInvalidAircraft is returned when an aircraft or aircraft type fails validation.
+	ErrInvalidAircraft = apperror.Validation("invalid_aircraft", "invalid aircraft")
+	// ErrAircraftRetired is returned when using or retiring an aircraft that is already retired.
+	ErrAircraftRetired = apperror.Conflict("aircraft_retired", "aircraft is retired")
+	// ErrAircraftInUse is returned when retiring an aircraft that still has flights to operate.
+	ErrAircraftInUse = apperror.Conflict("aircraft_in_use", "aircraft is assigned to upcoming flights")
+)
+
+// Service handles business logic for the aircraft fleet.
+type Service struct {
+	repo        *Repository
+	seatService *seating.Service
+	txManager   database.TxManager
+	log         *slog.Logger
+}
+
+// NewService creates a new fleet service.
+func NewService(repo *Repository, seatService *seating.Service, txManager database.TxManager, log *slog.Logger) *Service {
+	return &Service{
+		repo:        repo,
+		seatService: seatService,
+		txManager:   txManager,
+		log:         log,
+	}
+}
+
+// CreateType registers an aircraft type.
+func (s *Service) CreateType(ctx context.Context, req CreateAircraftTypeRequest) (*AircraftType, error) {
+	t := &AircraftType{
+		Code:         strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:         strings.TrimSpace(req.Name),
+		Manufacturer: strings.TrimSpace(req.Manufacturer),
+		MaxBaggageKg: req.MaxBaggageKg,
+	}
+	for _, ch := range t.Code {
+		if (ch < 'A' || ch > 'Z') && (ch < '0' || ch > '9') {
+			return nil, fmt.Errorf("%w: type code must be letters and digits", ErrInvalidAircraft)
+		}
+	}
+	if err := s.repo.CreateType(ctx, t); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Aircraft type registered", "type_id", t.ID, "code", t.Code)
+	return t, nil
+}
+
+// ListTypes returns all aircraft types.
+func (s *Service) ListTypes(ctx context.Context) ([]AircraftType, error) {
+	return s.repo.ListTypes(ctx)
+}
+
+// GetType retrieves an aircraft type by ID.
+func (s *Service) GetType(ctx context.Context, id int64) (*AircraftType, error) {
+	t, err := s.repo.GetType(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrTypeNotFound
+	}
+	return t, nil
+}
+
+// CreateAircraft registers an aircraft of a known type with the cabin layout it is fitted with.
+func (s *Service) CreateAircraft(ctx context.Context, req CreateAircraftRequest) (*Aircraft, error) {
+	tail := strings.ToUpper(strings.TrimSpace(req.TailNumber))
+	if tail == "" {
+		return nil, fmt.Errorf("%w: tail_number is required", ErrInvalidAircraft)
+	}
+	t, err := s.GetType(ctx, req.AircraftTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.seatService.GetLayout(ctx, req.CabinLayoutID); err != nil {
+		return nil, err
+	}
+
+	a := &Aircraft{
+		TailNumber:     tail,
+		AircraftTypeID: t.ID,
+		CabinLayoutID:  req.CabinLayoutID,
+		Status:         AircraftActive,
+	}
+	if err := s.repo.CreateAircraft(ctx, a); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Aircraft registered", "aircraft_id", a.ID, "tail_number", a.TailNumber, "type", t.Code)
+	return s.GetAircraft(ctx, a.ID)
+}
+
+// ListAircraft returns the fleet with each aircraft's seat configuration.
+func (s *Service) ListAircraft(ctx context.Context) ([]Aircraft, error) {
+	aircraft, err := s.repo.ListAircraft(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range aircraft {
+		if err := s.withSeats(ctx, &aircraft[i]); err != nil {
+			return nil, err
+		}
+	}
+	return aircraft, nil
+}
+
+// GetAircraft returns an aircraft with its seat configuration.
+func (s *Service) GetAircraft(ctx context.Context, id int64) (*Aircraft, error) {
+	a, err := s.repo.GetAircraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrAircraftNotFound
+	}
+	if err := s.withSeats(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ActiveAircraft returns an aircraft that can be assigned to flights, locking it so it cannot
+// be retired before the caller's transaction ends.
+func (s *Service) ActiveAircraft(ctx context.Context, id int64) (*Aircraft, error) {
+	a, err := s.repo.GetAircraftForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrAircraftNotFound
+	}
+	if a.Status != AircraftActive {
+		return nil, fmt.Errorf("%w: %s", ErrAircraftRetired, a.TailNumber)
+	}
+	if err := s.withSeats(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// RetireAircraft takes an aircraft out of service. Its upcoming flights must first be moved
+// to other aircraft.
+func (s *Service) RetireAircraft(ctx context.Context, id int64) (*Aircraft, error) {
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		a, err := s.ActiveAircraft(ctx, id)
+		if err != nil {
+			return err
+		}
+		n, err := s.repo.CountUpcomingFlights(ctx, id)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return fmt.Errorf("%w: %s has %d upcoming flights", ErrAircraftInUse, a.TailNumber, n)
+		}
+		return s.repo.SetAircraftStatus(ctx, id, AircraftRetired)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Aircraft retired", "aircraft_id", id)
+	return s.GetAircraft(ctx, id)
+}
+
+// withSeats fills in an aircraft's seat configuration from its cabin layout.
+func (s *Service) withSeats(ctx context.Context, a *Aircraft) error {
+	layout, err := s.seatService.GetLayout(ctx, a.CabinLayoutID)
+	if err != nil {
+		return err
+	}
+	a.TotalSeats = layout.SeatCount()
+	a.SeatConfig = layout.CabinSeats()
+	return nil
+}
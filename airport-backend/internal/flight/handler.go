@@ -2,12 +2,18 @@ package flight
 
 import (
 	"airport-system/internal/auth"
+	"airport-system/platform/apperror"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxImportSize caps the size of an uploaded schedule file.
+const maxImportSize = 32 << 20
+
 // Handler manages HTTP requests for flights.
 type Handler struct {
 	Service *Service
@@ -26,34 +32,91 @@ func (h *Handler) Create(c *gin.Context) {
 
 	var req CreateFlightParams
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest(err))
 		return
 	}
 
 	flight, err := h.Service.CreateFlight(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, flight)
 }
 
+// Import handles a bulk flight import from a CSV or SSIM file (ADMIN only). The file is taken
+// from the "file" field of a multipart form, or from the raw request body otherwise.
+func (h *Handler) Import(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	var params ImportParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	userID := c.GetInt64("userID")
+	result, err := h.Service.ImportFlights(c.Request.Context(), &userID, body, params.DryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // Search handles flight search.
 func (h *Handler) Search(c *gin.Context) {
 	var params SearchParams
 	if err := c.ShouldBindQuery(&params); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest(err))
 		return
 	}
 
-	flights, err := h.Service.SearchFlights(c.Request.Context(), params.Origin, params.Destination, params.Date)
+	result, err := h.Service.SearchFlights(c.Request.Context(), params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, flights)
+	c.JSON(http.StatusOK, result)
+}
+
+// SearchItineraries handles searching direct and connecting itineraries.
+func (h *Handler) SearchItineraries(c *gin.Context) {
+	var params ItineraryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	itineraries, err := h.Service.SearchItineraries(c.Request.Context(), params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, itineraries)
 }
 
 // GetByID handles getting a flight by ID.
@@ -61,19 +124,336 @@ func (h *Handler) GetByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		c.Error(apperror.ErrInvalidID)
 		return
 	}
 
 	flight, err := h.Service.GetByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	if flight == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "flight not found"})
+
+	c.JSON(http.StatusOK, flight)
+}
+
+// UpdateStatus handles flight status changes (STAFF, ADMIN).
+func (h *Handler) UpdateStatus(c *gin.Context) {
+	if !auth.RequireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	flight, err := h.Service.UpdateStatus(c.Request.Context(), id, c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, flight)
 }
+
+// GetStatusHistory handles listing a flight's status transitions (STAFF, ADMIN).
+func (h *Handler) GetStatusHistory(c *gin.Context) {
+	if !auth.RequireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	history, err := h.Service.GetStatusHistory(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetFares handles listing a flight's booking classes, fares and availability.
+func (h *Handler) GetFares(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	buckets, err := h.Service.GetFares(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, buckets)
+}
+
+// UpdateFares handles replacing a flight's fare buckets (ADMIN only).
+func (h *Handler) UpdateFares(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req UpdateFaresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	buckets, err := h.Service.UpdateFares(c.Request.Context(), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, buckets)
+}
+
+// AssignAircraft handles swapping the aircraft operating a flight (ADMIN only).
+func (h *Handler) AssignAircraft(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req AssignAircraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	change, err := h.Service.AssignAircraft(c.Request.Context(), id, c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// AssignLayout handles attaching a cabin layout to a flight (ADMIN only).
+func (h *Handler) AssignLayout(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req AssignLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	seatMap, err := h.Service.AssignLayout(c.Request.Context(), id, c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, seatMap)
+}
+
+// ListEquipmentChanges handles listing a flight's aircraft and layout changes (STAFF, ADMIN).
+func (h *Handler) ListEquipmentChanges(c *gin.Context) {
+	if !auth.RequireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	changes, err := h.Service.ListEquipmentChanges(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// ClearReview handles clearing a flight's schedule review flag (ADMIN only).
+func (h *Handler) ClearReview(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	flight, err := h.Service.ClearReview(c.Request.Context(), id, c.GetInt64("userID"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flight)
+}
+
+// CreateSchedule handles creating a recurring schedule (ADMIN only).
+func (h *Handler) CreateSchedule(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	change, err := h.Service.CreateSchedule(c.Request.Context(), c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, change)
+}
+
+// ListSchedules handles listing schedules (STAFF, ADMIN).
+func (h *Handler) ListSchedules(c *gin.Context) {
+	if !auth.RequireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	var params ListSchedulesParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	schedules, err := h.Service.ListSchedules(c.Request.Context(), params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// GetSchedule handles getting a schedule by ID (STAFF, ADMIN).
+func (h *Handler) GetSchedule(c *gin.Context) {
+	if !auth.RequireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	schedule, err := h.Service.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedule handles editing a schedule and its upcoming flights (ADMIN only).
+func (h *Handler) UpdateSchedule(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	change, err := h.Service.UpdateSchedule(c.Request.Context(), id, c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// ListScheduleFlights handles listing the flights generated from a schedule (STAFF, ADMIN).
+func (h *Handler) ListScheduleFlights(c *gin.Context) {
+	if !auth.RequireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var params ScheduleFlightsParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	flights, err := h.Service.ListScheduleFlights(c.Request.Context(), id, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flights)
+}
+
+// GenerateScheduledFlights handles rolling every current schedule forward now rather than
+// waiting for the background job (ADMIN only).
+func (h *Handler) GenerateScheduledFlights(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	created, err := h.Service.GenerateScheduledFlights(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"generated": created})
+}
@@ -1,6 +1,12 @@
 package flight
 
-import "time"
+import (
+	"airport-system/internal/seating"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
 
 // Flight represents a flight entity.
 type Flight struct {
@@ -17,6 +23,75 @@ type Flight struct {
 	UpdatedAt     time.Time `json:"updated_at"`
 	TotalSeats    int       `json:"total_seats"`
 	BasePrice     float64   `json:"base_price"`
+	AircraftID    *int64    `json:"aircraft_id,omitempty"`
+	CabinLayoutID *int64    `json:"cabin_layout_id,omitempty"`
+	ScheduleID    *int64    `json:"schedule_id,omitempty"`   // Schedule the flight was generated from
+	ScheduleDate  *string   `json:"schedule_date,omitempty"` // Operating day in the origin's local time, YYYY-MM-DD
+
+	// ReviewRequired is set when a schedule edit was not applied to the flight because it is
+	// booked or under way.
+	ReviewRequired bool `json:"review_required"`
+
+	EstimatedDepartureTime *time.Time `json:"estimated_departure_time,omitempty"`
+	EstimatedArrivalTime   *time.Time `json:"estimated_arrival_time,omitempty"`
+}
+
+// DefaultBasePrice is the base fare of flights created without one.
+const DefaultBasePrice = 32000.00
+
+// Flight statuses.
+const (
+	StatusScheduled   = "SCHEDULED"
+	StatusCheckInOpen = "CHECK_IN_OPEN"
+	StatusBoarding    = "BOARDING"
+	StatusDeparted    = "DEPARTED"
+	StatusArrived     = "ARRIVED"
+	StatusDelayed     = "DELAYED"
+	StatusDiverted    = "DIVERTED"
+	StatusCancelled   = "CANCELLED"
+)
+
+// statusTransitions lists the statuses a flight may move to from each status.
+// ARRIVED and CANCELLED are terminal.
+var statusTransitions = map[string][]string{
+	StatusScheduled:   {StatusCheckInOpen, StatusDelayed, StatusCancelled},
+	StatusCheckInOpen: {StatusBoarding, StatusDelayed, StatusCancelled},
+	StatusBoarding:    {StatusDeparted, StatusDelayed, StatusCancelled},
+	StatusDelayed:     {StatusDelayed, StatusScheduled, StatusCheckInOpen, StatusBoarding, StatusCancelled},
+	StatusDeparted:    {StatusArrived, StatusDiverted},
+	StatusDiverted:    {StatusDeparted, StatusArrived},
+}
+
+// CanTransition reports whether a flight may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidStatus reports whether status is a known flight status.
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusScheduled, StatusCheckInOpen, StatusBoarding, StatusDeparted,
+		StatusArrived, StatusDelayed, StatusDiverted, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// StatusChange is a recorded flight status transition.
+type StatusChange struct {
+	ID         int64     `json:"id"`
+	FlightID   int64     `json:"flight_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Version    int       `json:"version"` // Flight version after the change
+	ChangedBy  *int64    `json:"changed_by"`
+	Reason     string    `json:"reason"`
+	ChangedAt  time.Time `json:"changed_at"`
 }
 
 // CreateFlightParams defines the parameters for creating a new flight.
@@ -26,11 +101,522 @@ type CreateFlightParams struct {
 	Destination   string `json:"destination" binding:"required,len=3"`
 	DepartureTime string `json:"departure_time" binding:"required"` // Format: RFC3339
 	ArrivalTime   string `json:"arrival_time" binding:"required"`   // Format: RFC3339
+	AircraftID    *int64 `json:"aircraft_id"`                       // Optional: sets capacity and cabins from the aircraft's seat configuration
+}
+
+// Flight import formats. CSV files name their columns in a header row; SSIM files follow
+// chapter 7 of the IATA Standard Schedules Information Manual.
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatSSIM = "ssim"
+)
+
+// MaxImportFlights caps the flights one import may create or update, after SSIM periods are
+// expanded into single days.
+const MaxImportFlights = 20000
+
+// Import actions. A flight matches an existing one with the same flight number and origin
+// departing on the same local day.
+const (
+	ImportInsert    = "insert"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+)
+
+// ImportParams defines the options of a bulk flight import.
+type ImportParams struct {
+	DryRun bool `form:"dry_run"` // Validate and report without applying anything
+}
+
+// ImportResult reports what a bulk flight import did, or would do on a dry run. An import is
+// applied only if every row is valid; otherwise nothing changes.
+type ImportResult struct {
+	Format    string           `json:"format"`
+	DryRun    bool             `json:"dry_run"`
+	Applied   bool             `json:"applied"`
+	Inserted  int              `json:"inserted"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Skipped   int              `json:"skipped"` // SSIM operating days that have already departed
+	Flights   []ImportedFlight `json:"flights"`
+	Errors    []ImportError    `json:"errors"`
+}
+
+// ImportedFlight is a flight read from an import and what the import does with it.
+type ImportedFlight struct {
+	Line          int       `json:"line"`
+	Action        string    `json:"action"`    // insert, update or unchanged
+	FlightID      *int64    `json:"flight_id"` // Nil for inserts that were not applied
+	FlightNo      string    `json:"flight_no"`
+	Origin        string    `json:"origin"`
+	Destination   string    `json:"destination"`
+	DepartureTime time.Time `json:"departure_time"`
+	ArrivalTime   time.Time `json:"arrival_time"`
+}
+
+// ImportError is a row rejected during an import.
+type ImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// importRow is a flight read from an import file and the line it was read from.
+type importRow struct {
+	line   int
+	params CreateFlightParams
+}
+
+// UpdateStatusRequest defines the body for changing a flight's status.
+// Version must match the flight's current version (compare-and-swap).
+type UpdateStatusRequest struct {
+	Status                 string `json:"status" binding:"required"`
+	Version                int    `json:"version" binding:"required"`
+	Reason                 string `json:"reason"`                   // Required for DELAYED and DIVERTED
+	EstimatedDepartureTime string `json:"estimated_departure_time"` // Optional, RFC3339
+	EstimatedArrivalTime   string `json:"estimated_arrival_time"`   // Optional, RFC3339
+}
+
+// SignificantRetime is how far a flight's departure has to move for its passengers to be
+// offered rebooking or a full refund.
+const SignificantRetime = 3 * time.Hour
+
+// UpdateFlightRequest defines the body for retiming a flight, and optionally renumbering it.
+type UpdateFlightRequest struct {
+	FlightNo      string `json:"flight_no"`                         // Optional: the flight keeps its number otherwise
+	DepartureTime string `json:"departure_time" binding:"required"` // Format: RFC3339
+	ArrivalTime   string `json:"arrival_time" binding:"required"`   // Format: RFC3339
+	Version       int    `json:"version" binding:"required"`
+	Reason        string `json:"reason"` // Optional: passed on to the passengers notified
+}
+
+// CancelFlightRequest defines the body for cancelling a flight.
+type CancelFlightRequest struct {
+	Version int    `json:"version" binding:"required"`
+	Reason  string `json:"reason" binding:"required"`
 }
 
 // SearchParams defines criteria for searching flights.
 type SearchParams struct {
-	Origin      string `form:"origin"`
-	Destination string `form:"destination"`
-	Date        string `form:"date"` // Format: YYYY-MM-DD
+	Origin       string   `form:"origin"`
+	Destination  string   `form:"destination"`
+	Date         string   `form:"date"`                                    // Format: YYYY-MM-DD, in the origin's local time
+	MinPrice     *float64 `form:"min_price" binding:"omitempty,gte=0"`     // Lowest fare still on sale
+	MaxPrice     *float64 `form:"max_price" binding:"omitempty,gte=0"`     // Lowest fare still on sale
+	DepartAfter  string   `form:"depart_after"`                            // Format: HH:MM, in the origin's local time
+	DepartBefore string   `form:"depart_before"`                           // Format: HH:MM; before depart_after for windows spanning midnight
+	Status       string   `form:"status"`                                  // Comma-separated statuses
+	MinSeats     int      `form:"min_seats" binding:"omitempty,min=1"`     // Seats left on the flight
+	Sort         string   `form:"sort"`                                    // departure (default), price or duration
+	Limit        int      `form:"limit" binding:"omitempty,min=1,max=100"` // Defaults to DefaultSearchLimit
+	Cursor       string   `form:"cursor"`                                  // next_cursor of the previous page
+}
+
+// Search sort orders. Ties are broken by flight ID so pages never overlap.
+const (
+	SortDeparture = "departure"
+	SortPrice     = "price" // Sold-out flights last
+	SortDuration  = "duration"
+)
+
+// DefaultSearchLimit is the page size of searches that do not ask for one.
+const DefaultSearchLimit = 20
+
+// FlightResult is a flight found by a search, with its current availability.
+type FlightResult struct {
+	Flight
+	SeatsAvailable  int      `json:"seats_available"`
+	LowestFare      *float64 `json:"lowest_fare"` // Cheapest booking class still on sale; nil when sold out
+	DurationMinutes int      `json:"duration_minutes"`
+}
+
+// SearchResult is one page of search results.
+type SearchResult struct {
+	Flights    []FlightResult `json:"flights"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// searchQuery is a validated search. date, if set, is midnight of the day in the origin's time
+// zone, or in UTC when no origin is given.
+type searchQuery struct {
+	origin       string
+	destination  string
+	date         time.Time
+	minPrice     *float64
+	maxPrice     *float64
+	departAfter  string
+	departBefore string
+	statuses     []string
+	minSeats     int
+	sort         string
+	limit        int
+	after        *searchCursor
+}
+
+// searchCursor marks the last result of a page: the sort it was produced by, the result's sort
+// key as text and its flight ID.
+type searchCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"id"`
+}
+
+// Itinerary search limits.
+const (
+	MaxItineraryStops        = 2
+	MaxJourneyMinutes        = 48 * 60 // Longest journey searched or booked as one itinerary
+	DefaultMaxJourneyMinutes = 24 * 60
+	DefaultItineraryLimit    = 20
+)
+
+// bookableStatuses are the statuses a flight can still be sold, retimed or rebooked onto in.
+var bookableStatuses = []string{StatusScheduled, StatusCheckInOpen, StatusDelayed}
+
+// Bookable reports whether the flight is still open: it has not started boarding, left or been
+// cancelled.
+func (f *Flight) Bookable() bool {
+	return slices.Contains(bookableStatuses, f.Status)
+}
+
+// ItineraryParams defines criteria for searching connecting itineraries.
+type ItineraryParams struct {
+	Origin            string `form:"origin" binding:"required,len=3"`
+	Destination       string `form:"destination" binding:"required,len=3"`
+	Date              string `form:"date" binding:"required"`                                // Format: YYYY-MM-DD; the first leg departs on this day in the origin's local time
+	MaxStops          *int   `form:"max_stops" binding:"omitempty,min=0,max=2"`              // Defaults to MaxItineraryStops
+	MaxJourneyMinutes int    `form:"max_journey_minutes" binding:"omitempty,min=1,max=2880"` // First departure to last arrival; defaults to DefaultMaxJourneyMinutes
+	Seats             int    `form:"seats" binding:"omitempty,min=1,max=9"`                  // Seats needed on every leg; defaults to 1
+	Sort              string `form:"sort"`                                                   // duration (default) or price; ties go to the other, then the earlier departure
+	Limit             int    `form:"limit" binding:"omitempty,min=1,max=100"`                // Defaults to DefaultItineraryLimit
+}
+
+// Itinerary is a journey of one or more bookable legs, each departing from the airport the
+// previous one arrives at no sooner than its minimum connection time.
+type Itinerary struct {
+	Legs            []FlightResult `json:"legs"`
+	Connections     []Connection   `json:"connections"`
+	Stops           int            `json:"stops"`
+	DepartureTime   time.Time      `json:"departure_time"`
+	ArrivalTime     time.Time      `json:"arrival_time"`
+	DurationMinutes int            `json:"duration_minutes"` // First departure to last arrival, connections included
+	Price           float64        `json:"price"`            // Sum of the legs' lowest fares per traveller
+}
+
+// Connection is the change of flights between two legs of an itinerary.
+type Connection struct {
+	Airport        string `json:"airport"`
+	Minutes        int    `json:"minutes"`         // Time between arrival and onward departure
+	MinimumMinutes int    `json:"minimum_minutes"` // The airport's minimum connection time
+}
+
+// legQuery selects flights that can be sold as itinerary legs: bookable, with seats and a fare
+// left, departing from one of origins within [departFrom, departTo).
+type legQuery struct {
+	origins     []string
+	destination string // Empty matches any destination
+	departFrom  time.Time
+	departTo    time.Time
+	minSeats    int
+}
+
+// departure returns when the flight is expected to leave: its estimate if it has one.
+func (f *Flight) departure() time.Time {
+	if f.EstimatedDepartureTime != nil {
+		return *f.EstimatedDepartureTime
+	}
+	return f.DepartureTime
+}
+
+// arrival returns when the flight is expected to land: its estimate if it has one.
+func (f *Flight) arrival() time.Time {
+	if f.EstimatedArrivalTime != nil {
+		return *f.EstimatedArrivalTime
+	}
+	return f.ArrivalTime
+}
+
+// Cabins. Every booking class belongs to one cabin, and tickets record the cabin as their fare class.
+const (
+	CabinFirst    = seating.CabinFirst
+	CabinBusiness = seating.CabinBusiness
+	CabinEconomy  = seating.CabinEconomy
+)
+
+// BookingClass is a fare product sold within a cabin. Rank 1 is the cabin's highest class.
+type BookingClass struct {
+	Code           string  `json:"code"`
+	Cabin          string  `json:"cabin"`
+	Rank           int     `json:"rank"`
+	Name           string  `json:"name"`
+	FareMultiplier float64 `json:"fare_multiplier"` // Of the flight's base price, for new flights
+	DefaultShare   float64 `json:"default_share"`   // Percent of the cabin's seats allocated to new flights
+}
+
+// FareBucket is a flight's inventory of one booking class.
+type FareBucket struct {
+	BookingClass string    `json:"booking_class"`
+	Cabin        string    `json:"cabin"`
+	Rank         int       `json:"rank"`
+	Name         string    `json:"name"`
+	Allocation   int       `json:"allocation"`
+	Sold         int       `json:"sold"`
+	Fare         float64   `json:"fare"`
+	Closed       bool      `json:"closed"`    // Sells nothing; its seats stay open to higher classes
+	Available    int       `json:"available"` // Seats the class can still sell, including seats nested from lower classes
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ComputeAvailability sets Available on buckets sorted by cabin and rank. Higher classes may
+// sell seats allocated to lower ones but not the reverse, so a class can sell another seat
+// only while, for it and every class above it in the cabin, the seats sold at that rank or
+// below stay under the seats allocated at that rank or below.
+func ComputeAvailability(buckets []FareBucket) {
+	for start := 0; start < len(buckets); {
+		end := start
+		for end < len(buckets) && buckets[end].Cabin == buckets[start].Cabin {
+			end++
+		}
+		cabin := buckets[start:end]
+
+		// headroom[i] is what the classes from rank i down have allocated but not sold
+		headroom := make([]int, len(cabin)+1)
+		for i := len(cabin) - 1; i >= 0; i-- {
+			headroom[i] = headroom[i+1] + cabin[i].Allocation - cabin[i].Sold
+		}
+		limit := headroom[0]
+		for i := range cabin {
+			limit = min(limit, headroom[i])
+			cabin[i].Available = max(limit, 0)
+			if cabin[i].Closed {
+				cabin[i].Available = 0
+			}
+		}
+		start = end
+	}
+}
+
+// NestingFits reports whether the sold seats of buckets sorted by cabin and rank fit their
+// allocations, with higher classes occupying seats allocated to lower ones where needed.
+func NestingFits(buckets []FareBucket) bool {
+	for i := range buckets {
+		allocated, sold := 0, 0
+		for j := i; j < len(buckets) && buckets[j].Cabin == buckets[i].Cabin; j++ {
+			allocated += buckets[j].Allocation
+			sold += buckets[j].Sold
+		}
+		if sold > allocated {
+			return false
+		}
+	}
+	return true
+}
+
+// CheapestAvailable returns the lowest-ranked class of a cabin that can still sell a seat, or
+// nil if the cabin is sold out. Availability must have been computed.
+func CheapestAvailable(buckets []FareBucket, cabin string) *FareBucket {
+	var cheapest *FareBucket
+	for i := range buckets {
+		b := &buckets[i]
+		if b.Cabin == cabin && b.Available > 0 && (cheapest == nil || b.Rank > cheapest.Rank) {
+			cheapest = b
+		}
+	}
+	return cheapest
+}
+
+// FindBucket returns the bucket of a booking class, or nil if the flight does not sell it.
+func FindBucket(buckets []FareBucket, bookingClass string) *FareBucket {
+	for i := range buckets {
+		if buckets[i].BookingClass == bookingClass {
+			return &buckets[i]
+		}
+	}
+	return nil
+}
+
+// ResizeBuckets fits fare buckets to new cabin sizes after an equipment change. Each cabin's
+// classes keep their share of its seats, with rounding leftovers going to the top class; a
+// cabin new to the flight gets the classes' default shares, priced from basePrice. Classes of
+// a cabin that is gone are dropped unless they have sold seats, in which case they stay with
+// no allocation until those passengers are moved.
+func ResizeBuckets(buckets []FareBucket, classes []BookingClass, seats map[string]int, basePrice float64) []FareBucket {
+	byCabin := make(map[string][]FareBucket)
+	for _, b := range buckets {
+		byCabin[b.Cabin] = append(byCabin[b.Cabin], b)
+	}
+
+	resized := []FareBucket{}
+	for _, cabin := range []string{CabinFirst, CabinBusiness, CabinEconomy} {
+		n := seats[cabin]
+		current := byCabin[cabin]
+		sort.Slice(current, func(i, j int) bool { return current[i].Rank < current[j].Rank })
+
+		if n == 0 {
+			for _, b := range current {
+				if b.Sold > 0 {
+					b.Allocation = 0
+					resized = append(resized, b)
+				}
+			}
+			continue
+		}
+
+		if len(current) == 0 {
+			for _, bc := range classes {
+				if bc.Cabin == cabin {
+					current = append(current, FareBucket{
+						BookingClass: bc.Code,
+						Cabin:        bc.Cabin,
+						Rank:         bc.Rank,
+						Name:         bc.Name,
+						Allocation:   int(math.Round(bc.DefaultShare * 100)), // Weighted in hundredths of a percent
+						Fare:         math.Round(basePrice*bc.FareMultiplier*100) / 100,
+					})
+				}
+			}
+			sort.Slice(current, func(i, j int) bool { return current[i].Rank < current[j].Rank })
+		}
+
+		weight := 0
+		for _, b := range current {
+			weight += b.Allocation
+		}
+		allocated := 0
+		for i := range current {
+			if weight > 0 {
+				current[i].Allocation = current[i].Allocation * n / weight
+			} else {
+				current[i].Allocation = 0
+			}
+			allocated += current[i].Allocation
+		}
+		if len(current) > 0 {
+			current[0].Allocation += n - allocated
+		}
+		resized = append(resized, current...)
+	}
+
+	sort.Slice(resized, func(i, j int) bool {
+		if resized[i].Cabin != resized[j].Cabin {
+			return resized[i].Cabin < resized[j].Cabin
+		}
+		return resized[i].Rank < resized[j].Rank
+	})
+	return resized
+}
+
+// EquipmentChange records a change of a flight's aircraft or cabin layout.
+type EquipmentChange struct {
+	ID             int64     `json:"id"`
+	FlightID       int64     `json:"flight_id"`
+	FromAircraftID *int64    `json:"from_aircraft_id"`
+	ToAircraftID   *int64    `json:"to_aircraft_id"`
+	FromLayoutID   *int64    `json:"from_layout_id"`
+	ToLayoutID     int64     `json:"to_layout_id"`
+	FromCapacity   int       `json:"from_capacity"`
+	ToCapacity     int       `json:"to_capacity"`
+	ReseatCount    int       `json:"reseat_count"`                // Tickets whose seat no longer exists in their cabin
+	ReseatTickets  []int64   `json:"reseat_ticket_ids,omitempty"` // Only returned by the change itself
+	ChangedBy      *int64    `json:"changed_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AssignAircraftRequest defines the body for assigning an aircraft to a flight.
+type AssignAircraftRequest struct {
+	AircraftID int64 `json:"aircraft_id" binding:"required"`
+}
+
+// AssignLayoutRequest defines the body for assigning a cabin layout to a flight.
+type AssignLayoutRequest struct {
+	CabinLayoutID int64 `json:"cabin_layout_id" binding:"required"`
+}
+
+// FareBucketRequest sets the allocation, fare and state of one booking class on a flight.
+type FareBucketRequest struct {
+	BookingClass string  `json:"booking_class" binding:"required,len=1"`
+	Allocation   int     `json:"allocation" binding:"min=0"`
+	Fare         float64 `json:"fare" binding:"required,gt=0"`
+	Closed       bool    `json:"closed"`
+}
+
+// UpdateFaresRequest replaces a flight's fare buckets. Allocations must add up to the flight's
+// capacity; classes left out stop being sold and must not have sold seats.
+type UpdateFaresRequest struct {
+	Buckets []FareBucketRequest `json:"buckets" binding:"required,min=1,dive"`
+}
+
+// ScheduleHorizonDays is how many days ahead flights are generated from their schedules.
+const ScheduleHorizonDays = 90
+
+// Schedule is a recurring flight, operated at the same local time on some days of the week
+// over a date range. One flight is generated for each operating day within the horizon.
+type Schedule struct {
+	ID             int64     `json:"id"`
+	FlightNo       string    `json:"flight_no"`
+	Origin         string    `json:"origin"`
+	Destination    string    `json:"destination"`
+	DepartureLocal string    `json:"departure_local"` // HH:MM in the origin's time zone
+	BlockMinutes   int       `json:"block_minutes"`   // Scheduled time from departure to arrival
+	DaysOfWeek     string    `json:"days_of_week"`    // Monday first, the day's digit when operated and '.' when not, e.g. "1.3.5.."
+	EffectiveFrom  string    `json:"effective_from"`  // YYYY-MM-DD, local to the origin
+	EffectiveTo    string    `json:"effective_to"`    // YYYY-MM-DD, inclusive
+	AircraftTypeID int64     `json:"aircraft_type_id"`
+	CabinLayoutID  *int64    `json:"cabin_layout_id"` // Seat configuration of generated flights; all economy if nil
+	BasePrice      float64   `json:"base_price"`
+	Version        int       `json:"version"`
+	CreatedBy      *int64    `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Operates reports whether the schedule operates on a date, given as midnight UTC.
+func (s *Schedule) Operates(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	if day < s.EffectiveFrom || day > s.EffectiveTo {
+		return false
+	}
+	weekday := (int(date.Weekday()) + 6) % 7 // Monday first
+	return s.DaysOfWeek[weekday] != '.'
+}
+
+// Times returns the departure and arrival of the flight operating on a date, given as midnight
+// UTC, at an origin in loc.
+func (s *Schedule) Times(date time.Time, loc *time.Location) (departure, arrival time.Time) {
+	clock, _ := time.Parse("15:04", s.DepartureLocal)
+	departure = time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	return departure, departure.Add(time.Duration(s.BlockMinutes) * time.Minute)
+}
+
+// ScheduleRequest defines the body for creating or editing a schedule.
+type ScheduleRequest struct {
+	FlightNo       string  `json:"flight_no" binding:"required"`
+	Origin         string  `json:"origin" binding:"required,len=3"`
+	Destination    string  `json:"destination" binding:"required,len=3"`
+	DepartureLocal string  `json:"departure_local" binding:"required"` // Format: HH:MM
+	BlockMinutes   int     `json:"block_minutes" binding:"required,gt=0"`
+	DaysOfWeek     string  `json:"days_of_week" binding:"required,len=7"`
+	EffectiveFrom  string  `json:"effective_from" binding:"required"` // Format: YYYY-MM-DD
+	EffectiveTo    string  `json:"effective_to" binding:"required"`   // Format: YYYY-MM-DD
+	AircraftTypeID int64   `json:"aircraft_type_id" binding:"required"`
+	CabinLayoutID  *int64  `json:"cabin_layout_id"`                     // Optional: generated flights sell 150 economy seats otherwise
+	BasePrice      float64 `json:"base_price" binding:"omitempty,gt=0"` // Optional: DefaultBasePrice otherwise
+	Version        int     `json:"version"`                             // Required when editing: must match the schedule's current version
+}
+
+// ScheduleChange reports what saving a schedule did to its flights.
+type ScheduleChange struct {
+	Schedule  *Schedule `json:"schedule"`
+	Generated int       `json:"generated"`            // Flights created for newly operated days
+	Updated   []int64   `json:"updated_flight_ids"`   // Unbooked flights moved to the new times, route or seats
+	Cancelled []int64   `json:"cancelled_flight_ids"` // Unbooked flights on days no longer operated
+	Flagged   []int64   `json:"flagged_flight_ids"`   // Booked or departing flights left unchanged and flagged for review
+}
+
+// ListSchedulesParams filters schedules.
+type ListSchedulesParams struct {
+	Current bool `form:"current"` // Leave out schedules whose effective range has ended
+}
+
+// ScheduleFlightsParams filters the flights of a schedule.
+type ScheduleFlightsParams struct {
+	ReviewRequired bool `form:"review_required"` // Only flights flagged for review
 }
@@ -1,12 +1,34 @@
 package flight
 
 import (
+	"airport-system/platform/database"
 	"context"
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// flightColumns is the column list read by scanFlight.
+const flightColumns = `id, flight_no, origin, destination, gate_id, departure_time, arrival_time, status, version, created_at, updated_at, total_seats, base_price,
+		estimated_departure_time, estimated_arrival_time, aircraft_id, cabin_layout_id, schedule_id, to_char(schedule_date, 'YYYY-MM-DD'), review_required`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFlight(row rowScanner, f *Flight) error {
+	return row.Scan(
+		&f.ID, &f.FlightNo, &f.Origin, &f.Destination, &f.GateID,
+		&f.DepartureTime, &f.ArrivalTime, &f.Status, &f.Version,
+		&f.CreatedAt, &f.UpdatedAt, &f.TotalSeats, &f.BasePrice,
+		&f.EstimatedDepartureTime, &f.EstimatedArrivalTime, &f.AircraftID, &f.CabinLayoutID,
+		&f.ScheduleID, &f.ScheduleDate, &f.ReviewRequired,
+	)
+}
+
 // Repository handles database interactions for flights.
 type Repository struct {
 	DB *sql.DB
@@ -17,12 +39,45 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{DB: db}
 }
 
-// Create inserts a new flight into the database.
-func (r *Repository) Create(ctx context.Context, f *Flight) (int64, error) {
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+// Create inserts a new flight into the database. cabinSeats gives the seats of each cabin;
+// nil sells all of TotalSeats in economy.
+func (r *Repository) Create(ctx context.Context, f *Flight, cabinSeats map[string]int) (int64, error) {
+	// The inventory counter row and the fare buckets are created in the same statement so a
+	// flight is never bookable without them. Each cabin's seats are split by its booking
+	// classes' default shares; rounding leftovers go to the top class.
 	query := `
-		INSERT INTO flights (flight_no, origin, destination, departure_time, arrival_time, status, version, total_seats, base_price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
-		RETURNING id
+		WITH inserted AS (
+			INSERT INTO flights (flight_no, origin, destination, departure_time, arrival_time, status, version, total_seats, base_price,
+			                     aircraft_id, cabin_layout_id, schedule_id, schedule_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $15, $16::date, NOW(), NOW())
+			RETURNING id, total_seats, base_price
+		), cabins AS (
+			SELECT c.cabin, c.seats
+			FROM (VALUES ('F', $12::INT), ('C', $13::INT), ('Y', $14::INT)) AS c(cabin, seats)
+			WHERE c.seats > 0
+		), buckets AS (
+			INSERT INTO fare_buckets (flight_id, booking_class, allocation, fare)
+			SELECT i.id,
+			       bc.code,
+			       FLOOR(bc.default_share * c.seats / 100)
+			           + CASE WHEN bc.rank = 1 THEN c.seats - (
+			                 SELECT SUM(FLOOR(o.default_share * c.seats / 100))::INT FROM booking_classes o WHERE o.cabin = c.cabin
+			             ) ELSE 0 END,
+			       ROUND(i.base_price * bc.fare_multiplier, 2)
+			FROM inserted i
+			CROSS JOIN cabins c
+			JOIN booking_classes bc ON bc.cabin = c.cabin
+		)
+		INSERT INTO flight_inventory (flight_id, capacity, sold, updated_at)
+		SELECT id, total_seats, 0, NOW() FROM inserted
+		RETURNING flight_id
 	`
 
 	// Defaults
@@ -32,13 +87,16 @@ func (r *Repository) Create(ctx context.Context, f *Flight) (int64, error) {
 	if f.TotalSeats == 0 {
 		f.TotalSeats = 150
 	}
+	if cabinSeats == nil {
+		cabinSeats = map[string]int{CabinEconomy: f.TotalSeats}
+	}
 
 	if f.BasePrice == 0 {
-		f.BasePrice = 32000.00
+		f.BasePrice = DefaultBasePrice
 	}
 
 	var id int64
-	err := r.DB.QueryRowContext(ctx, query,
+	err := r.executor(ctx).QueryRowContext(ctx, query,
 		f.FlightNo,
 		f.Origin,
 		f.Destination,
@@ -48,6 +106,13 @@ func (r *Repository) Create(ctx context.Context, f *Flight) (int64, error) {
 		f.Version,
 		f.TotalSeats,
 		f.BasePrice,
+		f.AircraftID,
+		f.CabinLayoutID,
+		cabinSeats[CabinFirst],
+		cabinSeats[CabinBusiness],
+		cabinSeats[CabinEconomy],
+		f.ScheduleID,
+		f.ScheduleDate,
 	).Scan(&id)
 
 	if err != nil {
@@ -56,76 +121,232 @@ func (r *Repository) Create(ctx context.Context, f *Flight) (int64, error) {
 	return id, nil
 }
 
-// Search retrieves flights based on origin, destination, and date.
-func (r *Repository) Search(ctx context.Context, origin, destination string, date time.Time) ([]Flight, error) {
-	query := `
-		SELECT id, flight_no, origin, destination, gate_id, departure_time, arrival_time, status, version, created_at, updated_at, total_seats, base_price
-		FROM flights
-		WHERE 1=1
-	`
+// searchSortKeys gives each search order's sort key over the search's inner query, and the
+// type its text form is cast back to when resuming from a cursor.
+var searchSortKeys = map[string]struct{ expr, typ string }{
+	SortDeparture: {"departure_time", "timestamptz"},
+	SortPrice:     {"COALESCE(lowest_fare::float8, 'Infinity')", "float8"},
+	SortDuration:  {"arrival_time - departure_time", "interval"},
+}
+
+// lowestFareQuery finds the cheapest fare flight f can still sell. It applies the nesting rule
+// of ComputeAvailability: a class can sell while, for it and every class above it in its cabin,
+// the seats allocated at that rank or below outnumber those sold.
+const lowestFareQuery = `
+	SELECT MIN(a.fare) AS fare
+	FROM (
+		SELECT h.fare, h.closed,
+		       MIN(h.headroom) OVER (PARTITION BY h.cabin ORDER BY h.rank ROWS UNBOUNDED PRECEDING) AS available
+		FROM (
+			SELECT fb.fare, fb.closed, bc.cabin, bc.rank,
+			       SUM(fb.allocation - fb.sold) OVER (PARTITION BY bc.cabin ORDER BY bc.rank DESC) AS headroom
+			FROM fare_buckets fb
+			JOIN booking_classes bc ON bc.code = fb.booking_class
+			WHERE fb.flight_id = f.id
+		) h
+	) a
+	WHERE a.available > 0 AND NOT a.closed`
+
+// withExtra scans columns selected after flightColumns along with a flight.
+type withExtra struct {
+	rowScanner
+	extra []any
+}
+
+func (w withExtra) Scan(dest ...any) error {
+	return w.rowScanner.Scan(append(dest, w.extra...)...)
+}
+
+// Search returns one page of flights matching a search, with the cursor of the next page or
+// nil on the last one. Dates and departure windows are compared in each flight's origin time
+// zone, UTC for origins missing from the airport reference data.
+func (r *Repository) Search(ctx context.Context, q searchQuery) ([]FlightResult, *searchCursor, error) {
+	localDeparture := `(f.departure_time AT TIME ZONE COALESCE(ap.timezone, 'UTC'))`
+	inner := `
+		SELECT f.*, i.capacity - i.sold AS seats_available, lf.fare AS lowest_fare
+		FROM flights f
+		JOIN flight_inventory i ON i.flight_id = f.id
+		LEFT JOIN airports ap ON ap.iata_code = f.origin
+		LEFT JOIN LATERAL (` + lowestFareQuery + `) lf ON TRUE
+		WHERE 1=1`
 	args := []interface{}{}
 	argID := 1
 
-	if origin != "" {
-		query += fmt.Sprintf(" AND origin = $%d", argID)
-		args = append(args, origin)
+	if q.origin != "" {
+		inner += fmt.Sprintf(" AND f.origin = $%d", argID)
+		args = append(args, q.origin)
+		argID++
+	}
+	if q.destination != "" {
+		inner += fmt.Sprintf(" AND f.destination = $%d", argID)
+		args = append(args, q.destination)
+		argID++
+	}
+
+	// Date filtering (Full day range in the origin's time zone)
+	if !q.date.IsZero() && q.origin == "" {
+		// UTC offsets run from -12 to +14 hours; the range keeps the departure index usable
+		inner += fmt.Sprintf(" AND f.departure_time >= $%d AND f.departure_time < $%d AND %s::date = $%d::date",
+			argID, argID+1, localDeparture, argID+2)
+		args = append(args, q.date.Add(-14*time.Hour), q.date.Add(36*time.Hour), q.date.Format("2006-01-02"))
+		argID += 3
+	} else if !q.date.IsZero() {
+		inner += fmt.Sprintf(" AND f.departure_time >= $%d AND f.departure_time < $%d", argID, argID+1)
+		args = append(args, q.date, q.date.AddDate(0, 0, 1))
+		argID += 2
+	}
+
+	switch {
+	case q.departAfter != "" && q.departBefore != "" && q.departAfter > q.departBefore:
+		// The window spans midnight
+		inner += fmt.Sprintf(" AND (%s::time >= $%d::time OR %s::time <= $%d::time)", localDeparture, argID, localDeparture, argID+1)
+		args = append(args, q.departAfter, q.departBefore)
+		argID += 2
+	default:
+		if q.departAfter != "" {
+			inner += fmt.Sprintf(" AND %s::time >= $%d::time", localDeparture, argID)
+			args = append(args, q.departAfter)
+			argID++
+		}
+		if q.departBefore != "" {
+			inner += fmt.Sprintf(" AND %s::time <= $%d::time", localDeparture, argID)
+			args = append(args, q.departBefore)
+			argID++
+		}
+	}
+
+	if len(q.statuses) > 0 {
+		inner += fmt.Sprintf(" AND f.status = ANY($%d)", argID)
+		args = append(args, pq.Array(q.statuses))
 		argID++
 	}
-	if destination != "" {
-		query += fmt.Sprintf(" AND destination = $%d", argID)
-		args = append(args, destination)
+	if q.minSeats > 0 {
+		inner += fmt.Sprintf(" AND i.capacity - i.sold >= $%d", argID)
+		args = append(args, q.minSeats)
 		argID++
 	}
 
-	// Date filtering (Full day range)
-	if !date.IsZero() {
-		// Start of day
-		startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-		// End of day
-		endOfDay := startOfDay.Add(24 * time.Hour).Add(-1 * time.Nanosecond)
+	sortKey := searchSortKeys[q.sort]
+	query := `
+		SELECT ` + flightColumns + `, seats_available, lowest_fare, (` + sortKey.expr + `)::text
+		FROM (` + inner + `) r
+		WHERE 1=1`
 
-		query += fmt.Sprintf(" AND departure_time BETWEEN $%d AND $%d", argID, argID+1)
-		args = append(args, startOfDay, endOfDay)
+	if q.minPrice != nil {
+		query += fmt.Sprintf(" AND lowest_fare >= $%d", argID)
+		args = append(args, *q.minPrice)
+		argID++
+	}
+	if q.maxPrice != nil {
+		query += fmt.Sprintf(" AND lowest_fare <= $%d", argID)
+		args = append(args, *q.maxPrice)
+		argID++
+	}
+	if q.after != nil {
+		query += fmt.Sprintf(" AND (%s, id) > ($%d::%s, $%d)", sortKey.expr, argID, sortKey.typ, argID+1)
+		args = append(args, q.after.Key, q.after.ID)
 		argID += 2
 	}
 
-	query += " ORDER BY departure_time ASC"
+	// One extra row tells whether there is a next page
+	query += fmt.Sprintf(" ORDER BY %s, id LIMIT $%d", sortKey.expr, argID)
+	args = append(args, q.limit+1)
 
-	rows, err := r.DB.QueryContext(ctx, query, args...)
+	rows, err := r.executor(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search flights: %w", err)
+		return nil, nil, fmt.Errorf("failed to search flights: %w", err)
 	}
 	defer rows.Close()
 
-	var flights []Flight
+	results := []FlightResult{}
+	var keys []string
 	for rows.Next() {
-		var f Flight
-		if err := rows.Scan(
-			&f.ID, &f.FlightNo, &f.Origin, &f.Destination, &f.GateID,
-			&f.DepartureTime, &f.ArrivalTime, &f.Status, &f.Version,
-			&f.CreatedAt, &f.UpdatedAt, &f.TotalSeats, &f.BasePrice,
-		); err != nil {
+		var res FlightResult
+		var key string
+		if err := scanFlight(withExtra{rows, []any{&res.SeatsAvailable, &res.LowestFare, &key}}, &res.Flight); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan flight: %w", err)
+		}
+		res.DurationMinutes = int(res.ArrivalTime.Sub(res.DepartureTime).Minutes())
+		results = append(results, res)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to search flights: %w", err)
+	}
+
+	if len(results) <= q.limit {
+		return results, nil, nil
+	}
+	results = results[:q.limit]
+	last := q.limit - 1
+	return results, &searchCursor{Sort: q.sort, Key: keys[last], ID: results[last].ID}, nil
+}
+
+// ListLegs returns the flights a leg query selects with their availability, in departure order.
+func (r *Repository) ListLegs(ctx context.Context, q legQuery) ([]FlightResult, error) {
+	query := `
+		SELECT ` + flightColumns + `, seats_available, lowest_fare
+		FROM (
+			SELECT f.*, i.capacity - i.sold AS seats_available, lf.fare AS lowest_fare
+			FROM flights f
+			JOIN flight_inventory i ON i.flight_id = f.id
+			LEFT JOIN LATERAL (` + lowestFareQuery + `) lf ON TRUE
+			WHERE f.origin = ANY($1) AND f.departure_time >= $2 AND f.departure_time < $3
+			  AND f.status = ANY($4) AND i.capacity - i.sold >= $5
+			  AND ($6::text = '' OR f.destination = $6)
+		) r
+		WHERE lowest_fare IS NOT NULL
+		ORDER BY departure_time, id
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query,
+		pq.Array(q.origins), q.departFrom, q.departTo, pq.Array(bookableStatuses), q.minSeats, q.destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list itinerary legs: %w", err)
+	}
+	defer rows.Close()
+
+	legs := []FlightResult{}
+	for rows.Next() {
+		var leg FlightResult
+		if err := scanFlight(withExtra{rows, []any{&leg.SeatsAvailable, &leg.LowestFare}}, &leg.Flight); err != nil {
 			return nil, fmt.Errorf("failed to scan flight: %w", err)
 		}
-		flights = append(flights, f)
+		leg.DurationMinutes = int(leg.ArrivalTime.Sub(leg.DepartureTime).Minutes())
+		legs = append(legs, leg)
 	}
+	return legs, rows.Err()
+}
 
-	return flights, nil
+// FindByFlightNo returns the first flight with the flight number and origin departing within
+// [from, to), or nil if there is none. Cancelled flights are ignored.
+func (r *Repository) FindByFlightNo(ctx context.Context, flightNo, origin string, from, to time.Time) (*Flight, error) {
+	query := `
+		SELECT ` + flightColumns + `
+		FROM flights
+		WHERE flight_no = $1 AND origin = $2 AND departure_time >= $3 AND departure_time < $4 AND status <> $5
+		ORDER BY departure_time, id
+		LIMIT 1
+	`
+	var f Flight
+	err := scanFlight(r.executor(ctx).QueryRowContext(ctx, query, flightNo, origin, from, to, StatusCancelled), &f)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find flight: %w", err)
+	}
+	return &f, nil
 }
 
 // GetByID retrieves a flight by its ID.
 func (r *Repository) GetByID(ctx context.Context, id int64) (*Flight, error) {
 	query := `
-		SELECT id, flight_no, origin, destination, gate_id, departure_time, arrival_time, status, version, created_at, updated_at, total_seats, base_price
+		SELECT ` + flightColumns + `
 		FROM flights
 		WHERE id = $1
 	`
 	var f Flight
-	err := r.DB.QueryRowContext(ctx, query, id).Scan(
-		&f.ID, &f.FlightNo, &f.Origin, &f.Destination, &f.GateID,
-		&f.DepartureTime, &f.ArrivalTime, &f.Status, &f.Version,
-		&f.CreatedAt, &f.UpdatedAt, &f.TotalSeats, &f.BasePrice,
-	)
+	err := scanFlight(r.executor(ctx).QueryRowContext(ctx, query, id), &f)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -134,3 +355,464 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*Flight, error) {
 	}
 	return &f, nil
 }
+
+// UpdateStatus sets a flight's status and estimated times if its version still equals
+// expectedVersion, bumping the version. Returns nil if the version did not match.
+func (r *Repository) UpdateStatus(ctx context.Context, id int64, expectedVersion int, status string, estDeparture, estArrival *time.Time) (*Flight, error) {
+	query := `
+		UPDATE flights
+		SET status = $1,
+		    estimated_departure_time = COALESCE($2, estimated_departure_time),
+		    estimated_arrival_time = COALESCE($3, estimated_arrival_time),
+		    version = version + 1,
+		    updated_at = NOW()
+		WHERE id = $4 AND version = $5
+		RETURNING ` + flightColumns
+	var f Flight
+	err := scanFlight(r.executor(ctx).QueryRowContext(ctx, query, status, estDeparture, estArrival, id, expectedVersion), &f)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update flight status: %w", err)
+	}
+	return &f, nil
+}
+
+// CreateStatusChange records a flight status transition.
+func (r *Repository) CreateStatusChange(ctx context.Context, sc *StatusChange) (int64, error) {
+	query := `
+		INSERT INTO flight_status_history (flight_id, from_status, to_status, version, changed_by, reason, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, changed_at
+	`
+	var id int64
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		sc.FlightID, sc.FromStatus, sc.ToStatus, sc.Version, sc.ChangedBy, sc.Reason,
+	).Scan(&id, &sc.ChangedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record status change: %w", err)
+	}
+	return id, nil
+}
+
+// ListStatusHistory returns a flight's status transitions, oldest first.
+func (r *Repository) ListStatusHistory(ctx context.Context, flightID int64) ([]StatusChange, error) {
+	query := `
+		SELECT id, flight_id, from_status, to_status, version, changed_by, reason, changed_at
+		FROM flight_status_history
+		WHERE flight_id = $1
+		ORDER BY changed_at ASC, id ASC
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []StatusChange{}
+	for rows.Next() {
+		var sc StatusChange
+		if err := rows.Scan(&sc.ID, &sc.FlightID, &sc.FromStatus, &sc.ToStatus, &sc.Version, &sc.ChangedBy, &sc.Reason, &sc.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status change: %w", err)
+		}
+		history = append(history, sc)
+	}
+	return history, nil
+}
+
+// ListBookingClasses returns every booking class by cabin and rank.
+func (r *Repository) ListBookingClasses(ctx context.Context) ([]BookingClass, error) {
+	query := `SELECT code, cabin, rank, name, fare_multiplier, default_share FROM booking_classes ORDER BY cabin, rank`
+	rows, err := r.executor(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list booking classes: %w", err)
+	}
+	defer rows.Close()
+
+	var classes []BookingClass
+	for rows.Next() {
+		var bc BookingClass
+		if err := rows.Scan(&bc.Code, &bc.Cabin, &bc.Rank, &bc.Name, &bc.FareMultiplier, &bc.DefaultShare); err != nil {
+			return nil, fmt.Errorf("failed to scan booking class: %w", err)
+		}
+		classes = append(classes, bc)
+	}
+	return classes, rows.Err()
+}
+
+// ListFareBuckets returns a flight's fare buckets sorted by cabin and rank, with availability computed.
+func (r *Repository) ListFareBuckets(ctx context.Context, flightID int64) ([]FareBucket, error) {
+	return r.listFareBuckets(ctx, flightID, "")
+}
+
+// LockFareBuckets returns a flight's fare buckets like ListFareBuckets and locks them until the
+// transaction ends. Callers lock the flight's inventory row first.
+func (r *Repository) LockFareBuckets(ctx context.Context, flightID int64) ([]FareBucket, error) {
+	return r.listFareBuckets(ctx, flightID, " FOR UPDATE OF fb")
+}
+
+func (r *Repository) listFareBuckets(ctx context.Context, flightID int64, lock string) ([]FareBucket, error) {
+	query := `
+		SELECT fb.booking_class, bc.cabin, bc.rank, bc.name, fb.allocation, fb.sold, fb.fare, fb.closed, fb.updated_at
+		FROM fare_buckets fb
+		JOIN booking_classes bc ON bc.code = fb.booking_class
+		WHERE fb.flight_id = $1
+		ORDER BY bc.cabin, bc.rank` + lock
+	rows, err := r.executor(ctx).QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fare buckets: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []FareBucket{}
+	for rows.Next() {
+		var b FareBucket
+		if err := rows.Scan(&b.BookingClass, &b.Cabin, &b.Rank, &b.Name, &b.Allocation, &b.Sold, &b.Fare, &b.Closed, &b.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fare bucket: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	ComputeAvailability(buckets)
+	return buckets, nil
+}
+
+// LockInventory returns a flight's seat capacity and seats sold, and locks its inventory row
+// until the transaction ends. Returns ErrFlightNotFound if the flight has no inventory.
+func (r *Repository) LockInventory(ctx context.Context, flightID int64) (capacity, sold int, err error) {
+	query := `SELECT capacity, sold FROM flight_inventory WHERE flight_id = $1 FOR UPDATE`
+	if err := r.executor(ctx).QueryRowContext(ctx, query, flightID).Scan(&capacity, &sold); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, ErrFlightNotFound
+		}
+		return 0, 0, fmt.Errorf("failed to lock inventory: %w", err)
+	}
+	return capacity, sold, nil
+}
+
+// SetEquipment assigns an aircraft and cabin layout to a flight and resizes its capacity.
+func (r *Repository) SetEquipment(ctx context.Context, flightID int64, aircraftID *int64, layoutID int64, totalSeats int) error {
+	exec := r.executor(ctx)
+
+	query := `UPDATE flights SET aircraft_id = $2, cabin_layout_id = $3, total_seats = $4, updated_at = NOW() WHERE id = $1`
+	if _, err := exec.ExecContext(ctx, query, flightID, aircraftID, layoutID, totalSeats); err != nil {
+		return fmt.Errorf("failed to assign equipment: %w", err)
+	}
+
+	query = `UPDATE flight_inventory SET capacity = $2, updated_at = NOW() WHERE flight_id = $1`
+	if _, err := exec.ExecContext(ctx, query, flightID, totalSeats); err != nil {
+		return fmt.Errorf("failed to update flight inventory: %w", err)
+	}
+	return nil
+}
+
+// CreateEquipmentChange records an equipment change.
+func (r *Repository) CreateEquipmentChange(ctx context.Context, ec *EquipmentChange) error {
+	query := `
+		INSERT INTO equipment_changes (flight_id, from_aircraft_id, to_aircraft_id, from_layout_id, to_layout_id,
+		                               from_capacity, to_capacity, reseat_count, changed_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING id, created_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		ec.FlightID, ec.FromAircraftID, ec.ToAircraftID, ec.FromLayoutID, ec.ToLayoutID,
+		ec.FromCapacity, ec.ToCapacity, ec.ReseatCount, ec.ChangedBy,
+	).Scan(&ec.ID, &ec.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record equipment change: %w", err)
+	}
+	return nil
+}
+
+// ListEquipmentChanges returns a flight's equipment changes, oldest first.
+func (r *Repository) ListEquipmentChanges(ctx context.Context, flightID int64) ([]EquipmentChange, error) {
+	query := `
+		SELECT id, flight_id, from_aircraft_id, to_aircraft_id, from_layout_id, to_layout_id,
+		       from_capacity, to_capacity, reseat_count, changed_by, created_at
+		FROM equipment_changes
+		WHERE flight_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list equipment changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []EquipmentChange{}
+	for rows.Next() {
+		var ec EquipmentChange
+		if err := rows.Scan(&ec.ID, &ec.FlightID, &ec.FromAircraftID, &ec.ToAircraftID, &ec.FromLayoutID, &ec.ToLayoutID,
+			&ec.FromCapacity, &ec.ToCapacity, &ec.ReseatCount, &ec.ChangedBy, &ec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan equipment change: %w", err)
+		}
+		changes = append(changes, ec)
+	}
+	return changes, nil
+}
+
+// ReplaceFareBuckets stores a flight's fare buckets and deletes the classes not among them.
+func (r *Repository) ReplaceFareBuckets(ctx context.Context, flightID int64, buckets []FareBucket) error {
+	classes := make([]string, len(buckets))
+	for i, b := range buckets {
+		classes[i] = b.BookingClass
+	}
+	query := `DELETE FROM fare_buckets WHERE flight_id = $1 AND NOT (booking_class = ANY($2))`
+	if _, err := r.executor(ctx).ExecContext(ctx, query, flightID, pq.Array(classes)); err != nil {
+		return fmt.Errorf("failed to remove fare buckets: %w", err)
+	}
+
+	query = `
+		INSERT INTO fare_buckets (flight_id, booking_class, allocation, fare, closed, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (flight_id, booking_class) DO UPDATE
+		SET allocation = EXCLUDED.allocation, fare = EXCLUDED.fare, closed = EXCLUDED.closed, updated_at = NOW()
+	`
+	for _, b := range buckets {
+		if _, err := r.executor(ctx).ExecContext(ctx, query, flightID, b.BookingClass, b.Allocation, b.Fare, b.Closed); err != nil {
+			return fmt.Errorf("failed to store fare bucket: %w", err)
+		}
+	}
+	return nil
+}
+
+// AdjustBucketSold changes the seats sold in a booking class by delta, never below zero.
+func (r *Repository) AdjustBucketSold(ctx context.Context, flightID int64, bookingClass string, delta int) error {
+	query := `
+		UPDATE fare_buckets
+		SET sold = GREATEST(sold + $3, 0), updated_at = NOW()
+		WHERE flight_id = $1 AND booking_class = $2
+	`
+	if _, err := r.executor(ctx).ExecContext(ctx, query, flightID, bookingClass, delta); err != nil {
+		return fmt.Errorf("failed to update fare bucket: %w", err)
+	}
+	return nil
+}
+
+// scheduleColumns is the column list read by scanSchedule.
+const scheduleColumns = `id, flight_no, origin, destination, to_char(departure_local, 'HH24:MI'), block_minutes, days_of_week,
+		to_char(effective_from, 'YYYY-MM-DD'), to_char(effective_to, 'YYYY-MM-DD'), aircraft_type_id, cabin_layout_id, base_price,
+		version, created_by, created_at, updated_at`
+
+func scanSchedule(row rowScanner, s *Schedule) error {
+	return row.Scan(
+		&s.ID, &s.FlightNo, &s.Origin, &s.Destination, &s.DepartureLocal, &s.BlockMinutes, &s.DaysOfWeek,
+		&s.EffectiveFrom, &s.EffectiveTo, &s.AircraftTypeID, &s.CabinLayoutID, &s.BasePrice,
+		&s.Version, &s.CreatedBy, &s.CreatedAt, &s.UpdatedAt,
+	)
+}
+
+// CreateSchedule inserts a new flight schedule.
+func (r *Repository) CreateSchedule(ctx context.Context, s *Schedule) error {
+	query := `
+		INSERT INTO flight_schedules (flight_no, origin, destination, departure_local, block_minutes, days_of_week,
+		                              effective_from, effective_to, aircraft_type_id, cabin_layout_id, base_price, version,
+		                              created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 1, $12, NOW(), NOW())
+		RETURNING ` + scheduleColumns
+	err := scanSchedule(r.executor(ctx).QueryRowContext(ctx, query,
+		s.FlightNo, s.Origin, s.Destination, s.DepartureLocal, s.BlockMinutes, s.DaysOfWeek,
+		s.EffectiveFrom, s.EffectiveTo, s.AircraftTypeID, s.CabinLayoutID, s.BasePrice, s.CreatedBy,
+	), s)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+	return nil
+}
+
+// UpdateSchedule saves a schedule if its version still equals expectedVersion, bumping the
+// version. Returns false if the version did not match.
+func (r *Repository) UpdateSchedule(ctx context.Context, s *Schedule, expectedVersion int) (bool, error) {
+	query := `
+		UPDATE flight_schedules
+		SET flight_no = $1, origin = $2, destination = $3, departure_local = $4, block_minutes = $5, days_of_week = $6,
+		    effective_from = $7, effective_to = $8, aircraft_type_id = $9, cabin_layout_id = $10, base_price = $11,
+		    version = version + 1, updated_at = NOW()
+		WHERE id = $12 AND version = $13
+		RETURNING ` + scheduleColumns
+	err := scanSchedule(r.executor(ctx).QueryRowContext(ctx, query,
+		s.FlightNo, s.Origin, s.Destination, s.DepartureLocal, s.BlockMinutes, s.DaysOfWeek,
+		s.EffectiveFrom, s.EffectiveTo, s.AircraftTypeID, s.CabinLayoutID, s.BasePrice, s.ID, expectedVersion,
+	), s)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return true, nil
+}
+
+// GetSchedule retrieves a schedule by ID.
+func (r *Repository) GetSchedule(ctx context.Context, id int64) (*Schedule, error) {
+	return r.getSchedule(ctx, `SELECT `+scheduleColumns+` FROM flight_schedules WHERE id = $1`, id)
+}
+
+// GetScheduleForUpdate retrieves a schedule by ID and locks it for the current transaction,
+// serializing edits with flight generation.
+func (r *Repository) GetScheduleForUpdate(ctx context.Context, id int64) (*Schedule, error) {
+	return r.getSchedule(ctx, `SELECT `+scheduleColumns+` FROM flight_schedules WHERE id = $1 FOR UPDATE`, id)
+}
+
+func (r *Repository) getSchedule(ctx context.Context, query string, id int64) (*Schedule, error) {
+	var s Schedule
+	if err := scanSchedule(r.executor(ctx).QueryRowContext(ctx, query, id), &s); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	return &s, nil
+}
+
+// ListSchedules returns schedules ordered by flight number. current leaves out schedules
+// whose effective range has ended.
+func (r *Repository) ListSchedules(ctx context.Context, current bool) ([]Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM flight_schedules`
+	if current {
+		// A day of slack: the range ends in the origin's time zone, not the database's
+		query += ` WHERE effective_to >= CURRENT_DATE - 1`
+	}
+	query += ` ORDER BY flight_no, effective_from`
+
+	rows, err := r.executor(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []Schedule{}
+	for rows.Next() {
+		var s Schedule
+		if err := scanSchedule(rows, &s); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, nil
+}
+
+// ListScheduleDates returns the operating days from a date on which a schedule already has a
+// flight, whatever its status.
+func (r *Repository) ListScheduleDates(ctx context.Context, scheduleID int64, from string) (map[string]bool, error) {
+	query := `SELECT to_char(schedule_date, 'YYYY-MM-DD') FROM flights WHERE schedule_id = $1 AND schedule_date >= $2::date`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, scheduleID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedule dates: %w", err)
+	}
+	defer rows.Close()
+
+	dates := make(map[string]bool)
+	for rows.Next() {
+		var d string
+		if err := rows.Scan(&d); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule date: %w", err)
+		}
+		dates[d] = true
+	}
+	return dates, nil
+}
+
+// ListScheduleFlights returns a schedule's flights in departure order, optionally only those
+// flagged for review.
+func (r *Repository) ListScheduleFlights(ctx context.Context, scheduleID int64, reviewOnly bool) ([]Flight, error) {
+	query := `SELECT ` + flightColumns + ` FROM flights WHERE schedule_id = $1`
+	if reviewOnly {
+		query += ` AND review_required`
+	}
+	query += ` ORDER BY departure_time`
+	return r.listFlights(ctx, query, scheduleID)
+}
+
+// LockUpcomingScheduleFlights returns a schedule's flights that have not departed and were not
+// cancelled, with the seats sold on each, and locks their inventory rows so nothing can be
+// booked on them until the transaction ends.
+func (r *Repository) LockUpcomingScheduleFlights(ctx context.Context, scheduleID int64) ([]Flight, map[int64]int, error) {
+	query := `
+		SELECT i.flight_id, i.sold
+		FROM flight_inventory i
+		JOIN flights f ON f.id = i.flight_id
+		WHERE f.schedule_id = $1 AND f.departure_time > NOW() AND f.status <> $2
+		ORDER BY f.departure_time
+		FOR UPDATE OF i
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, scheduleID, StatusCancelled)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock schedule flights: %w", err)
+	}
+	defer rows.Close()
+
+	sold := make(map[int64]int)
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan flight inventory: %w", err)
+		}
+		sold[id] = n
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to lock schedule flights: %w", err)
+	}
+
+	query = `SELECT ` + flightColumns + ` FROM flights WHERE id = ANY($1) ORDER BY departure_time`
+	flights, err := r.listFlights(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, nil, err
+	}
+	return flights, sold, nil
+}
+
+// RescheduleFlight moves a flight to new times, flight number and route if its version still
+// equals f.Version, bumping the version and clearing any review flag. Returns false if the
+// version did not match.
+func (r *Repository) RescheduleFlight(ctx context.Context, f *Flight) (bool, error) {
+	query := `
+		UPDATE flights
+		SET flight_no = $3, origin = $4, destination = $5, departure_time = $6, arrival_time = $7,
+		    review_required = FALSE, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2
+		RETURNING ` + flightColumns
+	err := scanFlight(r.executor(ctx).QueryRowContext(ctx, query,
+		f.ID, f.Version, f.FlightNo, f.Origin, f.Destination, f.DepartureTime, f.ArrivalTime,
+	), f)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to reschedule flight: %w", err)
+	}
+	return true, nil
+}
+
+// SetReviewRequired flags or clears flights for review.
+func (r *Repository) SetReviewRequired(ctx context.Context, flightIDs []int64, required bool) error {
+	query := `UPDATE flights SET review_required = $2, updated_at = NOW() WHERE id = ANY($1)`
+	if _, err := r.executor(ctx).ExecContext(ctx, query, pq.Array(flightIDs), required); err != nil {
+		return fmt.Errorf("failed to flag flights for review: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) listFlights(ctx context.Context, query string, args ...any) ([]Flight, error) {
+	rows, err := r.executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flights: %w", err)
+	}
+	defer rows.Close()
+
+	flights := []Flight{}
+	for rows.Next() {
+		var f Flight
+		if err := scanFlight(rows, &f); err != nil {
+			return nil, fmt.Errorf("failed to scan flight: %w", err)
+		}
+		flights = append(flights, f)
+	}
+	return flights, nil
+}
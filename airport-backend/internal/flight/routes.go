@@ -11,8 +11,30 @@ func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFu
 		flightGroup.GET("", h.Search) 
 		
 		flightGroup.GET("/:id", h.GetByID)
+		flightGroup.GET("/:id/fares", h.GetFares)
 
 		// Protected routes
 		flightGroup.POST("", authMiddleware, h.Create)
+		flightGroup.POST("/import", authMiddleware, h.Import)
+		flightGroup.PATCH("/:id/status", authMiddleware, h.UpdateStatus)
+		flightGroup.GET("/:id/status-history", authMiddleware, h.GetStatusHistory)
+		flightGroup.PUT("/:id/fares", authMiddleware, h.UpdateFares)
+		flightGroup.PUT("/:id/aircraft", authMiddleware, h.AssignAircraft)
+		flightGroup.GET("/:id/equipment-changes", authMiddleware, h.ListEquipmentChanges)
+		flightGroup.PUT("/:id/seats/layout", authMiddleware, h.AssignLayout)
+		flightGroup.DELETE("/:id/review", authMiddleware, h.ClearReview)
+	}
+
+	r.GET("/itineraries", h.SearchItineraries)
+
+	scheduleGroup := r.Group("/schedules")
+	scheduleGroup.Use(authMiddleware)
+	{
+		scheduleGroup.POST("", h.CreateSchedule)
+		scheduleGroup.GET("", h.ListSchedules)
+		scheduleGroup.POST("/generate", h.GenerateScheduledFlights)
+		scheduleGroup.GET("/:id", h.GetSchedule)
+		scheduleGroup.PUT("/:id", h.UpdateSchedule)
+		scheduleGroup.GET("/:id/flights", h.ListScheduleFlights)
 	}
 }
\ No newline at end of file
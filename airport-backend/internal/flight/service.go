@@ -1,78 +1,1521 @@
 package flight
 
 import (
+	"airport-system/internal/airport"
+	"airport-system/internal/fleet"
+	"airport-system/internal/seating"
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
+	"bufio"
 	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+var (
+	// ErrFlightNotFound is returned when the flight does not exist.
+	ErrFlightNotFound = apperror.NotFound("flight_not_found", "flight not found")
+	// ErrVersionConflict is returned when the flight was modified since the client read it.
+	ErrVersionConflict = apperror.Conflict("version_conflict", "flight was modified by another request, reload and retry")
+	// ErrInvalidTransition is returned for status changes the lifecycle does not allow.
+	ErrInvalidTransition = apperror.Conflict("invalid_status_transition", "invalid status transition")
+	// ErrInvalidStatusUpdate is returned when a status update request is malformed.
+	ErrInvalidStatusUpdate = apperror.Validation("invalid_status_update", "invalid status update")
+	// ErrInvalidFlight is returned when flight details fail validation.
+	ErrInvalidFlight = apperror.Validation("invalid_flight", "invalid flight")
+	// ErrInvalidSearch is returned when search parameters fail validation.
+	ErrInvalidSearch = apperror.Validation("invalid_search", "invalid search")
+	// ErrInvalidImport is returned when a flight import file cannot be read, or for rows it rejects.
+	ErrInvalidImport = apperror.Validation("invalid_flight_import", "invalid flight import")
+	// ErrInvalidItinerary is returned when flights booked together do not connect into one itinerary.
+	ErrInvalidItinerary = apperror.Validation("invalid_itinerary", "flights do not form a valid itinerary")
+	// ErrBookingClassNotOffered is returned when booking a class the flight does not sell.
+	ErrBookingClassNotOffered = apperror.Validation("booking_class_not_offered", "booking class is not sold on this flight")
+	// ErrBookingClassFull is returned when a booking class has no seat left, even nested from lower classes.
+	ErrBookingClassFull = apperror.Conflict("booking_class_full", "booking class is sold out")
+	// ErrInvalidFares is returned when a fare bucket configuration fails validation.
+	ErrInvalidFares = apperror.Validation("invalid_fares", "invalid fare buckets")
+	// ErrFaresConflict is returned when new fare buckets cannot hold the seats already sold.
+	ErrFaresConflict = apperror.Conflict("fares_conflict", "fare buckets do not fit sold seats")
+	// ErrEquipmentTooSmall is returned when the new equipment has fewer seats than are sold.
+	ErrEquipmentTooSmall = apperror.Conflict("equipment_too_small", "aircraft has fewer seats than are sold")
+	// ErrScheduleNotFound is returned when the schedule does not exist.
+	ErrScheduleNotFound = apperror.NotFound("schedule_not_found", "schedule not found")
+	// ErrInvalidSchedule is returned when a schedule fails validation.
+	ErrInvalidSchedule = apperror.Validation("invalid_schedule", "invalid schedule")
+	// ErrScheduleConflict is returned when the schedule was modified since the client read it.
+	ErrScheduleConflict = apperror.Conflict("schedule_version_conflict", "schedule was modified by another request, reload and retry")
+	// ErrEquipmentLocked is returned when changing the equipment of a flight that has left or was cancelled.
+	ErrEquipmentLocked = apperror.Conflict("equipment_locked", "flight equipment can no longer be changed")
+	// ErrFlightLocked is returned when retiming a flight that has started boarding, left or been cancelled.
+	ErrFlightLocked = apperror.Conflict("flight_locked", "flight can no longer be retimed")
+)
+
 // Service handles business logic for flights.
 type Service struct {
-	repo *Repository
-	log  *slog.Logger
+	repo           *Repository
+	airportService *airport.Service
+	fleetService   *fleet.Service
+	seatService    *seating.Service
+	txManager      database.TxManager
+	log            *slog.Logger
 }
 
 // NewService creates a new flight service.
-func NewService(repo *Repository, log *slog.Logger) *Service {
+func NewService(repo *Repository, airportService *airport.Service, fleetService *fleet.Service, seatService *seating.Service, txManager database.TxManager, log *slog.Logger) *Service {
 	return &Service{
-		repo: repo,
-		log:  log,
+		repo:           repo,
+		airportService: airportService,
+		fleetService:   fleetService,
+		seatService:    seatService,
+		txManager:      txManager,
+		log:            log,
 	}
 }
 
-// CreateFlight validates and creates a new flight.
+// CreateFlight validates and creates a new flight. A flight given an aircraft takes its
+// capacity and cabins from the aircraft's seat configuration.
 func (s *Service) CreateFlight(ctx context.Context, params CreateFlightParams) (*Flight, error) {
+	flight, err := s.newFlight(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		cabinSeats, err := s.equipFlight(ctx, flight, params.AircraftID)
+		if err != nil {
+			return err
+		}
+
+		id, err := s.repo.Create(ctx, flight, cabinSeats)
+		if err != nil {
+			return err
+		}
+		flight.ID = id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flight, nil
+}
+
+// newFlight validates flight details and returns the scheduled flight they describe.
+func (s *Service) newFlight(ctx context.Context, params CreateFlightParams) (*Flight, error) {
 	// Parse times
 	depTime, err := time.Parse(time.RFC3339, params.DepartureTime)
 	if err != nil {
-		return nil, errors.New("invalid departure_time format (expected RFC3339)")
+		return nil, fmt.Errorf("%w: invalid departure_time format (expected RFC3339)", ErrInvalidFlight)
 	}
 	arrTime, err := time.Parse(time.RFC3339, params.ArrivalTime)
 	if err != nil {
-		return nil, errors.New("invalid arrival_time format (expected RFC3339)")
+		return nil, fmt.Errorf("%w: invalid arrival_time format (expected RFC3339)", ErrInvalidFlight)
 	}
 
 	// Validation
+	if params.FlightNo == "" || len(params.FlightNo) > 16 {
+		return nil, fmt.Errorf("%w: flight_no must be 1 to 16 characters", ErrInvalidFlight)
+	}
 	if !arrTime.After(depTime) {
-		return nil, errors.New("arrival_time must be after departure_time")
+		return nil, fmt.Errorf("%w: arrival_time must be after departure_time", ErrInvalidFlight)
+	}
+	origin := strings.ToUpper(params.Origin)
+	destination := strings.ToUpper(params.Destination)
+	if origin == destination {
+		return nil, fmt.Errorf("%w: origin and destination cannot be the same", ErrInvalidFlight)
 	}
-	if params.Origin == params.Destination {
-		return nil, errors.New("origin and destination cannot be the same")
+	if err := s.requireAirports(ctx, ErrInvalidFlight, origin, destination); err != nil {
+		return nil, err
 	}
 
-	flight := &Flight{
+	return &Flight{
 		FlightNo:      params.FlightNo,
-		Origin:        params.Origin,
-		Destination:   params.Destination,
+		Origin:        origin,
+		Destination:   destination,
 		DepartureTime: depTime,
 		ArrivalTime:   arrTime,
-		Status:        "SCHEDULED",
+		Status:        StatusScheduled,
+	}, nil
+}
+
+// equipFlight gives a new flight the capacity of an aircraft and returns the aircraft's seats
+// per cabin. Without an aircraft the flight keeps the default capacity and cabins.
+func (s *Service) equipFlight(ctx context.Context, flight *Flight, aircraftID *int64) (map[string]int, error) {
+	if aircraftID == nil {
+		return nil, nil
 	}
+	aircraft, err := s.fleetService.ActiveAircraft(ctx, *aircraftID)
+	if err != nil {
+		return nil, err
+	}
+	flight.AircraftID = &aircraft.ID
+	flight.CabinLayoutID = &aircraft.CabinLayoutID
+	flight.TotalSeats = aircraft.TotalSeats
+	return aircraft.SeatConfig, nil
+}
+
+// errImportRejected rolls back an import that is a dry run or has rejected rows.
+var errImportRejected = errors.New("flight import not applied")
 
-	id, err := s.repo.Create(ctx, flight)
+// ImportFlights creates and updates flights from a CSV or SSIM schedule file in one transaction.
+// Every flight is validated as by CreateFlight. A flight matching an existing one updates it if
+// the existing flight is still SCHEDULED and nobody has booked it; otherwise the row is rejected
+// and the flight has to be changed on its own. Nothing is applied on a dry run or if any row is
+// rejected, but the result reports every insert, update and error either way.
+func (s *Service) ImportFlights(ctx context.Context, changedBy *int64, r io.Reader, dryRun bool) (*ImportResult, error) {
+	in := bufio.NewReader(r)
+	if bom, _ := in.Peek(3); string(bom) == "\uFEFF" {
+		in.Discard(3)
+	}
+
+	result := &ImportResult{Format: ImportFormatCSV, DryRun: dryRun, Flights: []ImportedFlight{}}
+	var rows []importRow
+	var err error
+	if head, _ := in.Peek(len(ssimTitle)); string(head) == ssimTitle {
+		result.Format = ImportFormatSSIM
+		rows, result.Errors, result.Skipped, err = readSSIM(in, time.Now())
+	} else {
+		rows, result.Errors, err = readFlightCSV(in)
+	}
 	if err != nil {
 		return nil, err
 	}
-	flight.ID = id
-	return flight, nil
+
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		seen := make(map[string]int)
+		locations := make(map[string]*time.Location)
+		for _, row := range rows {
+			imported, err := s.importFlight(ctx, changedBy, row, seen, locations)
+			if err != nil {
+				if _, ok := apperror.As(err); !ok {
+					return err
+				}
+				result.Errors = append(result.Errors, ImportError{Line: row.line, Message: err.Error()})
+				continue
+			}
+			switch imported.Action {
+			case ImportInsert:
+				result.Inserted++
+			case ImportUpdate:
+				result.Updated++
+			default:
+				result.Unchanged++
+			}
+			result.Flights = append(result.Flights, *imported)
+		}
+		if dryRun || len(result.Errors) > 0 {
+			return errImportRejected
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRejected) {
+		return nil, err
+	}
+	result.Applied = err == nil
+	if !result.Applied {
+		// The IDs of rolled back inserts were never committed
+		for i := range result.Flights {
+			if result.Flights[i].Action == ImportInsert {
+				result.Flights[i].FlightID = nil
+			}
+		}
+	}
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Line < result.Errors[j].Line })
+
+	s.log.Info("Flights imported", "format", result.Format, "dry_run", dryRun, "applied", result.Applied,
+		"inserted", result.Inserted, "updated", result.Updated, "unchanged", result.Unchanged, "rejected", len(result.Errors))
+	return result, nil
+}
+
+// importFlight validates an imported flight, then inserts it or updates the flight it matches.
+// seen maps the flights imported so far to their lines, and locations caches origin time zones.
+func (s *Service) importFlight(ctx context.Context, changedBy *int64, row importRow, seen map[string]int, locations map[string]*time.Location) (*ImportedFlight, error) {
+	f, err := s.newFlight(ctx, row.params)
+	if err != nil {
+		return nil, err
+	}
+	loc, ok := locations[f.Origin]
+	if !ok {
+		if loc, err = s.airportService.Location(ctx, f.Origin); err != nil {
+			return nil, err
+		}
+		locations[f.Origin] = loc
+	}
+	local := f.DepartureTime.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	key := f.FlightNo + "/" + f.Origin + "/" + day.Format("2006-01-02")
+	if line, ok := seen[key]; ok {
+		return nil, fmt.Errorf("%w: flight %s from %s on %s is already imported from line %d",
+			ErrInvalidImport, f.FlightNo, f.Origin, day.Format("2006-01-02"), line)
+	}
+	seen[key] = row.line
+
+	imported := &ImportedFlight{
+		Line:          row.line,
+		FlightNo:      f.FlightNo,
+		Origin:        f.Origin,
+		Destination:   f.Destination,
+		DepartureTime: f.DepartureTime,
+		ArrivalTime:   f.ArrivalTime,
+	}
+	existing, err := s.repo.FindByFlightNo(ctx, f.FlightNo, f.Origin, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		cabinSeats, err := s.equipFlight(ctx, f, row.params.AircraftID)
+		if err != nil {
+			return nil, err
+		}
+		id, err := s.repo.Create(ctx, f, cabinSeats)
+		if err != nil {
+			return nil, err
+		}
+		imported.Action, imported.FlightID = ImportInsert, &id
+		return imported, nil
+	}
+
+	imported.FlightID = &existing.ID
+	aircraftID := row.params.AircraftID
+	retime := existing.Destination != f.Destination || !existing.DepartureTime.Equal(f.DepartureTime) ||
+		!existing.ArrivalTime.Equal(f.ArrivalTime)
+	reequip := aircraftID != nil && (existing.AircraftID == nil || *existing.AircraftID != *aircraftID)
+	if !retime && !reequip {
+		imported.Action = ImportUnchanged
+		return imported, nil
+	}
+
+	_, sold, err := s.repo.LockInventory(ctx, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status != StatusScheduled || sold > 0 {
+		return nil, fmt.Errorf("%w: flight %d is %s with %d seats sold; change it on its own",
+			ErrInvalidImport, existing.ID, existing.Status, sold)
+	}
+	if retime {
+		existing.Destination, existing.DepartureTime, existing.ArrivalTime = f.Destination, f.DepartureTime, f.ArrivalTime
+		ok, err := s.repo.RescheduleFlight(ctx, existing)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrVersionConflict
+		}
+	}
+	if reequip {
+		aircraft, err := s.fleetService.ActiveAircraft(ctx, *aircraftID)
+		if err != nil {
+			return nil, err
+		}
+		layout, err := s.seatService.GetLayout(ctx, aircraft.CabinLayoutID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.changeEquipment(ctx, existing.ID, changedBy, &aircraft.ID, layout, true); err != nil {
+			return nil, err
+		}
+	}
+	imported.Action = ImportUpdate
+	return imported, nil
 }
 
-// SearchFlights searches for flights based on criteria.
-func (s *Service) SearchFlights(ctx context.Context, origin, destination, dateStr string) ([]Flight, error) {
-	var searchDate time.Time
-	if dateStr != "" {
-		parsedDate, err := time.Parse("2006-01-02", dateStr)
+// flightCSVColumns are the columns of a flight import CSV. aircraft_id may be left out.
+var flightCSVColumns = []string{"flight_no", "origin", "destination", "departure_time", "arrival_time", "aircraft_id"}
+
+// readFlightCSV reads flights from a CSV whose header row names its columns. Rows with an
+// invalid aircraft_id are reported by line; everything else is validated on import.
+func readFlightCSV(r io.Reader) ([]importRow, []ImportError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: the file is empty", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range flightCSVColumns[:5] {
+		if _, ok := index[name]; !ok {
+			return nil, nil, fmt.Errorf("%w: the header row has no %s column", ErrInvalidImport, name)
+		}
+	}
+
+	var rows []importRow
+	importErrors := []ImportError{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return nil, errors.New("invalid date format (expected YYYY-MM-DD)")
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		line, _ := reader.FieldPos(0)
+		value := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := importRow{line: line, params: CreateFlightParams{
+			FlightNo:      value("flight_no"),
+			Origin:        value("origin"),
+			Destination:   value("destination"),
+			DepartureTime: value("departure_time"),
+			ArrivalTime:   value("arrival_time"),
+		}}
+		if v := value("aircraft_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id <= 0 {
+				importErrors = append(importErrors, ImportError{Line: line, Message: fmt.Sprintf("invalid aircraft_id %q", v)})
+				continue
+			}
+			row.params.AircraftID = &id
+		}
+		rows = append(rows, row)
+		if len(rows) > MaxImportFlights {
+			return nil, nil, fmt.Errorf("%w: more than %d flights", ErrInvalidImport, MaxImportFlights)
 		}
-		searchDate = parsedDate
 	}
+	return rows, importErrors, nil
+}
 
-	return s.repo.Search(ctx, origin, destination, searchDate)
+// requireAirports checks that every code is a known airport, wrapping invalid with the first
+// unknown one.
+func (s *Service) requireAirports(ctx context.Context, invalid error, codes ...string) error {
+	for _, code := range codes {
+		if _, err := s.airportService.GetAirport(ctx, code); err != nil {
+			if errors.Is(err, airport.ErrAirportNotFound) {
+				return fmt.Errorf("%w: unknown airport %s", invalid, code)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchFlights returns one page of flights matching the search. Dates and departure windows
+// are in the origin airport's local time.
+func (s *Service) SearchFlights(ctx context.Context, params SearchParams) (*SearchResult, error) {
+	q := searchQuery{
+		origin:      strings.ToUpper(params.Origin),
+		destination: strings.ToUpper(params.Destination),
+		minPrice:    params.MinPrice,
+		maxPrice:    params.MaxPrice,
+		minSeats:    params.MinSeats,
+		sort:        params.Sort,
+		limit:       params.Limit,
+	}
+	if q.sort == "" {
+		q.sort = SortDeparture
+	}
+	if _, ok := searchSortKeys[q.sort]; !ok {
+		return nil, fmt.Errorf("%w: unknown sort %q (expected departure, price or duration)", ErrInvalidSearch, q.sort)
+	}
+	if q.limit == 0 {
+		q.limit = DefaultSearchLimit
+	}
+	if q.minPrice != nil && q.maxPrice != nil && *q.minPrice > *q.maxPrice {
+		return nil, fmt.Errorf("%w: min_price must not exceed max_price", ErrInvalidSearch)
+	}
+
+	for _, w := range []struct {
+		name, value string
+		dest        *string
+	}{{"depart_after", params.DepartAfter, &q.departAfter}, {"depart_before", params.DepartBefore, &q.departBefore}} {
+		if w.value == "" {
+			continue
+		}
+		clock, err := time.Parse("15:04", w.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s format (expected HH:MM)", ErrInvalidSearch, w.name)
+		}
+		*w.dest = clock.Format("15:04")
+	}
+
+	if params.Status != "" {
+		for _, status := range strings.Split(params.Status, ",") {
+			status = strings.ToUpper(strings.TrimSpace(status))
+			if !IsValidStatus(status) {
+				return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidSearch, status)
+			}
+			q.statuses = append(q.statuses, status)
+		}
+	}
+
+	if params.Date != "" {
+		// Without an origin every flight is matched against its own origin's day instead. Flights
+		// from airports missing from the reference data fall back to UTC, as in the repository.
+		loc := time.UTC
+		if q.origin != "" {
+			airportLoc, err := s.airportService.Location(ctx, q.origin)
+			if err != nil && !errors.Is(err, airport.ErrAirportNotFound) {
+				return nil, err
+			}
+			if err == nil {
+				loc = airportLoc
+			}
+		}
+		parsedDate, err := time.ParseInLocation("2006-01-02", params.Date, loc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid date format (expected YYYY-MM-DD)", ErrInvalidSearch)
+		}
+		q.date = parsedDate
+	}
+
+	if params.Cursor != "" {
+		cursor, err := decodeCursor(params.Cursor)
+		if err != nil || cursor.Sort != q.sort {
+			return nil, fmt.Errorf("%w: invalid cursor for this sort", ErrInvalidSearch)
+		}
+		q.after = cursor
+	}
+
+	flights, next, err := s.repo.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	result := &SearchResult{Flights: flights}
+	if next != nil {
+		result.NextCursor = encodeCursor(next)
+	}
+	return result, nil
+}
+
+// encodeCursor turns a search cursor into an opaque URL-safe token.
+func encodeCursor(c *searchCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var c searchCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SearchItineraries finds journeys from the origin to the destination with up to MaxStops
+// connections whose first leg departs on the given day in the origin's local time. Every leg
+// must be open for booking with enough seats and a fare left, and every connection must allow
+// the connecting airport's minimum connection time. Expected times apply to delayed flights.
+func (s *Service) SearchItineraries(ctx context.Context, params ItineraryParams) ([]Itinerary, error) {
+	origin, destination := strings.ToUpper(params.Origin), strings.ToUpper(params.Destination)
+	if origin == destination {
+		return nil, fmt.Errorf("%w: origin and destination must differ", ErrInvalidSearch)
+	}
+	maxStops := MaxItineraryStops
+	if params.MaxStops != nil {
+		maxStops = *params.MaxStops
+	}
+	maxJourney := time.Duration(DefaultMaxJourneyMinutes) * time.Minute
+	if params.MaxJourneyMinutes > 0 {
+		maxJourney = time.Duration(params.MaxJourneyMinutes) * time.Minute
+	}
+	seats := params.Seats
+	if seats == 0 {
+		seats = 1
+	}
+	sortBy := params.Sort
+	if sortBy == "" {
+		sortBy = SortDuration
+	}
+	if sortBy != SortDuration && sortBy != SortPrice {
+		return nil, fmt.Errorf("%w: unknown sort %q (expected duration or price)", ErrInvalidSearch, sortBy)
+	}
+	limit := params.Limit
+	if limit == 0 {
+		limit = DefaultItineraryLimit
+	}
+
+	// Airports missing from the reference data fall back to UTC, as in SearchFlights
+	loc := time.UTC
+	airportLoc, err := s.airportService.Location(ctx, origin)
+	if err != nil && !errors.Is(err, airport.ErrAirportNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		loc = airportLoc
+	}
+	day, err := time.ParseInLocation("2006-01-02", params.Date, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid date format (expected YYYY-MM-DD)", ErrInvalidSearch)
+	}
+
+	// Load the legs stop by stop: departures from the origin on the day, then onward flights
+	// from the airports reached so far. An airport's departures are loaded once, by the first
+	// stop reaching it, whose window covers those of later stops.
+	q := legQuery{origins: []string{origin}, departFrom: day, departTo: day.AddDate(0, 0, 1), minSeats: seats}
+	latest := q.departTo.Add(maxJourney)
+	byOrigin := make(map[string][]FlightResult)
+	loaded := map[string]bool{origin: true}
+	var hubs []string
+	for stop := 0; ; stop++ {
+		if stop == maxStops {
+			q.destination = destination
+		}
+		legs, err := s.repo.ListLegs(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, leg := range legs {
+			byOrigin[leg.Origin] = append(byOrigin[leg.Origin], leg)
+		}
+		if stop == maxStops {
+			break
+		}
+
+		q = legQuery{departTo: latest, minSeats: seats}
+		for _, leg := range legs {
+			if leg.Destination == destination {
+				continue
+			}
+			if !loaded[leg.Destination] {
+				loaded[leg.Destination] = true
+				q.origins = append(q.origins, leg.Destination)
+			}
+			if q.departFrom.IsZero() || leg.arrival().Before(q.departFrom) {
+				q.departFrom = leg.arrival()
+			}
+		}
+		if len(q.origins) == 0 {
+			break
+		}
+		hubs = append(hubs, q.origins...)
+	}
+
+	mct, err := s.airportService.MinConnections(ctx, hubs)
+	if err != nil {
+		return nil, err
+	}
+
+	itineraries := []Itinerary{}
+	visited := map[string]bool{origin: true}
+	var extend func(path []FlightResult)
+	extend = func(path []FlightResult) {
+		last := path[len(path)-1]
+		if last.Destination == destination {
+			itineraries = append(itineraries, newItinerary(path, mct))
+			return
+		}
+		if len(path) > maxStops {
+			return
+		}
+		ready := last.arrival().Add(mct[last.Destination])
+		for _, next := range byOrigin[last.Destination] {
+			if next.departure().Before(ready) || visited[next.Destination] || next.arrival().Sub(path[0].departure()) > maxJourney {
+				continue
+			}
+			visited[next.Destination] = true
+			extend(append(path[:len(path):len(path)], next))
+			delete(visited, next.Destination)
+		}
+	}
+	for _, first := range byOrigin[origin] {
+		if first.arrival().Sub(first.departure()) > maxJourney {
+			continue
+		}
+		visited[first.Destination] = true
+		extend([]FlightResult{first})
+		delete(visited, first.Destination)
+	}
+
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		a, b := &itineraries[i], &itineraries[j]
+		if sortBy == SortPrice && a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		if a.DurationMinutes != b.DurationMinutes {
+			return a.DurationMinutes < b.DurationMinutes
+		}
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.DepartureTime.Before(b.DepartureTime)
+	})
+	if len(itineraries) > limit {
+		itineraries = itineraries[:limit]
+	}
+	return itineraries, nil
+}
+
+// newItinerary assembles an itinerary from connecting legs.
+func newItinerary(legs []FlightResult, mct map[string]time.Duration) Itinerary {
+	first, last := &legs[0], &legs[len(legs)-1]
+	it := Itinerary{
+		Legs:          legs,
+		Connections:   []Connection{},
+		Stops:         len(legs) - 1,
+		DepartureTime: first.departure(),
+		ArrivalTime:   last.arrival(),
+	}
+	it.DurationMinutes = int(it.ArrivalTime.Sub(it.DepartureTime).Minutes())
+	for i := range legs {
+		it.Price += *legs[i].LowestFare
+		if i > 0 {
+			at := legs[i].Origin
+			it.Connections = append(it.Connections, Connection{
+				Airport:        at,
+				Minutes:        int(legs[i].departure().Sub(legs[i-1].arrival()).Minutes()),
+				MinimumMinutes: int(mct[at].Minutes()),
+			})
+		}
+	}
+	it.Price = math.Round(it.Price*100) / 100
+	return it
+}
+
+// CheckItinerary checks that flights, in travel order, can be booked together as one
+// itinerary: each is open for booking and departs from where the previous one lands no sooner
+// than that airport's minimum connection time, no airport is visited twice and the journey
+// lasts at most MaxJourneyMinutes.
+func (s *Service) CheckItinerary(ctx context.Context, legs []*Flight) error {
+	if len(legs) == 0 || len(legs) > MaxItineraryStops+1 {
+		return fmt.Errorf("%w: an itinerary has 1 to %d legs", ErrInvalidItinerary, MaxItineraryStops+1)
+	}
+	hubs := make([]string, 0, len(legs)-1)
+	for _, leg := range legs[:len(legs)-1] {
+		hubs = append(hubs, leg.Destination)
+	}
+	mct, err := s.airportService.MinConnections(ctx, hubs)
+	if err != nil {
+		return err
+	}
+
+	visited := map[string]bool{legs[0].Origin: true}
+	for i, leg := range legs {
+		if !leg.Bookable() {
+			return fmt.Errorf("%w: flight %s is %s", ErrInvalidItinerary, leg.FlightNo, leg.Status)
+		}
+		if i > 0 {
+			prev := legs[i-1]
+			if leg.Origin != prev.Destination {
+				return fmt.Errorf("%w: flight %s departs from %s, not %s where flight %s arrives",
+					ErrInvalidItinerary, leg.FlightNo, leg.Origin, prev.Destination, prev.FlightNo)
+			}
+			if leg.departure().Before(prev.arrival().Add(mct[leg.Origin])) {
+				return fmt.Errorf("%w: the connection at %s is shorter than its minimum of %d minutes",
+					ErrInvalidItinerary, leg.Origin, int(mct[leg.Origin].Minutes()))
+			}
+		}
+		if visited[leg.Destination] {
+			return fmt.Errorf("%w: the itinerary visits %s twice", ErrInvalidItinerary, leg.Destination)
+		}
+		visited[leg.Destination] = true
+	}
+	if legs[len(legs)-1].arrival().Sub(legs[0].departure()) > MaxJourneyMinutes*time.Minute {
+		return fmt.Errorf("%w: the journey lasts more than %d hours", ErrInvalidItinerary, MaxJourneyMinutes/60)
+	}
+	return nil
 }
 
 // GetByID retrieves a flight by its ID.
 func (s *Service) GetByID(ctx context.Context, id int64) (*Flight, error) {
-	return s.repo.GetByID(ctx, id)
+	flight, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if flight == nil {
+		return nil, ErrFlightNotFound
+	}
+	return flight, nil
+}
+
+// UpdateStatus moves a flight to a new status using compare-and-swap on its version
+// and records the transition with the acting user and reason. Flights are cancelled through
+// POST /flights/:id/cancel instead, which also deals with their bookings.
+func (s *Service) UpdateStatus(ctx context.Context, flightID, userID int64, req UpdateStatusRequest) (*Flight, error) {
+	if !IsValidStatus(req.Status) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidStatusUpdate, req.Status)
+	}
+	if req.Status == StatusCancelled {
+		return nil, fmt.Errorf("%w: cancel flights with POST /flights/:id/cancel", ErrInvalidStatusUpdate)
+	}
+	switch req.Status {
+	case StatusDelayed, StatusDiverted:
+		if req.Reason == "" {
+			return nil, fmt.Errorf("%w: reason is required when setting status %s", ErrInvalidStatusUpdate, req.Status)
+		}
+	}
+
+	var estDeparture, estArrival *time.Time
+	if req.EstimatedDepartureTime != "" {
+		t, err := time.Parse(time.RFC3339, req.EstimatedDepartureTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid estimated_departure_time format (expected RFC3339)", ErrInvalidStatusUpdate)
+		}
+		estDeparture = &t
+	}
+	if req.EstimatedArrivalTime != "" {
+		t, err := time.Parse(time.RFC3339, req.EstimatedArrivalTime)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid estimated_arrival_time format (expected RFC3339)", ErrInvalidStatusUpdate)
+		}
+		estArrival = &t
+	}
+
+	var updated *Flight
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetByID(ctx, flightID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrFlightNotFound
+		}
+		if current.Version != req.Version {
+			return ErrVersionConflict
+		}
+		if !CanTransition(current.Status, req.Status) {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current.Status, req.Status)
+		}
+
+		updated, err = s.repo.UpdateStatus(ctx, flightID, req.Version, req.Status, estDeparture, estArrival)
+		if err != nil {
+			return err
+		}
+		if updated == nil {
+			// Someone else bumped the version between our read and write.
+			return ErrVersionConflict
+		}
+
+		_, err = s.repo.CreateStatusChange(ctx, &StatusChange{
+			FlightID:   flightID,
+			FromStatus: current.Status,
+			ToStatus:   updated.Status,
+			Version:    updated.Version,
+			ChangedBy:  &userID,
+			Reason:     req.Reason,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Flight status changed", "flight_id", flightID, "status", updated.Status, "version", updated.Version, "user_id", userID)
+	return updated, nil
+}
+
+// ReviseFlight retimes a flight, and renumbers it if asked, using compare-and-swap on its
+// version. It returns the flight as it was and as it is now; both are the same flight if the
+// request changes nothing. The flight's route stays as it is. Callers run it in a transaction
+// and hold the flight's inventory lock.
+func (s *Service) ReviseFlight(ctx context.Context, flightID int64, req UpdateFlightRequest) (*Flight, *Flight, error) {
+	current, err := s.repo.GetByID(ctx, flightID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current == nil {
+		return nil, nil, ErrFlightNotFound
+	}
+	if current.Version != req.Version {
+		return nil, nil, ErrVersionConflict
+	}
+	if !current.Bookable() {
+		return nil, nil, fmt.Errorf("%w: flight is %s", ErrFlightLocked, current.Status)
+	}
+
+	flightNo := strings.TrimSpace(req.FlightNo)
+	if flightNo == "" {
+		flightNo = current.FlightNo
+	}
+	revised, err := s.newFlight(ctx, CreateFlightParams{
+		FlightNo:      flightNo,
+		Origin:        current.Origin,
+		Destination:   current.Destination,
+		DepartureTime: req.DepartureTime,
+		ArrivalTime:   req.ArrivalTime,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if revised.FlightNo == current.FlightNo && revised.DepartureTime.Equal(current.DepartureTime) &&
+		revised.ArrivalTime.Equal(current.ArrivalTime) {
+		return current, current, nil
+	}
+	if !revised.DepartureTime.After(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: departure_time must be in the future", ErrInvalidFlight)
+	}
+
+	updated := *current
+	updated.FlightNo = revised.FlightNo
+	updated.DepartureTime, updated.ArrivalTime = revised.DepartureTime, revised.ArrivalTime
+	ok, err := s.repo.RescheduleFlight(ctx, &updated)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrVersionConflict
+	}
+
+	s.log.Info("Flight retimed", "flight_id", flightID, "departure_time", updated.DepartureTime, "arrival_time", updated.ArrivalTime, "version", updated.Version)
+	return current, &updated, nil
+}
+
+// CancelFlight cancels a flight using compare-and-swap on its version and records the
+// transition with the acting user and reason. Callers run it in a transaction and hold the
+// flight's inventory lock.
+func (s *Service) CancelFlight(ctx context.Context, flightID, userID int64, req CancelFlightRequest) (*Flight, error) {
+	current, err := s.repo.GetByID(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrFlightNotFound
+	}
+	if current.Version != req.Version {
+		return nil, ErrVersionConflict
+	}
+	if !CanTransition(current.Status, StatusCancelled) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current.Status, StatusCancelled)
+	}
+
+	updated, err := s.repo.UpdateStatus(ctx, flightID, req.Version, StatusCancelled, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrVersionConflict
+	}
+	_, err = s.repo.CreateStatusChange(ctx, &StatusChange{
+		FlightID:   flightID,
+		FromStatus: current.Status,
+		ToStatus:   updated.Status,
+		Version:    updated.Version,
+		ChangedBy:  &userID,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Flight cancelled", "flight_id", flightID, "version", updated.Version, "user_id", userID)
+	return updated, nil
+}
+
+// GetStatusHistory returns the recorded status transitions of a flight.
+func (s *Service) GetStatusHistory(ctx context.Context, flightID int64) ([]StatusChange, error) {
+	f, err := s.repo.GetByID(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, ErrFlightNotFound
+	}
+	return s.repo.ListStatusHistory(ctx, flightID)
+}
+
+// GetFares returns a flight's fare buckets with the seats each class can still sell.
+func (s *Service) GetFares(ctx context.Context, flightID int64) ([]FareBucket, error) {
+	if _, err := s.GetByID(ctx, flightID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListFareBuckets(ctx, flightID)
+}
+
+// UpdateFares replaces a flight's fare buckets. Each cabin's allocations must add up to its
+// seats in the flight's layout, and the seats already sold in each class must still fit under
+// nesting.
+func (s *Service) UpdateFares(ctx context.Context, flightID int64, req UpdateFaresRequest) ([]FareBucket, error) {
+	classes, err := s.repo.ListBookingClasses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]BookingClass, len(classes))
+	for _, bc := range classes {
+		byCode[bc.Code] = bc
+	}
+
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		if _, _, err := s.repo.LockInventory(ctx, flightID); err != nil {
+			return err
+		}
+		layout, err := s.seatService.FlightLayout(ctx, flightID)
+		if err != nil {
+			return err
+		}
+		current, err := s.repo.LockFareBuckets(ctx, flightID)
+		if err != nil {
+			return err
+		}
+
+		buckets := make([]FareBucket, 0, len(req.Buckets))
+		allocated := make(map[string]int)
+		for _, br := range req.Buckets {
+			code := strings.ToUpper(br.BookingClass)
+			bc, ok := byCode[code]
+			if !ok {
+				return fmt.Errorf("%w: unknown booking class %s", ErrInvalidFares, code)
+			}
+			if FindBucket(buckets, code) != nil {
+				return fmt.Errorf("%w: booking class %s is listed twice", ErrInvalidFares, code)
+			}
+			b := FareBucket{
+				BookingClass: code,
+				Cabin:        bc.Cabin,
+				Rank:         bc.Rank,
+				Name:         bc.Name,
+				Allocation:   br.Allocation,
+				Fare:         br.Fare,
+				Closed:       br.Closed,
+			}
+			if old := FindBucket(current, code); old != nil {
+				b.Sold = old.Sold
+			}
+			buckets = append(buckets, b)
+			allocated[b.Cabin] += b.Allocation
+		}
+		for _, old := range current {
+			if old.Sold > 0 && FindBucket(buckets, old.BookingClass) == nil {
+				return fmt.Errorf("%w: booking class %s has %d seats sold", ErrFaresConflict, old.BookingClass, old.Sold)
+			}
+		}
+		seats := layout.CabinSeats()
+		for _, cabin := range []string{CabinFirst, CabinBusiness, CabinEconomy} {
+			if allocated[cabin] != seats[cabin] {
+				return fmt.Errorf("%w: cabin %s allocations add up to %d but it has %d seats", ErrInvalidFares, cabin, allocated[cabin], seats[cabin])
+			}
+		}
+
+		sort.Slice(buckets, func(i, j int) bool {
+			if buckets[i].Cabin != buckets[j].Cabin {
+				return buckets[i].Cabin < buckets[j].Cabin
+			}
+			return buckets[i].Rank < buckets[j].Rank
+		})
+		if !NestingFits(buckets) {
+			return fmt.Errorf("%w: a cabin's lower classes have sold more seats than they are allocated", ErrFaresConflict)
+		}
+		return s.repo.ReplaceFareBuckets(ctx, flightID, buckets)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Fare buckets updated", "flight_id", flightID, "buckets", len(req.Buckets))
+	return s.repo.ListFareBuckets(ctx, flightID)
+}
+
+// AssignAircraft swaps the aircraft operating a flight. Capacity, cabins and fare buckets follow
+// the new aircraft's seat configuration, and tickets whose seat no longer exists in their
+// cabin are flagged for reseating. The new aircraft must seat every passenger already sold.
+func (s *Service) AssignAircraft(ctx context.Context, flightID, userID int64, req AssignAircraftRequest) (*EquipmentChange, error) {
+	var change *EquipmentChange
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		aircraft, err := s.fleetService.ActiveAircraft(ctx, req.AircraftID)
+		if err != nil {
+			return err
+		}
+		layout, err := s.seatService.GetLayout(ctx, aircraft.CabinLayoutID)
+		if err != nil {
+			return err
+		}
+		change, err = s.changeEquipment(ctx, flightID, &userID, &aircraft.ID, layout, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Flight equipment changed", "flight_id", flightID, "aircraft_id", req.AircraftID,
+		"capacity", change.ToCapacity, "reseat", change.ReseatCount, "user_id", userID)
+	return change, nil
+}
+
+// AssignLayout reconfigures the seats of a flight's aircraft. Unlike an aircraft swap, every
+// assigned seat must still exist in its cabin.
+func (s *Service) AssignLayout(ctx context.Context, flightID, userID int64, req AssignLayoutRequest) (*seating.SeatMap, error) {
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		layout, err := s.seatService.GetLayout(ctx, req.CabinLayoutID)
+		if err != nil {
+			return err
+		}
+		// Lock before reading the aircraft so a concurrent swap cannot be undone
+		if _, _, err := s.repo.LockInventory(ctx, flightID); err != nil {
+			return err
+		}
+		f, err := s.GetByID(ctx, flightID)
+		if err != nil {
+			return err
+		}
+		_, err = s.changeEquipment(ctx, flightID, &userID, f.AircraftID, layout, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Cabin layout assigned", "flight_id", flightID, "layout_id", req.CabinLayoutID, "user_id", userID)
+	return s.seatService.GetSeatMap(ctx, flightID)
+}
+
+// ListEquipmentChanges returns the recorded equipment changes of a flight.
+func (s *Service) ListEquipmentChanges(ctx context.Context, flightID int64) ([]EquipmentChange, error) {
+	if _, err := s.GetByID(ctx, flightID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListEquipmentChanges(ctx, flightID)
+}
+
+// changeEquipment moves a flight onto an aircraft and cabin layout, resizing its inventory and
+// fare buckets and flagging displaced tickets, or failing on them if strict is set. It locks
+// the flight's inventory, so it serializes with bookings, and must run inside a transaction.
+func (s *Service) changeEquipment(ctx context.Context, flightID int64, changedBy *int64, aircraftID *int64, layout *seating.CabinLayout, strict bool) (*EquipmentChange, error) {
+	capacity, sold, err := s.repo.LockInventory(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.GetByID(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	switch f.Status {
+	case StatusDeparted, StatusArrived, StatusDiverted, StatusCancelled:
+		return nil, fmt.Errorf("%w: flight is %s", ErrEquipmentLocked, f.Status)
+	}
+
+	seatCount := layout.SeatCount()
+	if sold > seatCount {
+		return nil, fmt.Errorf("%w: %s has %d seats but %d are sold", ErrEquipmentTooSmall, layout.Name, seatCount, sold)
+	}
+
+	displaced, err := s.seatService.Displaced(ctx, flightID, layout)
+	if err != nil {
+		return nil, err
+	}
+	if strict && len(displaced) > 0 {
+		return nil, fmt.Errorf("%w: seat %s is assigned to a ticket but does not exist in cabin %s of layout %s",
+			seating.ErrLayoutConflict, displaced[0].SeatNo, displaced[0].Cabin, layout.Name)
+	}
+	reseat := make([]int64, len(displaced))
+	for i, t := range displaced {
+		reseat[i] = t.TicketID
+	}
+
+	if err := s.repo.SetEquipment(ctx, flightID, aircraftID, layout.ID, seatCount); err != nil {
+		return nil, err
+	}
+	classes, err := s.repo.ListBookingClasses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := s.repo.LockFareBuckets(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceFareBuckets(ctx, flightID, ResizeBuckets(buckets, classes, layout.CabinSeats(), f.BasePrice)); err != nil {
+		return nil, err
+	}
+	if err := s.seatService.FlagReseat(ctx, flightID, reseat); err != nil {
+		return nil, err
+	}
+
+	change := &EquipmentChange{
+		FlightID:       flightID,
+		FromAircraftID: f.AircraftID,
+		ToAircraftID:   aircraftID,
+		FromLayoutID:   f.CabinLayoutID,
+		ToLayoutID:     layout.ID,
+		FromCapacity:   capacity,
+		ToCapacity:     seatCount,
+		ReseatCount:    len(reseat),
+		ReseatTickets:  reseat,
+		ChangedBy:      changedBy,
+	}
+	if err := s.repo.CreateEquipmentChange(ctx, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// CreateSchedule creates a recurring schedule and generates its flights within the horizon.
+func (s *Service) CreateSchedule(ctx context.Context, userID int64, req ScheduleRequest) (*ScheduleChange, error) {
+	sched, layout, err := s.newSchedule(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	sched.CreatedBy = &userID
+
+	change := &ScheduleChange{Schedule: sched, Updated: []int64{}, Cancelled: []int64{}, Flagged: []int64{}}
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateSchedule(ctx, sched); err != nil {
+			return err
+		}
+		change.Generated, err = s.generateFlights(ctx, sched, layout)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Schedule created", "schedule_id", sched.ID, "flight_no", sched.FlightNo, "generated", change.Generated, "user_id", userID)
+	return change, nil
+}
+
+// UpdateSchedule edits a schedule using compare-and-swap on its version and carries the edit
+// over to its upcoming flights: unbooked flights follow the schedule, booked ones are flagged
+// for review, and days newly operated get flights.
+func (s *Service) UpdateSchedule(ctx context.Context, id, userID int64, req ScheduleRequest) (*ScheduleChange, error) {
+	if req.Version == 0 {
+		return nil, fmt.Errorf("%w: version is required", ErrInvalidSchedule)
+	}
+	sched, layout, err := s.newSchedule(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	sched.ID = id
+
+	change := &ScheduleChange{Schedule: sched, Updated: []int64{}, Cancelled: []int64{}, Flagged: []int64{}}
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetScheduleForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrScheduleNotFound
+		}
+		ok, err := s.repo.UpdateSchedule(ctx, sched, req.Version)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrScheduleConflict
+		}
+
+		if err := s.applySchedule(ctx, userID, sched, layout, change); err != nil {
+			return err
+		}
+		change.Generated, err = s.generateFlights(ctx, sched, layout)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Schedule updated", "schedule_id", id, "version", sched.Version, "updated", len(change.Updated),
+		"cancelled", len(change.Cancelled), "flagged", len(change.Flagged), "generated", change.Generated, "user_id", userID)
+	return change, nil
+}
+
+// GetSchedule retrieves a schedule by ID.
+func (s *Service) GetSchedule(ctx context.Context, id int64) (*Schedule, error) {
+	sched, err := s.repo.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sched == nil {
+		return nil, ErrScheduleNotFound
+	}
+	return sched, nil
+}
+
+// ListSchedules returns all schedules, or only current ones.
+func (s *Service) ListSchedules(ctx context.Context, params ListSchedulesParams) ([]Schedule, error) {
+	return s.repo.ListSchedules(ctx, params.Current)
+}
+
+// ListScheduleFlights returns the flights generated from a schedule.
+func (s *Service) ListScheduleFlights(ctx context.Context, id int64, params ScheduleFlightsParams) ([]Flight, error) {
+	if _, err := s.GetSchedule(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListScheduleFlights(ctx, id, params.ReviewRequired)
+}
+
+// ClearReview clears a flight's review flag once the schedule change has been dealt with.
+func (s *Service) ClearReview(ctx context.Context, flightID, userID int64) (*Flight, error) {
+	f, err := s.GetByID(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetReviewRequired(ctx, []int64{flightID}, false); err != nil {
+		return nil, err
+	}
+	f.ReviewRequired = false
+
+	s.log.Info("Flight review cleared", "flight_id", flightID, "user_id", userID)
+	return f, nil
+}
+
+// GenerateScheduledFlights rolls every current schedule's flights forward to the end of the
+// horizon, returning how many flights were created. A schedule that fails is logged and left
+// for the next run without holding back the others; the failures are returned joined.
+func (s *Service) GenerateScheduledFlights(ctx context.Context) (int, error) {
+	schedules, err := s.repo.ListSchedules(ctx, true)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	var errs []error
+	for _, sched := range schedules {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		var n int
+		err := s.txManager.Run(ctx, func(ctx context.Context) error {
+			locked, err := s.repo.GetScheduleForUpdate(ctx, sched.ID)
+			if err != nil {
+				return err
+			}
+			layout, err := s.scheduleLayout(ctx, locked)
+			if err != nil {
+				return err
+			}
+			n, err = s.generateFlights(ctx, locked, layout)
+			return err
+		})
+		if err != nil {
+			s.log.Error("Schedule failed to roll forward", "schedule_id", sched.ID, "flight_no", sched.FlightNo, "error", err)
+			errs = append(errs, fmt.Errorf("failed to generate flights for schedule %d: %w", sched.ID, err))
+			continue
+		}
+		created += n
+	}
+	return created, errors.Join(errs...)
+}
+
+// newSchedule validates a schedule request and returns the schedule with the cabin layout its
+// flights are to be generated with, or nil for the default all-economy cabin.
+func (s *Service) newSchedule(ctx context.Context, req ScheduleRequest) (*Schedule, *seating.CabinLayout, error) {
+	sched := &Schedule{
+		FlightNo:       strings.ToUpper(strings.TrimSpace(req.FlightNo)),
+		Origin:         strings.ToUpper(req.Origin),
+		Destination:    strings.ToUpper(req.Destination),
+		BlockMinutes:   req.BlockMinutes,
+		DaysOfWeek:     req.DaysOfWeek,
+		EffectiveFrom:  req.EffectiveFrom,
+		EffectiveTo:    req.EffectiveTo,
+		AircraftTypeID: req.AircraftTypeID,
+		CabinLayoutID:  req.CabinLayoutID,
+		BasePrice:      req.BasePrice,
+	}
+	if sched.BasePrice == 0 {
+		sched.BasePrice = DefaultBasePrice
+	}
+
+	if sched.FlightNo == "" {
+		return nil, nil, fmt.Errorf("%w: flight_no is required", ErrInvalidSchedule)
+	}
+	if sched.Origin == sched.Destination {
+		return nil, nil, fmt.Errorf("%w: origin and destination cannot be the same", ErrInvalidSchedule)
+	}
+	clock, err := time.Parse("15:04", req.DepartureLocal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid departure_local format (expected HH:MM)", ErrInvalidSchedule)
+	}
+	sched.DepartureLocal = clock.Format("15:04")
+
+	operated := false
+	for i, ch := range sched.DaysOfWeek {
+		if ch == '.' {
+			continue
+		}
+		if ch != rune('1'+i) {
+			return nil, nil, fmt.Errorf("%w: days_of_week must give each day Monday first as its digit or '.', e.g. 1.3.5..", ErrInvalidSchedule)
+		}
+		operated = true
+	}
+	if !operated {
+		return nil, nil, fmt.Errorf("%w: days_of_week must include at least one day", ErrInvalidSchedule)
+	}
+
+	from, err := time.Parse("2006-01-02", req.EffectiveFrom)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid effective_from format (expected YYYY-MM-DD)", ErrInvalidSchedule)
+	}
+	to, err := time.Parse("2006-01-02", req.EffectiveTo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid effective_to format (expected YYYY-MM-DD)", ErrInvalidSchedule)
+	}
+	if to.Before(from) {
+		return nil, nil, fmt.Errorf("%w: effective_to must not be before effective_from", ErrInvalidSchedule)
+	}
+
+	if err := s.requireAirports(ctx, ErrInvalidSchedule, sched.Origin, sched.Destination); err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.fleetService.GetType(ctx, sched.AircraftTypeID); err != nil {
+		return nil, nil, err
+	}
+	layout, err := s.scheduleLayout(ctx, sched)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sched, layout, nil
+}
+
+// scheduleLayout returns the cabin layout of a schedule's flights, or nil if it has none.
+func (s *Service) scheduleLayout(ctx context.Context, sched *Schedule) (*seating.CabinLayout, error) {
+	if sched.CabinLayoutID == nil {
+		return nil, nil
+	}
+	return s.seatService.GetLayout(ctx, *sched.CabinLayoutID)
+}
+
+// generateFlights creates a schedule's missing flights from today, in the origin's time zone,
+// to the end of the horizon. Days that already have a flight, even a cancelled one, are
+// skipped, so running it again creates no duplicates. Callers hold the schedule's lock.
+func (s *Service) generateFlights(ctx context.Context, sched *Schedule, layout *seating.CabinLayout) (int, error) {
+	loc, err := s.airportService.Location(ctx, sched.Origin)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	local := now.In(loc)
+	from := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, ScheduleHorizonDays)
+
+	existing, err := s.repo.ListScheduleDates(ctx, sched.ID, from.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+
+	var cabinSeats map[string]int
+	totalSeats := 0
+	if layout != nil {
+		cabinSeats = layout.CabinSeats()
+		totalSeats = layout.SeatCount()
+	}
+
+	created := 0
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := date.Format("2006-01-02")
+		if existing[day] || !sched.Operates(date) {
+			continue
+		}
+		departure, arrival := sched.Times(date, loc)
+		if !departure.After(now) {
+			continue
+		}
+
+		f := &Flight{
+			FlightNo:      sched.FlightNo,
+			Origin:        sched.Origin,
+			Destination:   sched.Destination,
+			DepartureTime: departure,
+			ArrivalTime:   arrival,
+			Status:        StatusScheduled,
+			TotalSeats:    totalSeats,
+			BasePrice:     sched.BasePrice,
+			ScheduleID:    &sched.ID,
+			ScheduleDate:  &day,
+		}
+		if layout != nil {
+			f.CabinLayoutID = &layout.ID
+		}
+		if _, err := s.repo.Create(ctx, f, cabinSeats); err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// applySchedule brings a schedule's upcoming flights in line with it after an edit. Flights
+// nobody has booked follow the schedule: they move to its new times, route and seats, or are
+// cancelled if their day is no longer operated. Booked flights, and flights already past
+// SCHEDULED, are left as they are and flagged for review where they differ.
+func (s *Service) applySchedule(ctx context.Context, userID int64, sched *Schedule, layout *seating.CabinLayout, change *ScheduleChange) error {
+	loc, err := s.airportService.Location(ctx, sched.Origin)
+	if err != nil {
+		return err
+	}
+	flights, sold, err := s.repo.LockUpcomingScheduleFlights(ctx, sched.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range flights {
+		f := &flights[i]
+		date, err := time.Parse("2006-01-02", *f.ScheduleDate)
+		if err != nil {
+			return fmt.Errorf("flight %d has an invalid schedule date: %w", f.ID, err)
+		}
+		departure, arrival := sched.Times(date, loc)
+		operates := sched.Operates(date) && departure.After(now)
+		retime := f.FlightNo != sched.FlightNo || f.Origin != sched.Origin || f.Destination != sched.Destination ||
+			!f.DepartureTime.Equal(departure) || !f.ArrivalTime.Equal(arrival)
+		reequip := layout != nil && (f.CabinLayoutID == nil || *f.CabinLayoutID != layout.ID)
+		if operates && !retime && !reequip {
+			continue
+		}
+
+		if f.Status != StatusScheduled || sold[f.ID] > 0 {
+			change.Flagged = append(change.Flagged, f.ID)
+			continue
+		}
+
+		if !operates {
+			updated, err := s.repo.UpdateStatus(ctx, f.ID, f.Version, StatusCancelled, nil, nil)
+			if err != nil {
+				return err
+			}
+			if updated == nil {
+				return ErrVersionConflict
+			}
+			_, err = s.repo.CreateStatusChange(ctx, &StatusChange{
+				FlightID:   f.ID,
+				FromStatus: f.Status,
+				ToStatus:   updated.Status,
+				Version:    updated.Version,
+				ChangedBy:  &userID,
+				Reason:     "Schedule no longer operates on this day",
+			})
+			if err != nil {
+				return err
+			}
+			change.Cancelled = append(change.Cancelled, f.ID)
+			continue
+		}
+
+		if retime {
+			f.FlightNo, f.Origin, f.Destination = sched.FlightNo, sched.Origin, sched.Destination
+			f.DepartureTime, f.ArrivalTime = departure, arrival
+			ok, err := s.repo.RescheduleFlight(ctx, f)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrVersionConflict
+			}
+		}
+		if reequip {
+			if _, err := s.changeEquipment(ctx, f.ID, &userID, f.AircraftID, layout, true); err != nil {
+				return err
+			}
+		}
+		change.Updated = append(change.Updated, f.ID)
+	}
+
+	if len(change.Flagged) > 0 {
+		return s.repo.SetReviewRequired(ctx, change.Flagged, true)
+	}
+	return nil
 }
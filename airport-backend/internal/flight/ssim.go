@@ -0,0 +1,221 @@
+package flight
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSIM chapter 7 files are made of fixed-width records of 200 bytes, the first byte giving the
+// record type: 1 header, 2 carrier, 3 flight leg, 4 segment data, 5 trailer and 0 padding.
+// Imports read the carrier records, for their time mode, and the flight leg records.
+
+// ssimTitle opens the header record of every SSIM file.
+const ssimTitle = "1AIRLINE STANDARD SCHEDULE DATA SET"
+
+// ssimRecordLength is the width of an SSIM record; shorter lines are padded with blanks.
+const ssimRecordLength = 200
+
+// ssimLeg is a flight leg record: one leg of a flight operated on some days of a period.
+type ssimLeg struct {
+	flightNo    string
+	origin      string
+	destination string
+	from, to    time.Time // Period of operation: the first and last flight dates
+	days        string    // Days of operation, "1" (Monday) to "7" (Sunday) in place or blank
+	fortnightly bool      // Operated every other week of the period
+
+	departure, arrival   int           // Minutes past midnight on the departure and arrival dates
+	depOffset, arrOffset time.Duration // Offsets of local times from UTC; zero for UTC times
+	depDays, arrDays     int           // Date variations: departure and arrival dates after the flight date
+}
+
+// readSSIM reads the flight legs of an SSIM file and expands each into a flight per operating
+// day. Days that have already departed by now are skipped and counted. Invalid leg records are
+// reported by line.
+func readSSIM(r io.Reader, now time.Time) ([]importRow, []ImportError, int, error) {
+	scanner := bufio.NewScanner(r)
+	var rows []importRow
+	importErrors := []ImportError{}
+	skipped := 0
+	utc := false
+
+	for line := 1; scanner.Scan(); line++ {
+		record := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		if len(record) < ssimRecordLength {
+			record += strings.Repeat(" ", ssimRecordLength-len(record))
+		}
+
+		switch record[0] {
+		case '2':
+			// Time mode: U for UTC, L for local times
+			utc = record[1] == 'U'
+		case '3':
+			leg, err := parseSSIMLeg(record, utc)
+			if err != nil {
+				importErrors = append(importErrors, ImportError{Line: line, Message: err.Error()})
+				continue
+			}
+			legRows, past := leg.flights(line, now)
+			rows = append(rows, legRows...)
+			skipped += past
+			if len(rows) > MaxImportFlights {
+				return nil, nil, 0, fmt.Errorf("%w: more than %d flights", ErrInvalidImport, MaxImportFlights)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, 0, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return rows, importErrors, skipped, nil
+}
+
+// parseSSIMLeg reads a flight leg record. utc tells whether the carrier gives its times in UTC
+// rather than local time.
+func parseSSIMLeg(record string, utc bool) (*ssimLeg, error) {
+	field := func(from, to int) string { return strings.TrimSpace(record[from-1 : to]) }
+
+	airline := field(3, 5)
+	number, err := strconv.Atoi(field(6, 9))
+	if airline == "" || err != nil || number <= 0 {
+		return nil, errors.New("invalid airline designator or flight number")
+	}
+	leg := &ssimLeg{
+		flightNo:    fmt.Sprintf("%s%d%s", airline, number, field(2, 2)),
+		origin:      field(37, 39),
+		destination: field(55, 57),
+		days:        record[28:35],
+	}
+
+	if leg.from, err = parseSSIMDate(field(15, 21)); err != nil {
+		return nil, fmt.Errorf("invalid period of operation: %v", err)
+	}
+	if leg.to, err = parseSSIMDate(field(22, 28)); err != nil {
+		return nil, fmt.Errorf("invalid period of operation: %v", err)
+	}
+	if leg.to.Before(leg.from) {
+		return nil, errors.New("period of operation ends before it starts")
+	}
+	for i := 0; i < 7; i++ {
+		if leg.days[i] != ' ' && leg.days[i] != byte('1'+i) {
+			return nil, fmt.Errorf("invalid days of operation %q", leg.days)
+		}
+	}
+	switch field(36, 36) {
+	case "", "1":
+	case "2":
+		leg.fortnightly = true
+	default:
+		return nil, fmt.Errorf("unsupported frequency rate %q", field(36, 36))
+	}
+
+	if leg.departure, err = parseSSIMTime(field(40, 43)); err != nil {
+		return nil, fmt.Errorf("invalid departure time: %v", err)
+	}
+	if leg.arrival, err = parseSSIMTime(field(62, 65)); err != nil {
+		return nil, fmt.Errorf("invalid arrival time: %v", err)
+	}
+	if !utc {
+		if leg.depOffset, err = parseSSIMOffset(field(48, 52)); err != nil {
+			return nil, fmt.Errorf("invalid departure time variation: %v", err)
+		}
+		if leg.arrOffset, err = parseSSIMOffset(field(66, 70)); err != nil {
+			return nil, fmt.Errorf("invalid arrival time variation: %v", err)
+		}
+	}
+	if leg.depDays, err = parseSSIMDateVariation(record[192]); err != nil {
+		return nil, fmt.Errorf("invalid departure date variation: %v", err)
+	}
+	if leg.arrDays, err = parseSSIMDateVariation(record[193]); err != nil {
+		return nil, fmt.Errorf("invalid arrival date variation: %v", err)
+	}
+	return leg, nil
+}
+
+// flights expands the leg into a flight per operating day of its period. Flights that have
+// already departed by now are left out and counted.
+func (l *ssimLeg) flights(line int, now time.Time) ([]importRow, int) {
+	var rows []importRow
+	skipped := 0
+	for date := l.from; !date.After(l.to); date = date.AddDate(0, 0, 1) {
+		day := int(date.Weekday()+6)%7 + 1 // Monday is 1
+		if l.days[day-1] == ' ' {
+			continue
+		}
+		if l.fortnightly && int(date.Sub(l.from).Hours()/24)/7%2 == 1 {
+			continue
+		}
+
+		departure := date.AddDate(0, 0, l.depDays).Add(time.Duration(l.departure)*time.Minute - l.depOffset)
+		arrival := date.AddDate(0, 0, l.arrDays).Add(time.Duration(l.arrival)*time.Minute - l.arrOffset)
+		if !departure.After(now) {
+			skipped++
+			continue
+		}
+		rows = append(rows, importRow{line: line, params: CreateFlightParams{
+			FlightNo:      l.flightNo,
+			Origin:        l.origin,
+			Destination:   l.destination,
+			DepartureTime: departure.Format(time.RFC3339),
+			ArrivalTime:   arrival.Format(time.RFC3339),
+		}})
+	}
+	return rows, skipped
+}
+
+// parseSSIMDate parses a date such as 27MAR25 as midnight UTC.
+func parseSSIMDate(value string) (time.Time, error) {
+	if strings.HasPrefix(value, "00XXX") {
+		return time.Time{}, errors.New("open-ended periods are not supported")
+	}
+	return time.Parse("02Jan06", value)
+}
+
+// parseSSIMTime parses a time of day such as 0730 as minutes past midnight.
+func parseSSIMTime(value string) (int, error) {
+	if len(value) != 4 {
+		return 0, fmt.Errorf("%q is not HHMM", value)
+	}
+	hours, errH := strconv.Atoi(value[:2])
+	minutes, errM := strconv.Atoi(value[2:])
+	if errH != nil || errM != nil || hours > 24 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("%q is not HHMM", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// parseSSIMOffset parses a UTC/local time variation such as +0100 or -0530.
+func parseSSIMOffset(value string) (time.Duration, error) {
+	if len(value) != 5 || (value[0] != '+' && value[0] != '-') {
+		return 0, fmt.Errorf("%q is not +HHMM or -HHMM", value)
+	}
+	minutes, err := parseSSIMTime(value[1:])
+	if err != nil || minutes > 14*60 {
+		return 0, fmt.Errorf("%q is not +HHMM or -HHMM", value)
+	}
+	offset := time.Duration(minutes) * time.Minute
+	if value[0] == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// parseSSIMDateVariation parses a date variation: a digit of days later, or A for the day before.
+func parseSSIMDateVariation(value byte) (int, error) {
+	switch {
+	case value == ' ':
+		return 0, nil
+	case value == 'A':
+		return -1, nil
+	case value >= '0' && value <= '9':
+		return int(value - '0'), nil
+	}
+	return 0, fmt.Errorf("%q is not a digit or A", value)
+}
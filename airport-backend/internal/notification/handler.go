@@ -0,0 +1,53 @@
+package notification
+
+import (
+	"airport-system/platform/apperror"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages HTTP requests for notifications.
+type Handler struct {
+	Service *Service
+}
+
+// NewHandler creates a new notification handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{Service: service}
+}
+
+// List handles listing the caller's notifications.
+func (h *Handler) List(c *gin.Context) {
+	var params ListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	notifications, err := h.Service.ListMine(c.Request.Context(), c.GetInt64("userID"), params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkRead handles marking one of the caller's notifications read.
+func (h *Handler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	n, err := h.Service.MarkRead(c.Request.Context(), c.GetInt64("userID"), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, n)
+}
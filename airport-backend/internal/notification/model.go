@@ -0,0 +1,31 @@
+package notification
+
+import "time"
+
+// Notification kinds.
+const (
+	KindFlightRetimed     = "FLIGHT_RETIMED"
+	KindFlightCancelled   = "FLIGHT_CANCELLED"
+	KindWaitlistCancelled = "WAITLIST_CANCELLED"
+)
+
+// DefaultListLimit caps the notifications listed when no limit is given.
+const DefaultListLimit = 50
+
+// Notification is a message to an account holder about a change to their bookings.
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"-"`
+	Kind      string     `json:"kind"`
+	FlightID  *int64     `json:"flight_id,omitempty"`
+	TicketID  *int64     `json:"ticket_id,omitempty"` // Ticket the message is about, if any
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ListParams filters the caller's notifications.
+type ListParams struct {
+	Unread bool `form:"unread"`                                  // Only notifications not marked read yet
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=200"` // DefaultListLimit otherwise
+}
@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"airport-system/platform/database"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Repository handles database interactions for notifications.
+type Repository struct {
+	DB *sql.DB
+}
+
+// NewRepository creates a new notification repository.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{DB: db}
+}
+
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+// notificationColumns is the column list scanned by scanNotification.
+const notificationColumns = `id, user_id, kind, flight_id, ticket_id, message, read_at, created_at`
+
+func scanNotification(row interface{ Scan(dest ...any) error }, n *Notification) error {
+	return row.Scan(&n.ID, &n.UserID, &n.Kind, &n.FlightID, &n.TicketID, &n.Message, &n.ReadAt, &n.CreatedAt)
+}
+
+// Create inserts a notification.
+func (r *Repository) Create(ctx context.Context, n *Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, kind, flight_id, ticket_id, message)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query, n.UserID, n.Kind, n.FlightID, n.TicketID, n.Message).
+		Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns a user's notifications, newest first.
+func (r *Repository) ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := scanNotification(rows, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// MarkRead marks one of a user's notifications read, keeping the time it was first read, and
+// returns it. It returns nil if the user has no such notification.
+func (r *Repository) MarkRead(ctx context.Context, userID, id int64) (*Notification, error) {
+	query := `
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + notificationColumns
+	var n Notification
+	if err := scanNotification(r.executor(ctx).QueryRowContext(ctx, query, id, userID), &n); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	return &n, nil
+}
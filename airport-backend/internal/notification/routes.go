@@ -0,0 +1,15 @@
+package notification
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes sets up the notification routes.
+func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	notificationGroup := r.Group("/notifications")
+	notificationGroup.Use(authMiddleware)
+	{
+		notificationGroup.GET("", h.List)
+		notificationGroup.POST("/:id/read", h.MarkRead)
+	}
+}
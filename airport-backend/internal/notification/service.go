@@ -0,0 +1,57 @@
+package notification
+
+import (
+	"airport-system/platform/apperror"
+	"context"
+	"log/slog"
+)
+
+var (
+	// ErrNotificationNotFound is returned when the notification does not exist or belongs to another user.
+	ErrNotificationNotFound = apperror.NotFound("notification_not_found", "notification not found")
+)
+
+// Service handles business logic for notifications.
+type Service struct {
+	repo *Repository
+	log  *slog.Logger
+}
+
+// NewService creates a new notification service.
+func NewService(repo *Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Notify records a notification for its user. Inside a transaction it is only delivered if
+// the transaction commits.
+func (s *Service) Notify(ctx context.Context, n *Notification) error {
+	if err := s.repo.Create(ctx, n); err != nil {
+		return err
+	}
+	s.log.Info("User notified", "notification_id", n.ID, "user_id", n.UserID, "kind", n.Kind)
+	return nil
+}
+
+// ListMine returns the user's notifications, newest first.
+func (s *Service) ListMine(ctx context.Context, userID int64, params ListParams) ([]Notification, error) {
+	limit := params.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	return s.repo.ListByUser(ctx, userID, params.Unread, limit)
+}
+
+// MarkRead marks one of the user's notifications read.
+func (s *Service) MarkRead(ctx context.Context, userID, id int64) (*Notification, error) {
+	n, err := s.repo.MarkRead(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return nil, ErrNotificationNotFound
+	}
+	return n, nil
+}
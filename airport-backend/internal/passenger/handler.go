@@ -0,0 +1,98 @@
+package passenger
+
+import (
+	"airport-system/platform/apperror"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages HTTP requests for passenger profiles.
+type Handler struct {
+	Service *Service
+}
+
+// NewHandler creates a new passenger handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{Service: service}
+}
+
+// GetMe handles retrieving the caller's passenger profile.
+func (h *Handler) GetMe(c *gin.Context) {
+	profile, err := h.Service.GetMyProfile(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateMe handles creating or replacing the caller's passenger profile.
+func (h *Handler) UpdateMe(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	profile, err := h.Service.UpdateProfile(c.Request.Context(), c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ListCompanions handles listing the travellers the caller can book for.
+func (h *Handler) ListCompanions(c *gin.Context) {
+	travellers, err := h.Service.ListTravellers(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, travellers)
+}
+
+// AddCompanion handles adding a companion traveller.
+func (h *Handler) AddCompanion(c *gin.Context) {
+	var req CompanionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	companion, err := h.Service.AddCompanion(c.Request.Context(), c.GetInt64("userID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, companion)
+}
+
+// UpdateCompanion handles replacing a companion's details.
+func (h *Handler) UpdateCompanion(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req CompanionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	companion, err := h.Service.UpdateCompanion(c.Request.Context(), c.GetInt64("userID"), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, companion)
+}
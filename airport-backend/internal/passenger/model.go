@@ -2,12 +2,36 @@ package passenger
 
 import "time"
 
-// Passenger represents a passenger profile.
+// DateLayout is the format of dates in passenger requests.
+const DateLayout = "2006-01-02"
+
+// Passenger represents a traveller profile. Each user account has one primary profile
+// (the account holder) and may hold companion profiles for people they book for.
 type Passenger struct {
-	ID         int64     `json:"id"`
-	UserID     int64     `json:"user_id"`
-	PassportNo string    `json:"passport_no"`
-	Phone      string    `json:"phone"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID             int64      `json:"id"`
+	UserID         int64      `json:"user_id"`
+	FullName       *string    `json:"full_name,omitempty"` // Companions only; the account holder uses the user's name
+	IsPrimary      bool       `json:"is_primary"`
+	PassportNo     string     `json:"passport_no"`
+	Nationality    *string    `json:"nationality"` // ISO 3166-1 alpha-2
+	DateOfBirth    *time.Time `json:"date_of_birth"`
+	PassportExpiry *time.Time `json:"passport_expiry"`
+	Phone          string     `json:"phone"` // E.164
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// UpdateProfileRequest defines the body for creating or replacing the caller's profile.
+type UpdateProfileRequest struct {
+	PassportNo     string `json:"passport_no" binding:"required"`
+	Nationality    string `json:"nationality"`     // Optional, ISO 3166-1 alpha-2, e.g. "KZ"
+	DateOfBirth    string `json:"date_of_birth"`   // Optional, YYYY-MM-DD
+	PassportExpiry string `json:"passport_expiry"` // Optional, YYYY-MM-DD
+	Phone          string `json:"phone"`           // Optional, E.164, e.g. "+77011234567"
+}
+
+// CompanionRequest defines the body for adding or replacing a companion traveller.
+type CompanionRequest struct {
+	FullName string `json:"full_name" binding:"required,max=255"`
+	UpdateProfileRequest
 }
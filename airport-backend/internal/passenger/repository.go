@@ -1,6 +1,7 @@
 package passenger
 
 import (
+	"airport-system/platform/database"
 	"context"
 	"database/sql"
 	"fmt"
@@ -16,31 +17,136 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{DB: db}
 }
 
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+// passengerColumns is the column list scanned by scanPassenger.
+const passengerColumns = `id, user_id, full_name, is_primary, passport_no, nationality, date_of_birth, passport_expiry, phone, created_at, updated_at`
+
+func scanPassenger(row interface{ Scan(dest ...any) error }) (*Passenger, error) {
+	var p Passenger
+	err := row.Scan(&p.ID, &p.UserID, &p.FullName, &p.IsPrimary, &p.PassportNo, &p.Nationality, &p.DateOfBirth, &p.PassportExpiry, &p.Phone, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
 // Create inserts a new passenger profile.
 func (r *Repository) Create(ctx context.Context, p *Passenger) (int64, error) {
 	query := `
-		INSERT INTO passengers (user_id, passport_no, phone, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
-		RETURNING id
+		INSERT INTO passengers (user_id, full_name, is_primary, passport_no, nationality, date_of_birth, passport_expiry, phone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		RETURNING id, created_at, updated_at
 	`
 	var id int64
-	err := r.DB.QueryRowContext(ctx, query, p.UserID, p.PassportNo, p.Phone).Scan(&id)
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		p.UserID, p.FullName, p.IsPrimary, p.PassportNo, p.Nationality, p.DateOfBirth, p.PassportExpiry, p.Phone,
+	).Scan(&id, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create passenger: %w", err)
 	}
 	return id, nil
 }
 
-// GetByUserID retrieves a passenger profile by user ID.
+// GetByUserID retrieves a user's primary passenger profile.
 func (r *Repository) GetByUserID(ctx context.Context, userID int64) (*Passenger, error) {
-	query := `SELECT id, user_id, passport_no, phone, created_at, updated_at FROM passengers WHERE user_id = $1`
-	var p Passenger
-	err := r.DB.QueryRowContext(ctx, query, userID).Scan(&p.ID, &p.UserID, &p.PassportNo, &p.Phone, &p.CreatedAt, &p.UpdatedAt)
+	query := `SELECT ` + passengerColumns + ` FROM passengers WHERE user_id = $1 AND is_primary`
+	p, err := scanPassenger(r.executor(ctx).QueryRowContext(ctx, query, userID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found is not an error here, just nil
 		}
 		return nil, fmt.Errorf("failed to get passenger: %w", err)
 	}
-	return &p, nil
+	return p, nil
+}
+
+// GetByUserIDForUpdate retrieves a user's primary passenger profile and locks it.
+func (r *Repository) GetByUserIDForUpdate(ctx context.Context, userID int64) (*Passenger, error) {
+	query := `SELECT ` + passengerColumns + ` FROM passengers WHERE user_id = $1 AND is_primary FOR UPDATE`
+	p, err := scanPassenger(r.executor(ctx).QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get passenger: %w", err)
+	}
+	return p, nil
+}
+
+// GetByIDForUpdate retrieves a passenger profile by ID and locks it.
+func (r *Repository) GetByIDForUpdate(ctx context.Context, id int64) (*Passenger, error) {
+	query := `SELECT ` + passengerColumns + ` FROM passengers WHERE id = $1 FOR UPDATE`
+	p, err := scanPassenger(r.executor(ctx).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get passenger: %w", err)
+	}
+	return p, nil
+}
+
+// ListByUserID returns all travellers of a user, the account holder first.
+func (r *Repository) ListByUserID(ctx context.Context, userID int64) ([]Passenger, error) {
+	query := `SELECT ` + passengerColumns + ` FROM passengers WHERE user_id = $1 ORDER BY is_primary DESC, id`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passengers: %w", err)
+	}
+	defer rows.Close()
+
+	passengers := []Passenger{}
+	for rows.Next() {
+		p, err := scanPassenger(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan passenger: %w", err)
+		}
+		passengers = append(passengers, *p)
+	}
+	return passengers, nil
+}
+
+// Update replaces the editable fields of a passenger profile.
+func (r *Repository) Update(ctx context.Context, p *Passenger) error {
+	query := `
+		UPDATE passengers
+		SET full_name = $1, passport_no = $2, nationality = $3, date_of_birth = $4, passport_expiry = $5, phone = $6, updated_at = NOW()
+		WHERE id = $7
+		RETURNING updated_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		p.FullName, p.PassportNo, p.Nationality, p.DateOfBirth, p.PassportExpiry, p.Phone, p.ID,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update passenger: %w", err)
+	}
+	return nil
+}
+
+// HasCheckedInTickets reports whether the passenger holds an active ticket that is checked in
+// on a flight that has not yet arrived: a bag was checked in, or boarding has started.
+func (r *Repository) HasCheckedInTickets(ctx context.Context, passengerID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM tickets t
+			JOIN flights f ON f.id = t.flight_id
+			WHERE t.passenger_id = $1
+			  AND t.status = 'ACTIVE'
+			  AND f.status NOT IN ('ARRIVED', 'CANCELLED')
+			  AND (f.status IN ('BOARDING', 'DEPARTED', 'DIVERTED')
+			       OR EXISTS (SELECT 1 FROM baggage b WHERE b.ticket_id = t.id))
+		)
+	`
+	var exists bool
+	if err := r.executor(ctx).QueryRowContext(ctx, query, passengerID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check checked-in tickets: %w", err)
+	}
+	return exists, nil
 }
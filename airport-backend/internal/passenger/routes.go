@@ -0,0 +1,18 @@
+package passenger
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes sets up the passenger profile routes.
+func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	passengerGroup := r.Group("/passengers")
+	passengerGroup.Use(authMiddleware)
+	{
+		passengerGroup.GET("/me", h.GetMe)
+		passengerGroup.PUT("/me", h.UpdateMe)
+		passengerGroup.GET("/me/companions", h.ListCompanions)
+		passengerGroup.POST("/me/companions", h.AddCompanion)
+		passengerGroup.PUT("/me/companions/:id", h.UpdateCompanion)
+	}
+}
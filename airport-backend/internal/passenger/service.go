@@ -1,21 +1,48 @@
 package passenger
 
 import (
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
 	"context"
+	"fmt"
 	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrProfileNotFound is returned when the user has no passenger profile yet.
+	ErrProfileNotFound = apperror.NotFound("passenger_profile_not_found", "passenger profile not found")
+	// ErrInvalidProfile is returned when profile fields fail validation.
+	ErrInvalidProfile = apperror.Validation("invalid_passenger_profile", "invalid passenger profile")
+	// ErrProfileLocked is returned when travel document details change while a ticket is checked in.
+	ErrProfileLocked = apperror.Conflict("passenger_profile_locked", "travel document details cannot be changed while a ticket is checked in")
+	// ErrCompanionNotFound is returned when a companion does not exist or belongs to another user.
+	ErrCompanionNotFound = apperror.NotFound("companion_not_found", "companion not found")
+	// ErrTravellerNotFound is returned when a booking names a traveller the user does not hold.
+	ErrTravellerNotFound = apperror.NotFound("traveller_not_found", "traveller not found")
+)
+
+var (
+	passportPattern    = regexp.MustCompile(`^[A-Z0-9]{6,9}$`) // ICAO 9303 document numbers are at most 9 characters
+	nationalityPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	phonePattern       = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`) // E.164
 )
 
 // Service handles business logic for passengers.
 type Service struct {
-	repo *Repository
-	log  *slog.Logger
+	repo      *Repository
+	txManager database.TxManager
+	log       *slog.Logger
 }
 
 // NewService creates a new passenger service.
-func NewService(repo *Repository, log *slog.Logger) *Service {
+func NewService(repo *Repository, txManager database.TxManager, log *slog.Logger) *Service {
 	return &Service{
-		repo: repo,
-		log:  log,
+		repo:      repo,
+		txManager: txManager,
+		log:       log,
 	}
 }
 
@@ -24,12 +51,88 @@ func (s *Service) GetProfile(ctx context.Context, userID int64) (*Passenger, err
 	return s.repo.GetByUserID(ctx, userID)
 }
 
+// GetMyProfile retrieves the caller's passenger profile.
+func (s *Service) GetMyProfile(ctx context.Context, userID int64) (*Passenger, error) {
+	p, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrProfileNotFound
+	}
+	return p, nil
+}
+
 // CreateProfile creates a new passenger profile.
 func (s *Service) CreateProfile(ctx context.Context, userID int64, passport, phone string) (*Passenger, error) {
-	p := &Passenger{
-		UserID:     userID,
-		PassportNo: passport,
-		Phone:      phone,
+	p, err := newProfile(userID, UpdateProfileRequest{PassportNo: passport, Phone: phone})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.repo.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	p.ID = id
+	return p, nil
+}
+
+// UpdateProfile creates or replaces the caller's passenger profile. Passport number,
+// nationality, date of birth and passport expiry are locked while any of the passenger's
+// tickets is checked in, since they were already reported for that flight.
+func (s *Service) UpdateProfile(ctx context.Context, userID int64, req UpdateProfileRequest) (*Passenger, error) {
+	p, err := newProfile(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetByUserIDForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			id, err := s.repo.Create(ctx, p)
+			if err != nil {
+				return err
+			}
+			p.ID = id
+			return nil
+		}
+
+		if documentChanged(current, p) {
+			locked, err := s.repo.HasCheckedInTickets(ctx, current.ID)
+			if err != nil {
+				return err
+			}
+			if locked {
+				return ErrProfileLocked
+			}
+		}
+
+		p.ID = current.ID
+		p.CreatedAt = current.CreatedAt
+		return s.repo.Update(ctx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Passenger profile updated", "passenger_id", p.ID, "user_id", userID)
+	return p, nil
+}
+
+// ListTravellers returns the caller's own profile and companions, the account holder first.
+func (s *Service) ListTravellers(ctx context.Context, userID int64) ([]Passenger, error) {
+	return s.repo.ListByUserID(ctx, userID)
+}
+
+// AddCompanion adds a traveller the caller can book for, such as a family member.
+func (s *Service) AddCompanion(ctx context.Context, userID int64, req CompanionRequest) (*Passenger, error) {
+	p, err := newCompanion(userID, req)
+	if err != nil {
+		return nil, err
 	}
 
 	id, err := s.repo.Create(ctx, p)
@@ -37,5 +140,153 @@ func (s *Service) CreateProfile(ctx context.Context, userID int64, passport, pho
 		return nil, err
 	}
 	p.ID = id
+
+	s.log.Info("Companion added", "passenger_id", p.ID, "user_id", userID)
+	return p, nil
+}
+
+// UpdateCompanion replaces a companion's details. As with the account holder's profile,
+// the name and travel document are locked while any of the companion's tickets is checked in.
+func (s *Service) UpdateCompanion(ctx context.Context, userID, passengerID int64, req CompanionRequest) (*Passenger, error) {
+	p, err := newCompanion(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetByIDForUpdate(ctx, passengerID)
+		if err != nil {
+			return err
+		}
+		if current == nil || current.UserID != userID || current.IsPrimary {
+			return ErrCompanionNotFound
+		}
+
+		if documentChanged(current, p) || !equalString(current.FullName, p.FullName) {
+			locked, err := s.repo.HasCheckedInTickets(ctx, current.ID)
+			if err != nil {
+				return err
+			}
+			if locked {
+				return ErrProfileLocked
+			}
+		}
+
+		p.ID = current.ID
+		p.CreatedAt = current.CreatedAt
+		return s.repo.Update(ctx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Companion updated", "passenger_id", p.ID, "user_id", userID)
+	return p, nil
+}
+
+// ResolveTravellers returns the user's travellers with the given passenger IDs, in the
+// same order. It fails with ErrTravellerNotFound if any ID is not one of the user's travellers.
+func (s *Service) ResolveTravellers(ctx context.Context, userID int64, ids []int64) ([]Passenger, error) {
+	all, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Passenger, len(all))
+	for _, p := range all {
+		byID[p.ID] = p
+	}
+
+	travellers := make([]Passenger, 0, len(ids))
+	for _, id := range ids {
+		p, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: passenger %d", ErrTravellerNotFound, id)
+		}
+		travellers = append(travellers, p)
+	}
+	return travellers, nil
+}
+
+// newCompanion validates and normalizes a companion request.
+func newCompanion(userID int64, req CompanionRequest) (*Passenger, error) {
+	name := strings.Join(strings.Fields(req.FullName), " ")
+	if name == "" {
+		return nil, fmt.Errorf("%w: full_name is required", ErrInvalidProfile)
+	}
+	p, err := newProfile(userID, req.UpdateProfileRequest)
+	if err != nil {
+		return nil, err
+	}
+	p.FullName = &name
+	p.IsPrimary = false
 	return p, nil
 }
+
+// newProfile validates and normalizes a profile request.
+func newProfile(userID int64, req UpdateProfileRequest) (*Passenger, error) {
+	p := &Passenger{
+		UserID:     userID,
+		IsPrimary:  true,
+		PassportNo: strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(req.PassportNo), " ", "")),
+		Phone:      strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(strings.TrimSpace(req.Phone)),
+	}
+	if !passportPattern.MatchString(p.PassportNo) {
+		return nil, fmt.Errorf("%w: passport_no must be 6 to 9 letters or digits", ErrInvalidProfile)
+	}
+	if p.Phone != "" && !phonePattern.MatchString(p.Phone) {
+		return nil, fmt.Errorf("%w: phone must be in E.164 format, e.g. +77011234567", ErrInvalidProfile)
+	}
+
+	if req.Nationality != "" {
+		nationality := strings.ToUpper(strings.TrimSpace(req.Nationality))
+		if !nationalityPattern.MatchString(nationality) {
+			return nil, fmt.Errorf("%w: nationality must be an ISO 3166-1 alpha-2 country code", ErrInvalidProfile)
+		}
+		p.Nationality = &nationality
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.DateOfBirth != "" {
+		dob, err := time.Parse(DateLayout, req.DateOfBirth)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid date_of_birth format (expected YYYY-MM-DD)", ErrInvalidProfile)
+		}
+		if dob.After(today) || dob.Before(today.AddDate(-120, 0, 0)) {
+			return nil, fmt.Errorf("%w: date_of_birth is out of range", ErrInvalidProfile)
+		}
+		p.DateOfBirth = &dob
+	}
+	if req.PassportExpiry != "" {
+		expiry, err := time.Parse(DateLayout, req.PassportExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid passport_expiry format (expected YYYY-MM-DD)", ErrInvalidProfile)
+		}
+		if expiry.Before(today) {
+			return nil, fmt.Errorf("%w: passport has expired", ErrInvalidProfile)
+		}
+		p.PassportExpiry = &expiry
+	}
+	return p, nil
+}
+
+// documentChanged reports whether any travel document field differs between two profiles.
+func documentChanged(a, b *Passenger) bool {
+	return a.PassportNo != b.PassportNo ||
+		!equalString(a.Nationality, b.Nationality) ||
+		!equalDate(a.DateOfBirth, b.DateOfBirth) ||
+		!equalDate(a.PassportExpiry, b.PassportExpiry)
+}
+
+func equalString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Format(DateLayout) == b.Format(DateLayout)
+}
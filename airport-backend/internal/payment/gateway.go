@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+)
+
+// Gateway result statuses.
+const (
+	ResultSucceeded = "SUCCEEDED"
+	ResultDeclined  = "DECLINED"
+	ResultPending   = "PENDING" // The gateway will report the outcome by webhook
+)
+
+// AuthorizeRequest asks the gateway to reserve an amount on a card.
+type AuthorizeRequest struct {
+	IdempotencyKey string // Repeating a request with the same key must not charge twice
+	Amount         float64
+	Currency       string
+	Card           Card
+}
+
+// GatewayResult is a gateway's answer to an authorization or capture.
+type GatewayResult struct {
+	Status      string
+	Reference   string
+	DeclineCode string
+}
+
+// PaymentGateway is implemented by each payment provider. Errors mean the gateway could not
+// be reached or answered unexpectedly; declines are reported through GatewayResult.
+type PaymentGateway interface {
+	// Name identifies the gateway in stored intents and webhook events.
+	Name() string
+	// Authorize reserves the amount on the card.
+	Authorize(ctx context.Context, req AuthorizeRequest) (*GatewayResult, error)
+	// Capture collects a previously authorized amount.
+	Capture(ctx context.Context, reference string, amount float64) (*GatewayResult, error)
+	// Refund returns part or all of a captured amount. Repeating a request with the same
+	// idempotency key must not refund twice.
+	Refund(ctx context.Context, captureRef string, amount float64, idempotencyKey string) (*GatewayResult, error)
+	// ParseWebhook verifies a webhook delivery's signature and decodes its event.
+	ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+}
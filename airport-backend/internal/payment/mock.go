@@ -0,0 +1,138 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Test cards understood by MockGateway. Any other number is declined with "card_not_supported".
+const (
+	MockCardSuccess           = "4242424242424242"
+	MockCardDeclined          = "4000000000000002"
+	MockCardInsufficientFunds = "4000000000009995"
+	MockCardCaptureFails      = "4000000000000341" // Authorizes, then the capture is declined
+	MockCardAsync             = "4000000000003220" // Stays pending until a webhook reports the outcome
+	MockCardGatewayError      = "4000000000000119" // The gateway call fails as if it were unreachable
+)
+
+// MockSignatureHeader carries the hex HMAC-SHA256 of a mock webhook body.
+const MockSignatureHeader = "X-Mock-Signature"
+
+var (
+	errMockUnavailable    = errors.New("mock gateway: simulated outage")
+	errMockWebhookSecret  = errors.New("mock gateway: webhook secret not configured")
+	errMockSignature      = errors.New("mock gateway: invalid webhook signature")
+	errMockAuthorization  = errors.New("mock gateway: unknown authorization")
+	errMockMalformedEvent = errors.New("mock gateway: malformed webhook event")
+)
+
+// MockGateway is a deterministic in-process gateway for local development and tests.
+// The outcome of a payment depends only on the card number; references are derived from
+// the idempotency key, so retrying a request returns the same result.
+type MockGateway struct {
+	webhookSecret []byte
+
+	mu    sync.Mutex
+	cards map[string]string // Authorization reference -> card number
+}
+
+// NewMockGateway creates a mock gateway. Webhooks are signed with webhookSecret;
+// if it is empty, all webhook deliveries are rejected.
+func NewMockGateway(webhookSecret string) *MockGateway {
+	return &MockGateway{
+		webhookSecret: []byte(webhookSecret),
+		cards:         make(map[string]string),
+	}
+}
+
+// Name implements PaymentGateway.
+func (g *MockGateway) Name() string {
+	return "mock"
+}
+
+// Authorize implements PaymentGateway.
+func (g *MockGateway) Authorize(ctx context.Context, req AuthorizeRequest) (*GatewayResult, error) {
+	ref := "mock_auth_" + req.IdempotencyKey
+
+	switch req.Card.Number {
+	case MockCardGatewayError:
+		return nil, errMockUnavailable
+	case MockCardDeclined:
+		return &GatewayResult{Status: ResultDeclined, Reference: ref, DeclineCode: "card_declined"}, nil
+	case MockCardInsufficientFunds:
+		return &GatewayResult{Status: ResultDeclined, Reference: ref, DeclineCode: "insufficient_funds"}, nil
+	case MockCardAsync:
+		return &GatewayResult{Status: ResultPending, Reference: ref}, nil
+	case MockCardSuccess, MockCardCaptureFails:
+		g.mu.Lock()
+		g.cards[ref] = req.Card.Number
+		g.mu.Unlock()
+		return &GatewayResult{Status: ResultSucceeded, Reference: ref}, nil
+	default:
+		return &GatewayResult{Status: ResultDeclined, Reference: ref, DeclineCode: "card_not_supported"}, nil
+	}
+}
+
+// Capture implements PaymentGateway.
+func (g *MockGateway) Capture(ctx context.Context, reference string, amount float64) (*GatewayResult, error) {
+	g.mu.Lock()
+	card, ok := g.cards[reference]
+	g.mu.Unlock()
+	if !ok {
+		return nil, errMockAuthorization
+	}
+
+	if card == MockCardCaptureFails {
+		return &GatewayResult{Status: ResultDeclined, Reference: reference, DeclineCode: "capture_declined"}, nil
+	}
+	return &GatewayResult{Status: ResultSucceeded, Reference: "mock_cap_" + reference[len("mock_auth_"):]}, nil
+}
+
+// Refund implements PaymentGateway. Refunds of mock captures always succeed.
+func (g *MockGateway) Refund(ctx context.Context, captureRef string, amount float64, idempotencyKey string) (*GatewayResult, error) {
+	if !strings.HasPrefix(captureRef, "mock_cap_") {
+		return &GatewayResult{Status: ResultDeclined, DeclineCode: "unknown_capture"}, nil
+	}
+	return &GatewayResult{Status: ResultSucceeded, Reference: "mock_ref_" + idempotencyKey}, nil
+}
+
+// ParseWebhook implements PaymentGateway. The body is a JSON WebhookEvent signed in MockSignatureHeader.
+func (g *MockGateway) ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	if len(g.webhookSecret) == 0 {
+		return nil, errMockWebhookSecret
+	}
+	signature, err := hex.DecodeString(header.Get(MockSignatureHeader))
+	if err != nil || !hmac.Equal(signature, g.sign(payload)) {
+		return nil, errMockSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMockMalformedEvent, err)
+	}
+	if event.ID == "" || event.Type == "" || event.Reference == "" {
+		return nil, errMockMalformedEvent
+	}
+	event.Payload = payload
+	return &event, nil
+}
+
+// SignWebhook returns the signature header value for a webhook body, so that local
+// tooling can simulate the gateway's notifications.
+func (g *MockGateway) SignWebhook(payload []byte) string {
+	return hex.EncodeToString(g.sign(payload))
+}
+
+func (g *MockGateway) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.webhookSecret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,122 @@
+package payment
+
+import "time"
+
+// Payment intent statuses. REQUIRES_PAYMENT and PROCESSING are open; the others are final.
+const (
+	IntentRequiresPayment = "REQUIRES_PAYMENT"
+	IntentProcessing      = "PROCESSING" // Sent to the gateway; the outcome may arrive by webhook
+	IntentSucceeded       = "SUCCEEDED"
+	IntentFailed          = "FAILED"
+	IntentExpired         = "EXPIRED"
+	IntentCancelled       = "CANCELLED"
+)
+
+// Refund statuses. MANUAL refunds have no captured payment to return through the gateway.
+const (
+	RefundPending   = "PENDING"
+	RefundSucceeded = "SUCCEEDED"
+	RefundFailed    = "FAILED"
+	RefundManual    = "MANUAL"
+)
+
+// RefundReasonLatePayment is the reason of refunds for payments captured after their intent
+// was closed, whose tickets had already been released.
+const RefundReasonLatePayment = "late_payment"
+
+// Webhook event types understood by the service.
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+)
+
+// Intent is a request to collect the price of the tickets issued in one booking step.
+type Intent struct {
+	ID          int64     `json:"id"`
+	BookingID   int64     `json:"booking_id"`
+	UserID      int64     `json:"-"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Status      string    `json:"status"`
+	Gateway     string    `json:"gateway"`
+	GatewayRef  *string   `json:"-"`
+	CardLast4   *string   `json:"card_last4,omitempty"`
+	FailureCode *string   `json:"failure_code,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsOpen reports whether the intent is still waiting for an outcome.
+func (i *Intent) IsOpen() bool {
+	return i.Status == IntentRequiresPayment || i.Status == IntentProcessing
+}
+
+// Capture records money actually collected for an intent.
+type Capture struct {
+	ID         int64     `json:"id"`
+	IntentID   int64     `json:"intent_id"`
+	Amount     float64   `json:"amount"`
+	GatewayRef string    `json:"gateway_ref"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Refund returns money for a cancelled ticket, or for a payment captured too late.
+type Refund struct {
+	ID         int64     `json:"id"`
+	TicketID   *int64    `json:"ticket_id"` // Unset for late payments
+	IntentID   *int64    `json:"intent_id"`
+	Amount     float64   `json:"amount"`
+	Penalty    float64   `json:"penalty"` // Withheld under the ticket's fare rule
+	Currency   string    `json:"currency"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason"`
+	GatewayRef *string   `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Card holds the card details of a single payment attempt. They are passed to the gateway
+// and never stored; only the last four digits are kept on the intent.
+type Card struct {
+	Number   string `json:"number" binding:"required"`
+	ExpMonth int    `json:"exp_month" binding:"required,min=1,max=12"`
+	ExpYear  int    `json:"exp_year" binding:"required"`
+	CVC      string `json:"cvc" binding:"required"`
+}
+
+// ConfirmPaymentRequest defines the body for paying an intent.
+type ConfirmPaymentRequest struct {
+	Card Card `json:"card" binding:"required"`
+}
+
+// Outcome is the result of a charge attempt or a webhook, expressed as the intent status it leads to.
+type Outcome struct {
+	Status      string // IntentSucceeded, IntentFailed or IntentProcessing
+	Reference   string // Gateway reference of the authorization
+	CaptureRef  string // Gateway reference of the capture, when succeeded
+	FailureCode string
+}
+
+// WebhookEvent is a verified notification from the gateway.
+type WebhookEvent struct {
+	ID          string  `json:"id"`
+	Type        string  `json:"type"`
+	Reference   string  `json:"reference"`
+	Amount      float64 `json:"amount"`
+	CaptureRef  string  `json:"capture_ref,omitempty"`
+	DeclineCode string  `json:"decline_code,omitempty"`
+	Payload     []byte  `json:"-"`
+}
+
+// Outcome maps the event to the intent status it leads to.
+func (e *WebhookEvent) Outcome() (Outcome, bool) {
+	switch e.Type {
+	case EventPaymentSucceeded:
+		return Outcome{Status: IntentSucceeded, Reference: e.Reference, CaptureRef: e.CaptureRef}, true
+	case EventPaymentFailed:
+		return Outcome{Status: IntentFailed, Reference: e.Reference, FailureCode: e.DeclineCode}, true
+	default:
+		return Outcome{}, false
+	}
+}
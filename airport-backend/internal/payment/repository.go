@@ -0,0 +1,276 @@
+package payment
+
+import (
+	"airport-system/platform/database"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Repository handles database interactions for payments.
+type Repository struct {
+	DB *sql.DB
+}
+
+// NewRepository creates a new payment repository.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{DB: db}
+}
+
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+const intentColumns = `id, booking_id, user_id, amount, currency, status, gateway, gateway_ref, card_last4, failure_code, expires_at, created_at, updated_at`
+
+func scanIntent(row interface{ Scan(...any) error }) (*Intent, error) {
+	var i Intent
+	err := row.Scan(&i.ID, &i.BookingID, &i.UserID, &i.Amount, &i.Currency, &i.Status, &i.Gateway,
+		&i.GatewayRef, &i.CardLast4, &i.FailureCode, &i.ExpiresAt, &i.CreatedAt, &i.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// CreateIntent inserts a new payment intent.
+func (r *Repository) CreateIntent(ctx context.Context, i *Intent) error {
+	query := `
+		INSERT INTO payment_intents (booking_id, user_id, amount, currency, status, gateway, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		i.BookingID, i.UserID, i.Amount, i.Currency, i.Status, i.Gateway, i.ExpiresAt,
+	).Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment intent: %w", err)
+	}
+	return nil
+}
+
+// GetIntent retrieves a payment intent by ID.
+func (r *Repository) GetIntent(ctx context.Context, id int64) (*Intent, error) {
+	return r.getIntent(ctx, `SELECT `+intentColumns+` FROM payment_intents WHERE id = $1`, id)
+}
+
+// GetIntentForUpdate retrieves a payment intent by ID and locks it.
+func (r *Repository) GetIntentForUpdate(ctx context.Context, id int64) (*Intent, error) {
+	return r.getIntent(ctx, `SELECT `+intentColumns+` FROM payment_intents WHERE id = $1 FOR UPDATE`, id)
+}
+
+// GetIntentByReference retrieves a payment intent by its gateway reference.
+func (r *Repository) GetIntentByReference(ctx context.Context, gateway, ref string) (*Intent, error) {
+	return r.getIntent(ctx, `SELECT `+intentColumns+` FROM payment_intents WHERE gateway = $1 AND gateway_ref = $2`, gateway, ref)
+}
+
+func (r *Repository) getIntent(ctx context.Context, query string, args ...any) (*Intent, error) {
+	i, err := scanIntent(r.executor(ctx).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment intent: %w", err)
+	}
+	return i, nil
+}
+
+// ListByBooking retrieves the payment intents of a PNR, oldest first.
+func (r *Repository) ListByBooking(ctx context.Context, bookingID int64) ([]Intent, error) {
+	query := `SELECT ` + intentColumns + ` FROM payment_intents WHERE booking_id = $1 ORDER BY id`
+	return r.listIntents(ctx, query, bookingID)
+}
+
+// ListDue retrieves open intents whose deadline has passed. PROCESSING intents are only
+// due after an extra grace period, since the gateway may still report their outcome.
+func (r *Repository) ListDue(ctx context.Context, now time.Time, processingGrace time.Duration, limit int) ([]Intent, error) {
+	query := `
+		SELECT ` + intentColumns + ` FROM payment_intents
+		WHERE (status = 'REQUIRES_PAYMENT' AND expires_at <= $1)
+		   OR (status = 'PROCESSING' AND expires_at <= $2)
+		ORDER BY expires_at
+		LIMIT $3
+	`
+	return r.listIntents(ctx, query, now, now.Add(-processingGrace), limit)
+}
+
+func (r *Repository) listIntents(ctx context.Context, query string, args ...any) ([]Intent, error) {
+	rows, err := r.executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment intents: %w", err)
+	}
+	defer rows.Close()
+
+	intents := []Intent{}
+	for rows.Next() {
+		i, err := scanIntent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment intent: %w", err)
+		}
+		intents = append(intents, *i)
+	}
+	return intents, nil
+}
+
+// UpdateIntent saves an intent's status and gateway details.
+func (r *Repository) UpdateIntent(ctx context.Context, i *Intent) error {
+	query := `
+		UPDATE payment_intents
+		SET status = $1, gateway_ref = $2, card_last4 = $3, failure_code = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING updated_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query, i.Status, i.GatewayRef, i.CardLast4, i.FailureCode, i.ID).Scan(&i.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update payment intent: %w", err)
+	}
+	return nil
+}
+
+// CreateCapture records a capture. An intent is captured at most once.
+func (r *Repository) CreateCapture(ctx context.Context, c *Capture) error {
+	query := `
+		INSERT INTO payment_captures (intent_id, amount, gateway_ref, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (intent_id) DO NOTHING
+	`
+	if _, err := r.executor(ctx).ExecContext(ctx, query, c.IntentID, c.Amount, c.GatewayRef); err != nil {
+		return fmt.Errorf("failed to create payment capture: %w", err)
+	}
+	return nil
+}
+
+// RecordWebhookEvent stores a webhook delivery. It returns false if the event was already recorded.
+func (r *Repository) RecordWebhookEvent(ctx context.Context, gateway string, event *WebhookEvent, intentID *int64) (bool, error) {
+	query := `
+		INSERT INTO payment_webhook_events (gateway, event_id, event_type, intent_id, payload, received_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (gateway, event_id) DO NOTHING
+	`
+	res, err := r.executor(ctx).ExecContext(ctx, query, gateway, event.ID, event.Type, intentID, string(event.Payload))
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetCaptureByIntent retrieves the capture of an intent.
+func (r *Repository) GetCaptureByIntent(ctx context.Context, intentID int64) (*Capture, error) {
+	query := `SELECT id, intent_id, amount, gateway_ref, created_at FROM payment_captures WHERE intent_id = $1`
+	var c Capture
+	err := r.executor(ctx).QueryRowContext(ctx, query, intentID).Scan(&c.ID, &c.IntentID, &c.Amount, &c.GatewayRef, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payment capture: %w", err)
+	}
+	return &c, nil
+}
+
+const refundColumns = `id, ticket_id, intent_id, amount, penalty, currency, status, reason, gateway_ref, created_at, updated_at`
+
+func scanRefund(row interface{ Scan(...any) error }) (*Refund, error) {
+	var rf Refund
+	err := row.Scan(&rf.ID, &rf.TicketID, &rf.IntentID, &rf.Amount, &rf.Penalty, &rf.Currency,
+		&rf.Status, &rf.Reason, &rf.GatewayRef, &rf.CreatedAt, &rf.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rf, nil
+}
+
+// CreateRefund inserts a refund record.
+func (r *Repository) CreateRefund(ctx context.Context, rf *Refund) error {
+	query := `
+		INSERT INTO payment_refunds (ticket_id, intent_id, amount, penalty, currency, status, reason, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		rf.TicketID, rf.IntentID, rf.Amount, rf.Penalty, rf.Currency, rf.Status, rf.Reason,
+	).Scan(&rf.ID, &rf.CreatedAt, &rf.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refund: %w", err)
+	}
+	return nil
+}
+
+// GetRefund retrieves a refund by ID.
+func (r *Repository) GetRefund(ctx context.Context, id int64) (*Refund, error) {
+	query := `SELECT ` + refundColumns + ` FROM payment_refunds WHERE id = $1`
+	rf, err := scanRefund(r.executor(ctx).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	return rf, nil
+}
+
+// ListRefundsByBooking retrieves the refunds of a PNR's tickets and of its late payments.
+func (r *Repository) ListRefundsByBooking(ctx context.Context, bookingID int64) ([]Refund, error) {
+	query := `
+		SELECT ` + refundColumns + ` FROM payment_refunds
+		WHERE ticket_id IN (SELECT id FROM tickets WHERE booking_id = $1)
+		   OR (ticket_id IS NULL AND intent_id IN (SELECT id FROM payment_intents WHERE booking_id = $1))
+		ORDER BY id
+	`
+	return r.listRefunds(ctx, query, bookingID)
+}
+
+// ListPendingRefundIDs retrieves refunds still waiting for the gateway, oldest first.
+func (r *Repository) ListPendingRefundIDs(ctx context.Context, limit int) ([]int64, error) {
+	query := `SELECT id FROM payment_refunds WHERE status = 'PENDING' ORDER BY created_at LIMIT $1`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending refunds: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *Repository) listRefunds(ctx context.Context, query string, args ...any) ([]Refund, error) {
+	rows, err := r.executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+	defer rows.Close()
+
+	refunds := []Refund{}
+	for rows.Next() {
+		rf, err := scanRefund(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		refunds = append(refunds, *rf)
+	}
+	return refunds, nil
+}
+
+// UpdateRefund saves a refund's status and gateway reference.
+func (r *Repository) UpdateRefund(ctx context.Context, rf *Refund) error {
+	query := `UPDATE payment_refunds SET status = $1, gateway_ref = $2, updated_at = NOW() WHERE id = $3 RETURNING updated_at`
+	if err := r.executor(ctx).QueryRowContext(ctx, query, rf.Status, rf.GatewayRef, rf.ID).Scan(&rf.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+	return nil
+}
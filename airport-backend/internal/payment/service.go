@@ -0,0 +1,517 @@
+package payment
+
+import (
+	"airport-system/platform/apperror"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPaymentNotFound is returned when the payment intent does not exist.
+	ErrPaymentNotFound = apperror.NotFound("payment_not_found", "payment not found")
+	// ErrPaymentExpired is returned when paying after the intent's deadline.
+	ErrPaymentExpired = apperror.Conflict("payment_expired", "payment window has expired; the tickets were released")
+	// ErrPaymentInProgress is returned while the gateway is still processing the intent.
+	ErrPaymentInProgress = apperror.Conflict("payment_in_progress", "payment is already being processed")
+	// ErrPaymentClosed is returned when paying an intent that already succeeded, failed or was cancelled.
+	ErrPaymentClosed = apperror.Conflict("payment_closed", "payment is already closed")
+	// ErrPaymentDeclined is returned when the gateway declines the card.
+	ErrPaymentDeclined = apperror.PaymentRequired("payment_declined", "payment was declined; the tickets were released")
+	// ErrInvalidCard is returned when card details are malformed or expired.
+	ErrInvalidCard = apperror.Validation("invalid_card", "invalid card details")
+	// ErrGatewayUnavailable is returned when the gateway cannot be reached; the payment may be retried.
+	ErrGatewayUnavailable = apperror.Unavailable("payment_gateway_unavailable", "payment gateway is unavailable, please retry")
+	// ErrInvalidWebhook is returned for webhook deliveries that fail verification.
+	ErrInvalidWebhook = apperror.Validation("invalid_webhook", "invalid webhook")
+	// ErrWebhookUnmatched is returned for webhooks whose reference matches no intent yet, which
+	// happens when they overtake the charging request; the gateway redelivers them later.
+	ErrWebhookUnmatched = apperror.Unavailable("payment_webhook_unmatched", "payment webhook refers to an unknown payment, please retry")
+)
+
+// refundBatchSize caps how many pending refunds one ProcessPendingRefunds call sends.
+const refundBatchSize = 100
+
+// Defaults for Config fields left zero.
+const (
+	DefaultCurrency        = "KZT"
+	DefaultIntentTTL       = 15 * time.Minute
+	DefaultProcessingGrace = time.Hour
+)
+
+// Config holds payment settings.
+type Config struct {
+	Currency        string        // ISO 4217 code charged for tickets
+	IntentTTL       time.Duration // How long tickets wait for payment before being released
+	ProcessingGrace time.Duration // Extra time a PROCESSING intent waits for the gateway's webhook
+}
+
+// Service handles payment intents against a PaymentGateway. It never changes tickets itself;
+// callers apply the intent's outcome within the same transaction.
+type Service struct {
+	repo    *Repository
+	gateway PaymentGateway
+	log     *slog.Logger
+	cfg     Config
+}
+
+// NewService creates a new payment service.
+func NewService(repo *Repository, gateway PaymentGateway, log *slog.Logger, cfg Config) *Service {
+	if cfg.Currency == "" {
+		cfg.Currency = DefaultCurrency
+	}
+	if cfg.IntentTTL <= 0 {
+		cfg.IntentTTL = DefaultIntentTTL
+	}
+	if cfg.ProcessingGrace <= 0 {
+		cfg.ProcessingGrace = DefaultProcessingGrace
+	}
+	return &Service{
+		repo:    repo,
+		gateway: gateway,
+		log:     log,
+		cfg:     cfg,
+	}
+}
+
+// CreateIntent opens a payment intent for a PNR.
+func (s *Service) CreateIntent(ctx context.Context, bookingID, userID int64, amount float64) (*Intent, error) {
+	intent := &Intent{
+		BookingID: bookingID,
+		UserID:    userID,
+		Amount:    amount,
+		Currency:  s.cfg.Currency,
+		Status:    IntentRequiresPayment,
+		Gateway:   s.gateway.Name(),
+		ExpiresAt: time.Now().Add(s.cfg.IntentTTL),
+	}
+	if err := s.repo.CreateIntent(ctx, intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// GetIntent retrieves a payment intent by ID.
+func (s *Service) GetIntent(ctx context.Context, id int64) (*Intent, error) {
+	intent, err := s.repo.GetIntent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrPaymentNotFound
+	}
+	return intent, nil
+}
+
+// ListByBooking returns the payment intents of a PNR.
+func (s *Service) ListByBooking(ctx context.Context, bookingID int64) ([]Intent, error) {
+	return s.repo.ListByBooking(ctx, bookingID)
+}
+
+// ListDue returns open intents whose deadline has passed.
+func (s *Service) ListDue(ctx context.Context, limit int) ([]Intent, error) {
+	return s.repo.ListDue(ctx, time.Now(), s.cfg.ProcessingGrace, limit)
+}
+
+// Begin locks an intent awaiting payment and marks it PROCESSING, so that concurrent attempts
+// cannot charge twice. It returns ErrPaymentExpired once the deadline has passed.
+func (s *Service) Begin(ctx context.Context, id int64, card Card) (*Intent, error) {
+	if err := validateCard(card); err != nil {
+		return nil, err
+	}
+
+	intent, err := s.repo.GetIntentForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrPaymentNotFound
+	}
+	switch intent.Status {
+	case IntentRequiresPayment:
+	case IntentProcessing:
+		return nil, ErrPaymentInProgress
+	default:
+		return nil, fmt.Errorf("%w: status is %s", ErrPaymentClosed, intent.Status)
+	}
+	if !intent.ExpiresAt.After(time.Now()) {
+		return nil, ErrPaymentExpired
+	}
+
+	last4 := card.Number[len(card.Number)-4:]
+	intent.Status = IntentProcessing
+	intent.CardLast4 = &last4
+	intent.FailureCode = nil
+	if err := s.repo.UpdateIntent(ctx, intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// Reopen returns a PROCESSING intent to REQUIRES_PAYMENT after the gateway could not be reached.
+func (s *Service) Reopen(ctx context.Context, id int64) error {
+	intent, err := s.repo.GetIntentForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if intent == nil || intent.Status != IntentProcessing {
+		return nil
+	}
+	intent.Status = IntentRequiresPayment
+	return s.repo.UpdateIntent(ctx, intent)
+}
+
+// Charge authorizes and captures an intent's amount at the gateway. It must be called outside
+// a database transaction, after Begin has committed.
+func (s *Service) Charge(ctx context.Context, intent *Intent, card Card) (Outcome, error) {
+	auth, err := s.gateway.Authorize(ctx, AuthorizeRequest{
+		IdempotencyKey: "intent-" + strconv.FormatInt(intent.ID, 10),
+		Amount:         intent.Amount,
+		Currency:       intent.Currency,
+		Card:           card,
+	})
+	if err != nil {
+		s.log.Error("Payment authorization failed", "intent_id", intent.ID, "gateway", s.gateway.Name(), "error", err)
+		return Outcome{}, ErrGatewayUnavailable
+	}
+
+	switch auth.Status {
+	case ResultDeclined:
+		return Outcome{Status: IntentFailed, Reference: auth.Reference, FailureCode: auth.DeclineCode}, nil
+	case ResultPending:
+		return Outcome{Status: IntentProcessing, Reference: auth.Reference}, nil
+	}
+
+	capture, err := s.gateway.Capture(ctx, auth.Reference, intent.Amount)
+	if err != nil {
+		// The authorization is already in place; the webhook or the expiry sweep settles the intent.
+		s.log.Error("Payment capture failed", "intent_id", intent.ID, "gateway", s.gateway.Name(), "error", err)
+		return Outcome{Status: IntentProcessing, Reference: auth.Reference}, nil
+	}
+	if capture.Status != ResultSucceeded {
+		return Outcome{Status: IntentFailed, Reference: auth.Reference, FailureCode: capture.DeclineCode}, nil
+	}
+	return Outcome{Status: IntentSucceeded, Reference: auth.Reference, CaptureRef: capture.Reference}, nil
+}
+
+// Settle records an outcome on an open intent and reports whether the intent changed.
+// Outcomes for intents that are already closed are ignored, which makes repeated
+// webhooks and races between a webhook and the charging request harmless. The exception is
+// money captured after the intent was closed without success: it is recorded and refunded.
+func (s *Service) Settle(ctx context.Context, id int64, outcome Outcome) (*Intent, bool, error) {
+	intent, err := s.repo.GetIntentForUpdate(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if intent == nil {
+		return nil, false, ErrPaymentNotFound
+	}
+	if !intent.IsOpen() {
+		if outcome.Status == IntentSucceeded && intent.Status != IntentSucceeded {
+			if err := s.refundLatePayment(ctx, intent, outcome); err != nil {
+				return nil, false, err
+			}
+		}
+		return intent, false, nil
+	}
+	if outcome.Status == IntentProcessing && intent.Status == IntentProcessing && intent.GatewayRef != nil {
+		return intent, false, nil
+	}
+
+	if outcome.Reference != "" {
+		intent.GatewayRef = &outcome.Reference
+	}
+	intent.Status = outcome.Status
+	if outcome.FailureCode != "" {
+		intent.FailureCode = &outcome.FailureCode
+	}
+	if err := s.repo.UpdateIntent(ctx, intent); err != nil {
+		return nil, false, err
+	}
+
+	if outcome.Status == IntentSucceeded {
+		capture := &Capture{IntentID: intent.ID, Amount: intent.Amount, GatewayRef: outcome.CaptureRef}
+		if capture.GatewayRef == "" {
+			capture.GatewayRef = outcome.Reference
+		}
+		if err := s.repo.CreateCapture(ctx, capture); err != nil {
+			return nil, false, err
+		}
+	}
+
+	s.log.Info("Payment intent settled", "intent_id", intent.ID, "status", intent.Status)
+	return intent, true, nil
+}
+
+// refundLatePayment records money collected for an intent that was already closed, after its
+// tickets were released, and a refund of all of it for ProcessPendingRefunds to send. The
+// capture is recorded once, so a second report of the same payment does not refund it again.
+// Callers hold the intent's lock.
+func (s *Service) refundLatePayment(ctx context.Context, intent *Intent, outcome Outcome) error {
+	existing, err := s.repo.GetCaptureByIntent(ctx, intent.ID)
+	if err != nil || existing != nil {
+		return err
+	}
+
+	capture := &Capture{IntentID: intent.ID, Amount: intent.Amount, GatewayRef: outcome.CaptureRef}
+	if capture.GatewayRef == "" {
+		capture.GatewayRef = outcome.Reference
+	}
+	if err := s.repo.CreateCapture(ctx, capture); err != nil {
+		return err
+	}
+	refund := &Refund{
+		IntentID: &intent.ID,
+		Amount:   intent.Amount,
+		Currency: intent.Currency,
+		Status:   RefundPending,
+		Reason:   RefundReasonLatePayment,
+	}
+	if err := s.repo.CreateRefund(ctx, refund); err != nil {
+		return err
+	}
+
+	s.log.Warn("Refunding payment captured on a closed intent", "intent_id", intent.ID, "status", intent.Status, "refund_id", refund.ID)
+	return nil
+}
+
+// Expire closes an open intent whose deadline has passed and reports whether it changed.
+func (s *Service) Expire(ctx context.Context, id int64) (*Intent, bool, error) {
+	intent, err := s.repo.GetIntentForUpdate(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if intent == nil {
+		return nil, false, ErrPaymentNotFound
+	}
+
+	deadline := intent.ExpiresAt
+	if intent.Status == IntentProcessing {
+		deadline = deadline.Add(s.cfg.ProcessingGrace)
+	}
+	if !intent.IsOpen() || deadline.After(time.Now()) {
+		return intent, false, nil
+	}
+
+	intent.Status = IntentExpired
+	if err := s.repo.UpdateIntent(ctx, intent); err != nil {
+		return nil, false, err
+	}
+	s.log.Info("Payment intent expired", "intent_id", intent.ID)
+	return intent, true, nil
+}
+
+// Cancel closes an intent that has not been sent to the gateway and reports whether it changed.
+func (s *Service) Cancel(ctx context.Context, id int64) (*Intent, bool, error) {
+	intent, err := s.repo.GetIntentForUpdate(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if intent == nil {
+		return nil, false, ErrPaymentNotFound
+	}
+	switch intent.Status {
+	case IntentRequiresPayment:
+	case IntentProcessing:
+		return nil, false, ErrPaymentInProgress
+	default:
+		return intent, false, nil
+	}
+
+	intent.Status = IntentCancelled
+	if err := s.repo.UpdateIntent(ctx, intent); err != nil {
+		return nil, false, err
+	}
+	return intent, true, nil
+}
+
+// ParseWebhook verifies and decodes a webhook delivery.
+func (s *Service) ParseWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	event, err := s.gateway.ParseWebhook(payload, header)
+	if err != nil {
+		s.log.Warn("Rejected payment webhook", "gateway", s.gateway.Name(), "error", err)
+		return nil, ErrInvalidWebhook
+	}
+	return event, nil
+}
+
+// RecordWebhook stores a verified webhook event and returns the intent it refers to. It
+// reports false for redeliveries of an event that was already recorded. Events whose reference
+// matches no intent are not recorded and return ErrWebhookUnmatched, so that the gateway
+// delivers them again once the charging request has stored the reference.
+func (s *Service) RecordWebhook(ctx context.Context, event *WebhookEvent) (*Intent, bool, error) {
+	intent, err := s.repo.GetIntentByReference(ctx, s.gateway.Name(), event.Reference)
+	if err != nil {
+		return nil, false, err
+	}
+	if intent == nil {
+		s.log.Warn("Payment webhook for unknown reference", "event_id", event.ID, "reference", event.Reference)
+		return nil, false, ErrWebhookUnmatched
+	}
+
+	fresh, err := s.repo.RecordWebhookEvent(ctx, s.gateway.Name(), event, &intent.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !fresh {
+		s.log.Info("Ignoring duplicate payment webhook", "event_id", event.ID)
+	}
+	return intent, fresh, nil
+}
+
+// Currency returns the currency tickets are charged in.
+func (s *Service) Currency() string {
+	return s.cfg.Currency
+}
+
+// CreateRefund records a refund for a cancelled ticket. It runs in the caller's transaction;
+// the money is returned by ProcessRefund once that transaction has committed. Refunds without
+// a captured payment behind them are recorded as MANUAL, and zero refunds as SUCCEEDED.
+func (s *Service) CreateRefund(ctx context.Context, ticketID int64, intentID *int64, amount, penalty float64, reason string) (*Refund, error) {
+	refund := &Refund{
+		TicketID: &ticketID,
+		IntentID: intentID,
+		Amount:   amount,
+		Penalty:  penalty,
+		Currency: s.cfg.Currency,
+		Status:   RefundPending,
+		Reason:   reason,
+	}
+
+	if intentID != nil {
+		intent, err := s.repo.GetIntent(ctx, *intentID)
+		if err != nil {
+			return nil, err
+		}
+		if intent != nil {
+			refund.Currency = intent.Currency
+		}
+		if intent == nil || intent.Status != IntentSucceeded {
+			refund.Status = RefundManual
+		}
+	} else {
+		refund.Status = RefundManual
+	}
+	if amount == 0 {
+		refund.Status = RefundSucceeded
+	}
+
+	if err := s.repo.CreateRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+	s.log.Info("Refund recorded", "refund_id", refund.ID, "ticket_id", ticketID, "amount", amount, "penalty", penalty, "status", refund.Status)
+	return refund, nil
+}
+
+// ListRefundsByBooking returns the refunds of a PNR's tickets.
+func (s *Service) ListRefundsByBooking(ctx context.Context, bookingID int64) ([]Refund, error) {
+	return s.repo.ListRefundsByBooking(ctx, bookingID)
+}
+
+// ProcessRefund sends a pending refund to the gateway. If the gateway cannot be reached the
+// refund stays PENDING and ProcessPendingRefunds retries it later; the idempotency key keeps
+// retries from refunding twice.
+func (s *Service) ProcessRefund(ctx context.Context, id int64) (*Refund, error) {
+	refund, err := s.repo.GetRefund(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if refund == nil || refund.Status != RefundPending {
+		return refund, nil
+	}
+
+	capture, err := s.repo.GetCaptureByIntent(ctx, *refund.IntentID)
+	if err != nil {
+		return nil, err
+	}
+	if capture == nil {
+		refund.Status = RefundManual
+		return refund, s.repo.UpdateRefund(ctx, refund)
+	}
+
+	result, err := s.gateway.Refund(ctx, capture.GatewayRef, refund.Amount, "refund-"+strconv.FormatInt(refund.ID, 10))
+	if err != nil {
+		s.log.Error("Refund failed", "refund_id", refund.ID, "gateway", s.gateway.Name(), "error", err)
+		return refund, ErrGatewayUnavailable
+	}
+
+	refund.Status = RefundSucceeded
+	if result.Status != ResultSucceeded {
+		refund.Status = RefundFailed
+		s.log.Warn("Refund declined by gateway", "refund_id", refund.ID, "decline_code", result.DeclineCode)
+	}
+	if result.Reference != "" {
+		refund.GatewayRef = &result.Reference
+	}
+	if err := s.repo.UpdateRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// ProcessPendingRefunds retries refunds the gateway has not confirmed yet and returns how
+// many it completed. It is meant to be called periodically.
+func (s *Service) ProcessPendingRefunds(ctx context.Context) (int, error) {
+	ids, err := s.repo.ListPendingRefundIDs(ctx, refundBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for _, id := range ids {
+		refund, err := s.ProcessRefund(ctx, id)
+		if err != nil {
+			return done, err
+		}
+		if refund != nil && refund.Status != RefundPending {
+			done++
+		}
+	}
+	return done, nil
+}
+
+// validateCard checks the card number's format and checksum and that the card has not expired.
+func validateCard(card Card) error {
+	number := strings.ReplaceAll(card.Number, " ", "")
+	if len(number) < 12 || len(number) > 19 || !luhnValid(number) {
+		return fmt.Errorf("%w: card number is not valid", ErrInvalidCard)
+	}
+	if card.Number != number {
+		return fmt.Errorf("%w: card number must contain digits only", ErrInvalidCard)
+	}
+	if len(card.CVC) < 3 || len(card.CVC) > 4 || strings.Trim(card.CVC, "0123456789") != "" {
+		return fmt.Errorf("%w: cvc must be 3 or 4 digits", ErrInvalidCard)
+	}
+
+	now := time.Now()
+	if card.ExpMonth < 1 || card.ExpMonth > 12 ||
+		card.ExpYear < now.Year() || (card.ExpYear == now.Year() && card.ExpMonth < int(now.Month())) {
+		return fmt.Errorf("%w: card has expired", ErrInvalidCard)
+	}
+	return nil
+}
+
+// luhnValid reports whether a string of digits passes the Luhn checksum.
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
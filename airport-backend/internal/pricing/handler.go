@@ -0,0 +1,198 @@
+package pricing
+
+import (
+	"airport-system/internal/auth"
+	"airport-system/platform/apperror"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages HTTP requests for flight prices and pricing rules.
+type Handler struct {
+	Service *Service
+}
+
+// NewHandler creates a new pricing handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{Service: service}
+}
+
+// GetPrice handles retrieving a flight's current price, optionally for ?booking_class=, without locking it.
+func (h *Handler) GetPrice(c *gin.Context) {
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	quote, err := h.Service.CurrentPrice(c.Request.Context(), flightID, c.Query("booking_class"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// CreateQuote handles locking a flight's price for the caller to book.
+func (h *Handler) CreateQuote(c *gin.Context) {
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req QuoteRequest
+	// The body is optional: no body quotes the cheapest economy class with seats left
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+	}
+
+	quote, err := h.Service.CreateQuote(c.Request.Context(), c.GetInt64("userID"), flightID, req.BookingClass)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, quote)
+}
+
+// ListRules handles listing pricing rules, optionally filtered by ?status= (ADMIN only).
+func (h *Handler) ListRules(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	rules, err := h.Service.ListRules(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRule handles creating a draft pricing rule (ADMIN only).
+func (h *Handler) CreateRule(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	var req RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	rule, err := h.Service.CreateRule(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule handles editing a draft pricing rule (ADMIN only).
+func (h *Handler) UpdateRule(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req RuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	rule, err := h.Service.UpdateRule(c.Request.Context(), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// PublishRule handles making a draft pricing rule live (ADMIN only).
+func (h *Handler) PublishRule(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	rule, err := h.Service.PublishRule(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// RetireRule handles withdrawing a published pricing rule (ADMIN only).
+func (h *Handler) RetireRule(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	rule, err := h.Service.RetireRule(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// Simulate handles pricing a flight under draft rules and hypothetical demand (ADMIN only).
+func (h *Handler) Simulate(c *gin.Context) {
+	if !auth.RequireRole(c, auth.RoleAdmin) {
+		return
+	}
+
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req SimulateRequest
+	// The body is optional: no body simulates every draft at the flight's current demand
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(apperror.BadRequest(err))
+			return
+		}
+	}
+
+	sim, err := h.Service.Simulate(c.Request.Context(), flightID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sim)
+}
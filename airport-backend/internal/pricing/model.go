@@ -0,0 +1,164 @@
+package pricing
+
+import (
+	"math"
+	"time"
+)
+
+// Pricing rule statuses. Only PUBLISHED rules price tickets; DRAFT rules can be simulated and
+// edited, RETIRED rules are kept so old quotes still explain their price.
+const (
+	RuleDraft     = "DRAFT"
+	RulePublished = "PUBLISHED"
+	RuleRetired   = "RETIRED"
+)
+
+// Bounds on the combined multiplier of all matching rules, so stacked rules can neither give
+// seats away nor price them absurdly.
+const (
+	MinMultiplier = 0.5
+	MaxMultiplier = 3.0
+)
+
+// Rule multiplies a flight's base price when all of its conditions match. A nil condition
+// (or empty DaysOfWeek) matches any flight.
+type Rule struct {
+	ID                 int64     `json:"id"`
+	Name               string    `json:"name"`
+	Origin             *string   `json:"origin"`
+	Destination        *string   `json:"destination"`
+	MinLoadFactor      *float64  `json:"min_load_factor"` // Percent of seats sold, inclusive
+	MaxLoadFactor      *float64  `json:"max_load_factor"`
+	MinDaysToDeparture *int      `json:"min_days_to_departure"` // Whole days left before departure, inclusive
+	MaxDaysToDeparture *int      `json:"max_days_to_departure"`
+	DaysOfWeek         []int64   `json:"days_of_week"` // Departure weekdays, 0 = Sunday
+	Multiplier         float64   `json:"multiplier"`
+	Status             string    `json:"status"` // DRAFT, PUBLISHED, RETIRED
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// Matches reports whether every condition of the rule holds for the given demand.
+func (r *Rule) Matches(d Demand) bool {
+	if r.Origin != nil && *r.Origin != d.Origin {
+		return false
+	}
+	if r.Destination != nil && *r.Destination != d.Destination {
+		return false
+	}
+	if r.MinLoadFactor != nil && d.LoadFactor < *r.MinLoadFactor {
+		return false
+	}
+	if r.MaxLoadFactor != nil && d.LoadFactor > *r.MaxLoadFactor {
+		return false
+	}
+	if r.MinDaysToDeparture != nil && d.DaysToDeparture < *r.MinDaysToDeparture {
+		return false
+	}
+	if r.MaxDaysToDeparture != nil && d.DaysToDeparture > *r.MaxDaysToDeparture {
+		return false
+	}
+	if len(r.DaysOfWeek) > 0 {
+		for _, day := range r.DaysOfWeek {
+			if time.Weekday(day) == d.DayOfWeek {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Demand is the state of a flight that pricing rules are matched against.
+type Demand struct {
+	Origin          string       `json:"origin"`
+	Destination     string       `json:"destination"`
+	LoadFactor      float64      `json:"load_factor"` // Percent of seats held by active or unpaid tickets
+	DaysToDeparture int          `json:"days_to_departure"`
+	DayOfWeek       time.Weekday `json:"day_of_week"` // Of the departure in the origin's local time, 0 = Sunday
+}
+
+// Price is a flight's fare under a set of rules.
+type Price struct {
+	BasePrice  float64 `json:"base_price"` // Fare of the booking class before the rules
+	Multiplier float64 `json:"multiplier"` // Product of the matching rules' multipliers, within the bounds
+	Amount     float64 `json:"amount"`
+	Rules      []Rule  `json:"rules"` // Rules that matched
+}
+
+// apply prices base under the rules matching d.
+func apply(base float64, rules []Rule, d Demand) Price {
+	p := Price{BasePrice: base, Multiplier: 1, Rules: []Rule{}}
+	for _, r := range rules {
+		if r.Matches(d) {
+			p.Multiplier *= r.Multiplier
+			p.Rules = append(p.Rules, r)
+		}
+	}
+	p.Multiplier = math.Min(math.Max(p.Multiplier, MinMultiplier), MaxMultiplier)
+	p.Amount = math.Round(base*p.Multiplier*100) / 100
+	return p
+}
+
+// Quote is a flight's price at one moment. Quotes created for a user lock the price until
+// ExpiresAt; booking with the quote uses it up.
+type Quote struct {
+	ID           int64      `json:"id,omitempty"` // Zero for prices that are not locked
+	FlightID     int64      `json:"flight_id"`
+	BookingClass string     `json:"booking_class"` // Its fare bucket's fare is the base price
+	UserID       int64      `json:"-"`
+	Currency     string     `json:"currency"`
+	Demand       Demand     `json:"demand"`
+	RuleIDs      []int64    `json:"rule_ids"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	UsedAt       *time.Time `json:"used_at,omitempty"`
+	BookingID    *int64     `json:"booking_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Price
+
+	departureTime time.Time // Of the quoted flight, for locked quotes read back
+}
+
+// QuoteRequest defines the body for locking a flight's price.
+type QuoteRequest struct {
+	BookingClass string `json:"booking_class" binding:"omitempty,len=1"` // Optional: the cheapest economy class with seats left by default
+}
+
+// RuleRequest defines the body for creating or editing a pricing rule.
+type RuleRequest struct {
+	Name               string   `json:"name" binding:"required,max=100"`
+	Origin             *string  `json:"origin" binding:"omitempty,len=3"`
+	Destination        *string  `json:"destination" binding:"omitempty,len=3"`
+	MinLoadFactor      *float64 `json:"min_load_factor" binding:"omitempty,min=0,max=100"`
+	MaxLoadFactor      *float64 `json:"max_load_factor" binding:"omitempty,min=0,max=100"`
+	MinDaysToDeparture *int     `json:"min_days_to_departure" binding:"omitempty,min=0"`
+	MaxDaysToDeparture *int     `json:"max_days_to_departure" binding:"omitempty,min=0"`
+	DaysOfWeek         []int64  `json:"days_of_week" binding:"omitempty,dive,min=0,max=6"`
+	Multiplier         float64  `json:"multiplier" binding:"required,gt=0,lte=10"`
+}
+
+// SimulateRequest defines the body for simulating a flight's prices. Each combination of the
+// listed load factors and days to departure is priced; omitted lists use the flight's current values.
+type SimulateRequest struct {
+	BookingClass    string    `json:"booking_class" binding:"omitempty,len=1"` // Optional: the cheapest economy class with seats left by default
+	LoadFactors     []float64 `json:"load_factors" binding:"omitempty,max=50,dive,min=0,max=100"`
+	DaysToDeparture []int     `json:"days_to_departure" binding:"omitempty,max=50,dive,min=0"`
+	RuleIDs         []int64   `json:"rule_ids"` // Optional: draft rules to try with the published ones; every draft if empty
+}
+
+// Scenario is a simulated price for one demand.
+type Scenario struct {
+	Demand Demand `json:"demand"`
+	Price
+}
+
+// Simulation compares a flight's current price with the prices it would get from the
+// published rules plus the simulated drafts.
+type Simulation struct {
+	FlightID     int64      `json:"flight_id"`
+	BookingClass string     `json:"booking_class"`
+	Currency     string     `json:"currency"`
+	Current      Scenario   `json:"current"` // Published rules at the flight's current demand
+	Rules        []Rule     `json:"rules"`   // Rules the scenarios were priced with
+	Scenarios    []Scenario `json:"scenarios"`
+}
@@ -0,0 +1,204 @@
+package pricing
+
+import (
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrRuleNotFound is returned when the pricing rule does not exist.
+	ErrRuleNotFound = apperror.NotFound("pricing_rule_not_found", "pricing rule not found")
+	// ErrQuoteNotFound is returned when the price quote does not exist or belongs to another user.
+	ErrQuoteNotFound = apperror.NotFound("price_quote_not_found", "price quote not found")
+)
+
+// Repository handles database interactions for pricing rules and quotes.
+type Repository struct {
+	DB *sql.DB
+}
+
+// NewRepository creates a new pricing repository.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{DB: db}
+}
+
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+const ruleColumns = `id, name, origin, destination, min_load_factor, max_load_factor,
+	min_days_to_departure, max_days_to_departure, days_of_week, multiplier, status, created_at, updated_at`
+
+func scanRule(row interface{ Scan(...any) error }, rule *Rule) error {
+	var minDays, maxDays sql.NullInt64
+	var daysOfWeek pq.Int64Array
+	err := row.Scan(
+		&rule.ID, &rule.Name, &rule.Origin, &rule.Destination, &rule.MinLoadFactor, &rule.MaxLoadFactor,
+		&minDays, &maxDays, &daysOfWeek, &rule.Multiplier, &rule.Status, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if minDays.Valid {
+		v := int(minDays.Int64)
+		rule.MinDaysToDeparture = &v
+	}
+	if maxDays.Valid {
+		v := int(maxDays.Int64)
+		rule.MaxDaysToDeparture = &v
+	}
+	rule.DaysOfWeek = []int64(daysOfWeek)
+	if rule.DaysOfWeek == nil {
+		rule.DaysOfWeek = []int64{}
+	}
+	return nil
+}
+
+// CreateRule inserts a new pricing rule.
+func (r *Repository) CreateRule(ctx context.Context, rule *Rule) error {
+	query := `
+		INSERT INTO pricing_rules (name, origin, destination, min_load_factor, max_load_factor,
+			min_days_to_departure, max_days_to_departure, days_of_week, multiplier, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		rule.Name, rule.Origin, rule.Destination, rule.MinLoadFactor, rule.MaxLoadFactor,
+		rule.MinDaysToDeparture, rule.MaxDaysToDeparture, pq.Array(rule.DaysOfWeek), rule.Multiplier, rule.Status,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create pricing rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateRule stores a pricing rule's conditions, multiplier and status.
+func (r *Repository) UpdateRule(ctx context.Context, rule *Rule) error {
+	query := `
+		UPDATE pricing_rules
+		SET name = $2, origin = $3, destination = $4, min_load_factor = $5, max_load_factor = $6,
+			min_days_to_departure = $7, max_days_to_departure = $8, days_of_week = $9, multiplier = $10,
+			status = $11, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		rule.ID, rule.Name, rule.Origin, rule.Destination, rule.MinLoadFactor, rule.MaxLoadFactor,
+		rule.MinDaysToDeparture, rule.MaxDaysToDeparture, pq.Array(rule.DaysOfWeek), rule.Multiplier, rule.Status,
+	).Scan(&rule.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrRuleNotFound
+		}
+		return fmt.Errorf("failed to update pricing rule: %w", err)
+	}
+	return nil
+}
+
+// GetRuleForUpdate retrieves a pricing rule and locks it until the transaction ends.
+func (r *Repository) GetRuleForUpdate(ctx context.Context, id int64) (*Rule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM pricing_rules WHERE id = $1 FOR UPDATE`
+	var rule Rule
+	if err := scanRule(r.executor(ctx).QueryRowContext(ctx, query, id), &rule); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pricing rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// ListRules returns the pricing rules in any of the given statuses, or every rule if none is given.
+func (r *Repository) ListRules(ctx context.Context, statuses ...string) ([]Rule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM pricing_rules WHERE cardinality($1::text[]) = 0 OR status = ANY($1) ORDER BY id`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, pq.Array(statuses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pricing rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []Rule{}
+	for rows.Next() {
+		var rule Rule
+		if err := scanRule(rows, &rule); err != nil {
+			return nil, fmt.Errorf("failed to scan pricing rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// CountSeatedTickets returns the number of active or unpaid tickets on a flight.
+func (r *Repository) CountSeatedTickets(ctx context.Context, flightID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM tickets WHERE flight_id = $1 AND status IN ('ACTIVE', 'PENDING_PAYMENT')`
+	var n int
+	if err := r.executor(ctx).QueryRowContext(ctx, query, flightID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+	return n, nil
+}
+
+// CreateQuote inserts a locked price quote.
+func (r *Repository) CreateQuote(ctx context.Context, q *Quote) error {
+	query := `
+		INSERT INTO price_quotes (flight_id, booking_class, user_id, base_price, multiplier, amount, currency,
+			load_factor, days_to_departure, rule_ids, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		q.FlightID, q.BookingClass, q.UserID, q.BasePrice, q.Multiplier, q.Amount, q.Currency,
+		q.Demand.LoadFactor, q.Demand.DaysToDeparture, pq.Array(q.RuleIDs), q.ExpiresAt,
+	).Scan(&q.ID, &q.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create price quote: %w", err)
+	}
+	return nil
+}
+
+// GetQuoteForUpdate retrieves a price quote and locks it until the transaction ends. The
+// demand's day of the week is left to the caller, which knows the origin's time zone.
+func (r *Repository) GetQuoteForUpdate(ctx context.Context, id int64) (*Quote, error) {
+	query := `
+		SELECT q.id, q.flight_id, q.booking_class, q.user_id, q.base_price, q.multiplier, q.amount, q.currency,
+			q.load_factor, q.days_to_departure, q.rule_ids, q.expires_at, q.used_at, q.booking_id, q.created_at,
+			f.origin, f.destination, f.departure_time
+		FROM price_quotes q
+		JOIN flights f ON f.id = q.flight_id
+		WHERE q.id = $1
+		FOR UPDATE OF q
+	`
+	var q Quote
+	var ruleIDs pq.Int64Array
+	err := r.executor(ctx).QueryRowContext(ctx, query, id).Scan(
+		&q.ID, &q.FlightID, &q.BookingClass, &q.UserID, &q.BasePrice, &q.Multiplier, &q.Amount, &q.Currency,
+		&q.Demand.LoadFactor, &q.Demand.DaysToDeparture, &ruleIDs, &q.ExpiresAt, &q.UsedAt, &q.BookingID, &q.CreatedAt,
+		&q.Demand.Origin, &q.Demand.Destination, &q.departureTime,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get price quote: %w", err)
+	}
+	q.RuleIDs = []int64(ruleIDs)
+	q.Rules = []Rule{}
+	return &q, nil
+}
+
+// MarkQuoteUsed records that a quote was spent on a booking.
+func (r *Repository) MarkQuoteUsed(ctx context.Context, id, bookingID int64) error {
+	query := `UPDATE price_quotes SET used_at = NOW(), booking_id = $2 WHERE id = $1`
+	if _, err := r.executor(ctx).ExecContext(ctx, query, id, bookingID); err != nil {
+		return fmt.Errorf("failed to use price quote: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package pricing
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes sets up the flight price, price quote and pricing rule routes.
+func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	priceGroup := r.Group("/flights/:id")
+	{
+		priceGroup.GET("/price", h.GetPrice)
+
+		// Protected routes
+		priceGroup.POST("/price-quotes", authMiddleware, h.CreateQuote)
+	}
+
+	pricingGroup := r.Group("/pricing")
+	pricingGroup.Use(authMiddleware)
+	{
+		pricingGroup.GET("/rules", h.ListRules)
+		pricingGroup.POST("/rules", h.CreateRule)
+		pricingGroup.PUT("/rules/:id", h.UpdateRule)
+		pricingGroup.POST("/rules/:id/publish", h.PublishRule)
+		pricingGroup.POST("/rules/:id/retire", h.RetireRule)
+		pricingGroup.POST("/flights/:id/simulate", h.Simulate)
+	}
+}
@@ -0,0 +1,422 @@
+package pricing
+
+import (
+	"airport-system/internal/airport"
+	"airport-system/internal/flight"
+	"airport-system/internal/payment"
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrFlightNotFound is returned when pricing a flight that does not exist.
+	ErrFlightNotFound = apperror.NotFound("flight_not_found", "flight not found")
+	// ErrInvalidRule is returned when a pricing rule's conditions contradict each other.
+	ErrInvalidRule = apperror.Validation("invalid_pricing_rule", "invalid pricing rule")
+	// ErrRuleNotDraft is returned when editing or publishing a rule that is no longer a draft.
+	ErrRuleNotDraft = apperror.Conflict("pricing_rule_not_draft", "only draft pricing rules can be changed")
+	// ErrRuleNotPublished is returned when retiring a rule that is not published.
+	ErrRuleNotPublished = apperror.Conflict("pricing_rule_not_published", "pricing rule is not published")
+	// ErrQuoteExpired is returned when booking with a quote after its deadline.
+	ErrQuoteExpired = apperror.Conflict("price_quote_expired", "price quote has expired; request a new one")
+	// ErrQuoteUsed is returned when booking with a quote that was already used.
+	ErrQuoteUsed = apperror.Conflict("price_quote_used", "price quote has already been used")
+	// ErrQuoteMismatch is returned when booking a flight or class with another one's quote.
+	ErrQuoteMismatch = apperror.Validation("price_quote_mismatch", "price quote is for another flight or booking class")
+)
+
+// DefaultQuoteTTL is used when Config.QuoteTTL is zero.
+const DefaultQuoteTTL = 10 * time.Minute
+
+// Config holds pricing settings.
+type Config struct {
+	Currency string        // ISO 4217 code prices are quoted in; payment.DefaultCurrency if empty
+	QuoteTTL time.Duration // How long a locked quote is honoured by booking
+}
+
+// Service prices flights with the published pricing rules and locks quotes for booking.
+type Service struct {
+	repo           *Repository
+	flightRepo     *flight.Repository
+	airportService *airport.Service
+	txManager      database.TxManager
+	log            *slog.Logger
+	cfg            Config
+}
+
+// NewService creates a new pricing service.
+func NewService(repo *Repository, flightRepo *flight.Repository, airportService *airport.Service, txManager database.TxManager, log *slog.Logger, cfg Config) *Service {
+	if cfg.Currency == "" {
+		cfg.Currency = payment.DefaultCurrency
+	}
+	if cfg.QuoteTTL <= 0 {
+		cfg.QuoteTTL = DefaultQuoteTTL
+	}
+	return &Service{
+		repo:           repo,
+		flightRepo:     flightRepo,
+		airportService: airportService,
+		txManager:      txManager,
+		log:            log,
+		cfg:            cfg,
+	}
+}
+
+// CreateRule validates and stores a new draft pricing rule.
+func (s *Service) CreateRule(ctx context.Context, req RuleRequest) (*Rule, error) {
+	rule := &Rule{Status: RuleDraft}
+	if err := applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Pricing rule created", "rule_id", rule.ID, "name", rule.Name)
+	return rule, nil
+}
+
+// UpdateRule replaces the conditions and multiplier of a draft rule. Published rules are
+// retired and replaced instead, so that issued quotes keep explaining their price.
+func (s *Service) UpdateRule(ctx context.Context, id int64, req RuleRequest) (*Rule, error) {
+	var rule *Rule
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		var err error
+		rule, err = s.lockRule(ctx, id)
+		if err != nil {
+			return err
+		}
+		if rule.Status != RuleDraft {
+			return ErrRuleNotDraft
+		}
+		if err := applyRuleRequest(rule, req); err != nil {
+			return err
+		}
+		return s.repo.UpdateRule(ctx, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// PublishRule makes a draft rule price tickets.
+func (s *Service) PublishRule(ctx context.Context, id int64) (*Rule, error) {
+	return s.setRuleStatus(ctx, id, RuleDraft, RulePublished, ErrRuleNotDraft)
+}
+
+// RetireRule stops a published rule from pricing tickets.
+func (s *Service) RetireRule(ctx context.Context, id int64) (*Rule, error) {
+	return s.setRuleStatus(ctx, id, RulePublished, RuleRetired, ErrRuleNotPublished)
+}
+
+func (s *Service) setRuleStatus(ctx context.Context, id int64, from, to string, errWrongStatus error) (*Rule, error) {
+	var rule *Rule
+	err := s.txManager.Run(ctx, func(ctx context.Context) error {
+		var err error
+		rule, err = s.lockRule(ctx, id)
+		if err != nil {
+			return err
+		}
+		if rule.Status != from {
+			return errWrongStatus
+		}
+		rule.Status = to
+		return s.repo.UpdateRule(ctx, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Pricing rule status changed", "rule_id", id, "status", to)
+	return rule, nil
+}
+
+// ListRules returns the pricing rules, optionally only those in one status.
+func (s *Service) ListRules(ctx context.Context, status string) ([]Rule, error) {
+	if status == "" {
+		return s.repo.ListRules(ctx)
+	}
+	return s.repo.ListRules(ctx, strings.ToUpper(status))
+}
+
+// CurrentPrice prices a booking class of a flight with the published rules at its current
+// demand. An empty class prices the cheapest economy class with seats left. The price is not locked.
+func (s *Service) CurrentPrice(ctx context.Context, flightID int64, bookingClass string) (*Quote, error) {
+	f, bucket, err := s.getBucket(ctx, flightID, bookingClass)
+	if err != nil {
+		return nil, err
+	}
+	return s.price(ctx, f, bucket, time.Now())
+}
+
+// CreateQuote prices a booking class of a flight for a user and locks the price for the
+// configured TTL. An empty class quotes the cheapest economy class with seats left.
+func (s *Service) CreateQuote(ctx context.Context, userID, flightID int64, bookingClass string) (*Quote, error) {
+	f, bucket, err := s.getBucket(ctx, flightID, bookingClass)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	quote, err := s.price(ctx, f, bucket, now)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(s.cfg.QuoteTTL)
+	quote.UserID = userID
+	quote.ExpiresAt = &expiresAt
+	if err := s.repo.CreateQuote(ctx, quote); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Price quote created", "quote_id", quote.ID, "flight_id", flightID, "user_id", userID, "amount", quote.Amount)
+	return quote, nil
+}
+
+// UseQuote spends a user's locked quote on a booking of the quoted flight and returns it. An
+// empty booking class accepts the quote's class. It must run inside the booking transaction so
+// the quote stays unused if the booking fails.
+func (s *Service) UseQuote(ctx context.Context, userID, quoteID, flightID int64, bookingClass string, bookingID int64) (*Quote, error) {
+	quote, err := s.repo.GetQuoteForUpdate(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if quote == nil || quote.UserID != userID {
+		return nil, ErrQuoteNotFound
+	}
+	if quote.Demand.DayOfWeek, err = s.departureDay(ctx, quote.Demand.Origin, quote.departureTime); err != nil {
+		return nil, err
+	}
+	if quote.FlightID != flightID || (bookingClass != "" && quote.BookingClass != strings.ToUpper(bookingClass)) {
+		return nil, ErrQuoteMismatch
+	}
+	if quote.UsedAt != nil {
+		return nil, ErrQuoteUsed
+	}
+	if !quote.ExpiresAt.After(time.Now()) {
+		return nil, ErrQuoteExpired
+	}
+
+	if err := s.repo.MarkQuoteUsed(ctx, quoteID, bookingID); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// Simulate prices a flight under the published rules plus draft rules, for each combination
+// of the requested load factors and days to departure.
+func (s *Service) Simulate(ctx context.Context, flightID int64, req SimulateRequest) (*Simulation, error) {
+	f, bucket, err := s.getBucket(ctx, flightID, req.BookingClass)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	current, err := s.demand(ctx, f, now)
+	if err != nil {
+		return nil, err
+	}
+
+	published, err := s.repo.ListRules(ctx, RulePublished)
+	if err != nil {
+		return nil, err
+	}
+	drafts, err := s.repo.ListRules(ctx, RuleDraft)
+	if err != nil {
+		return nil, err
+	}
+	rules := append([]Rule{}, published...)
+	if len(req.RuleIDs) == 0 {
+		rules = append(rules, drafts...)
+	} else {
+		byID := make(map[int64]Rule, len(drafts))
+		for _, r := range drafts {
+			byID[r.ID] = r
+		}
+		for _, id := range req.RuleIDs {
+			r, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("%w: %d is not a draft rule", ErrRuleNotFound, id)
+			}
+			rules = append(rules, r)
+		}
+	}
+
+	loadFactors := req.LoadFactors
+	if len(loadFactors) == 0 {
+		loadFactors = []float64{current.LoadFactor}
+	}
+	days := req.DaysToDeparture
+	if len(days) == 0 {
+		days = []int{current.DaysToDeparture}
+	}
+
+	sim := &Simulation{
+		FlightID:     flightID,
+		BookingClass: bucket.BookingClass,
+		Currency:     s.cfg.Currency,
+		Current:      Scenario{Demand: current, Price: apply(bucket.Fare, published, current)},
+		Rules:        rules,
+		Scenarios:    make([]Scenario, 0, len(loadFactors)*len(days)),
+	}
+	for _, lf := range loadFactors {
+		for _, d := range days {
+			demand := current
+			demand.LoadFactor = lf
+			demand.DaysToDeparture = d
+			sim.Scenarios = append(sim.Scenarios, Scenario{Demand: demand, Price: apply(bucket.Fare, rules, demand)})
+		}
+	}
+	return sim, nil
+}
+
+// price prices a flight's fare bucket with the published rules at the flight's demand at now.
+func (s *Service) price(ctx context.Context, f *flight.Flight, bucket *flight.FareBucket, now time.Time) (*Quote, error) {
+	demand, err := s.demand(ctx, f, now)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.repo.ListRules(ctx, RulePublished)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &Quote{
+		FlightID:     f.ID,
+		BookingClass: bucket.BookingClass,
+		Currency:     s.cfg.Currency,
+		Demand:       demand,
+		CreatedAt:    now,
+		Price:        apply(bucket.Fare, rules, demand),
+	}
+	quote.RuleIDs = make([]int64, len(quote.Rules))
+	for i, r := range quote.Rules {
+		quote.RuleIDs[i] = r.ID
+	}
+	return quote, nil
+}
+
+// demand measures the flight's load factor and time to departure at now.
+func (s *Service) demand(ctx context.Context, f *flight.Flight, now time.Time) (Demand, error) {
+	seated, err := s.repo.CountSeatedTickets(ctx, f.ID)
+	if err != nil {
+		return Demand{}, err
+	}
+
+	day, err := s.departureDay(ctx, f.Origin, f.DepartureTime)
+	if err != nil {
+		return Demand{}, err
+	}
+	d := Demand{
+		Origin:      f.Origin,
+		Destination: f.Destination,
+		DayOfWeek:   day,
+	}
+	if f.TotalSeats > 0 {
+		d.LoadFactor = float64(seated) * 100 / float64(f.TotalSeats)
+	}
+	if until := f.DepartureTime.Sub(now); until > 0 {
+		d.DaysToDeparture = int(until / (24 * time.Hour))
+	}
+	return d, nil
+}
+
+// departureDay returns the day of the week of a departure in its origin's local time.
+// Airports missing from the reference data fall back to UTC, as in flight searches.
+func (s *Service) departureDay(ctx context.Context, origin string, departure time.Time) (time.Weekday, error) {
+	loc, err := s.airportService.Location(ctx, origin)
+	if errors.Is(err, airport.ErrAirportNotFound) {
+		return departure.UTC().Weekday(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return departure.In(loc).Weekday(), nil
+}
+
+// getBucket loads a flight and the fare bucket of a booking class. An empty class picks the
+// cheapest economy class with seats left, or the cheapest economy class if none has.
+func (s *Service) getBucket(ctx context.Context, flightID int64, bookingClass string) (*flight.Flight, *flight.FareBucket, error) {
+	f, err := s.flightRepo.GetByID(ctx, flightID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get flight: %w", err)
+	}
+	if f == nil {
+		return nil, nil, ErrFlightNotFound
+	}
+	buckets, err := s.flightRepo.ListFareBuckets(ctx, flightID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var bucket *flight.FareBucket
+	if bookingClass != "" {
+		bucket = flight.FindBucket(buckets, strings.ToUpper(bookingClass))
+	} else if bucket = flight.CheapestAvailable(buckets, flight.CabinEconomy); bucket == nil {
+		for i := range buckets {
+			if buckets[i].Cabin == flight.CabinEconomy {
+				bucket = &buckets[i]
+			}
+		}
+	}
+	if bucket == nil {
+		return nil, nil, flight.ErrBookingClassNotOffered
+	}
+	return f, bucket, nil
+}
+
+func (s *Service) lockRule(ctx context.Context, id int64) (*Rule, error) {
+	rule, err := s.repo.GetRuleForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrRuleNotFound
+	}
+	return rule, nil
+}
+
+// applyRuleRequest copies a validated request onto a rule.
+func applyRuleRequest(rule *Rule, req RuleRequest) error {
+	if req.MinLoadFactor != nil && req.MaxLoadFactor != nil && *req.MinLoadFactor > *req.MaxLoadFactor {
+		return fmt.Errorf("%w: min_load_factor is above max_load_factor", ErrInvalidRule)
+	}
+	if req.MinDaysToDeparture != nil && req.MaxDaysToDeparture != nil && *req.MinDaysToDeparture > *req.MaxDaysToDeparture {
+		return fmt.Errorf("%w: min_days_to_departure is above max_days_to_departure", ErrInvalidRule)
+	}
+
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Origin = upperCode(req.Origin)
+	rule.Destination = upperCode(req.Destination)
+	if rule.Origin != nil && rule.Destination != nil && *rule.Origin == *rule.Destination {
+		return fmt.Errorf("%w: origin and destination are the same", ErrInvalidRule)
+	}
+	rule.MinLoadFactor = req.MinLoadFactor
+	rule.MaxLoadFactor = req.MaxLoadFactor
+	rule.MinDaysToDeparture = req.MinDaysToDeparture
+	rule.MaxDaysToDeparture = req.MaxDaysToDeparture
+	rule.DaysOfWeek = []int64{}
+	seen := make(map[int64]bool, len(req.DaysOfWeek))
+	for _, day := range req.DaysOfWeek {
+		if !seen[day] {
+			seen[day] = true
+			rule.DaysOfWeek = append(rule.DaysOfWeek, day)
+		}
+	}
+	rule.Multiplier = req.Multiplier
+	return nil
+}
+
+func upperCode(code *string) *string {
+	if code == nil {
+		return nil
+	}
+	v := strings.ToUpper(strings.TrimSpace(*code))
+	return &v
+}
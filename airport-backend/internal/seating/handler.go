@@ -0,0 +1,131 @@
+package seating
+
+import (
+	"airport-system/internal/auth"
+	"airport-system/platform/apperror"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler manages HTTP requests for seat maps and seat holds.
+type Handler struct {
+	Service *Service
+}
+
+// NewHandler creates a new seating handler.
+func NewHandler(service *Service) *Handler {
+	return &Handler{Service: service}
+}
+
+// GetSeatMap handles retrieving a flight's seat availability.
+func (h *Handler) GetSeatMap(c *gin.Context) {
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	seatMap, err := h.Service.GetSeatMap(c.Request.Context(), flightID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, seatMap)
+}
+
+// HoldSeat handles reserving a seat before booking.
+func (h *Handler) HoldSeat(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.Error(apperror.ErrUnauthenticated)
+		return
+	}
+	userID := userIDVal.(int64)
+
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	var req HoldSeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	hold, err := h.Service.HoldSeat(c.Request.Context(), userID, flightID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// ReleaseHold handles releasing a seat hold.
+func (h *Handler) ReleaseHold(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.Error(apperror.ErrUnauthenticated)
+		return
+	}
+	userID := userIDVal.(int64)
+
+	flightID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+	holdID, err := strconv.ParseInt(c.Param("holdId"), 10, 64)
+	if err != nil {
+		c.Error(apperror.ErrInvalidID)
+		return
+	}
+
+	if err := h.Service.ReleaseHold(c.Request.Context(), userID, flightID, holdID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Seat hold released"})
+}
+
+// CreateLayout handles cabin layout creation (ADMIN only).
+func (h *Handler) CreateLayout(c *gin.Context) {
+	if !auth.RequireRole(c, "ADMIN") {
+		return
+	}
+
+	var req CreateLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.BadRequest(err))
+		return
+	}
+
+	layout, err := h.Service.CreateLayout(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, layout)
+}
+
+// ListLayouts lists all cabin layouts (STAFF, ADMIN).
+func (h *Handler) ListLayouts(c *gin.Context) {
+	if !auth.RequireRole(c, "STAFF", "ADMIN") {
+		return
+	}
+
+	layouts, err := h.Service.ListLayouts(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, layouts)
+}
@@ -0,0 +1,219 @@
+package seating
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Seat statuses shown on a seat map.
+const (
+	SeatAvailable = "AVAILABLE"
+	SeatHeld      = "HELD"
+	SeatOccupied  = "OCCUPIED"
+	SeatBlocked   = "BLOCKED"
+)
+
+// Cabins, from the front of the aircraft. Each booking class is sold in one of them.
+const (
+	CabinFirst    = "F"
+	CabinBusiness = "C"
+	CabinEconomy  = "Y"
+)
+
+const (
+	// DefaultHoldMinutes is used when a hold request does not specify a duration.
+	DefaultHoldMinutes = 10
+	// MaxHoldMinutes caps how long a seat can be held before booking.
+	MaxHoldMinutes = 30
+)
+
+// CabinLayout describes the seat grid of an aircraft and how its rows divide into cabins.
+type CabinLayout struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Rows         int       `json:"rows"`
+	SeatLetters  string    `json:"seat_letters"`  // e.g. "ABCDEF"
+	BlockedSeats []string  `json:"blocked_seats"` // e.g. ["1A", "1F"]
+	ExitRows     []int64   `json:"exit_rows"`
+	FirstRows    int       `json:"first_rows"`    // Rows from the front that are first class
+	BusinessRows int       `json:"business_rows"` // Rows after first class that are business class
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// DefaultLayout builds an all-economy six-abreast layout for flights without an explicit
+// cabin layout. Seats beyond totalSeats in the last row are blocked.
+func DefaultLayout(totalSeats int) *CabinLayout {
+	const letters = "ABCDEF"
+	rows := (totalSeats + len(letters) - 1) / len(letters)
+	layout := &CabinLayout{
+		Name:         "DEFAULT",
+		Rows:         rows,
+		SeatLetters:  letters,
+		BlockedSeats: []string{},
+		ExitRows:     []int64{},
+	}
+	for i := totalSeats; i < rows*len(letters); i++ {
+		layout.BlockedSeats = append(layout.BlockedSeats, fmt.Sprintf("%d%c", rows, letters[i%len(letters)]))
+	}
+	return layout
+}
+
+// SeatCount returns the number of sellable seats in the layout.
+func (l *CabinLayout) SeatCount() int {
+	blocked := 0
+	for _, seatNo := range l.BlockedSeats {
+		if l.inGrid(seatNo) {
+			blocked++
+		}
+	}
+	return l.Rows*len(l.SeatLetters) - blocked
+}
+
+// CabinSeats returns the number of sellable seats in each cabin that has any.
+func (l *CabinLayout) CabinSeats() map[string]int {
+	seats := make(map[string]int)
+	for row := 1; row <= l.Rows; row++ {
+		for _, letter := range l.SeatLetters {
+			if !l.isBlocked(fmt.Sprintf("%d%c", row, letter)) {
+				seats[l.CabinOf(row)]++
+			}
+		}
+	}
+	return seats
+}
+
+// CabinOf returns the cabin a row belongs to.
+func (l *CabinLayout) CabinOf(row int) string {
+	switch {
+	case row <= l.FirstRows:
+		return CabinFirst
+	case row <= l.FirstRows+l.BusinessRows:
+		return CabinBusiness
+	default:
+		return CabinEconomy
+	}
+}
+
+// HasSeat reports whether seatNo exists in the layout and is not blocked.
+func (l *CabinLayout) HasSeat(seatNo string) bool {
+	return l.inGrid(seatNo) && !l.isBlocked(seatNo)
+}
+
+// HasSeatIn reports whether seatNo exists in the layout, is not blocked and is in the cabin.
+func (l *CabinLayout) HasSeatIn(seatNo, cabin string) bool {
+	if !l.HasSeat(seatNo) {
+		return false
+	}
+	row, _, _ := ParseSeatNo(seatNo)
+	return l.CabinOf(row) == cabin
+}
+
+// IsExitRow reports whether the given row is an exit row.
+func (l *CabinLayout) IsExitRow(row int) bool {
+	for _, r := range l.ExitRows {
+		if int(r) == row {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *CabinLayout) inGrid(seatNo string) bool {
+	row, letter, err := ParseSeatNo(seatNo)
+	if err != nil {
+		return false
+	}
+	return row >= 1 && row <= l.Rows && strings.ContainsRune(l.SeatLetters, letter)
+}
+
+func (l *CabinLayout) isBlocked(seatNo string) bool {
+	for _, blocked := range l.BlockedSeats {
+		if strings.EqualFold(blocked, seatNo) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSeatNo splits a seat number like "12C" into its row and letter.
+func ParseSeatNo(seatNo string) (int, rune, error) {
+	seatNo = strings.ToUpper(strings.TrimSpace(seatNo))
+	if len(seatNo) < 2 {
+		return 0, 0, fmt.Errorf("invalid seat number %q", seatNo)
+	}
+	letter := rune(seatNo[len(seatNo)-1])
+	if letter < 'A' || letter > 'Z' {
+		return 0, 0, fmt.Errorf("invalid seat number %q", seatNo)
+	}
+	row, err := strconv.Atoi(seatNo[:len(seatNo)-1])
+	if err != nil || row <= 0 {
+		return 0, 0, fmt.Errorf("invalid seat number %q", seatNo)
+	}
+	return row, letter, nil
+}
+
+// NormalizeSeatNo returns seatNo in canonical upper-case form.
+func NormalizeSeatNo(seatNo string) string {
+	return strings.ToUpper(strings.TrimSpace(seatNo))
+}
+
+// Seat is a single position on a seat map.
+type Seat struct {
+	SeatNo  string `json:"seat_no"`
+	Row     int    `json:"row"`
+	Letter  string `json:"letter"`
+	ExitRow bool   `json:"exit_row"`
+	Cabin   string `json:"cabin"`
+	Status  string `json:"status"` // AVAILABLE, HELD, OCCUPIED, BLOCKED
+}
+
+// SeatMap is the availability of every seat on a flight.
+type SeatMap struct {
+	FlightID  int64        `json:"flight_id"`
+	Layout    *CabinLayout `json:"layout"`
+	Available int          `json:"available"`
+	Seats     []Seat       `json:"seats"`
+}
+
+// SeatHold reserves a seat for a user until ExpiresAt.
+type SeatHold struct {
+	ID        int64     `json:"id"`
+	FlightID  int64     `json:"flight_id"`
+	SeatNo    string    `json:"seat_no"`
+	UserID    int64     `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SeatedTicket is a seat assigned to a ticket, with the cabin the ticket was sold in.
+type SeatedTicket struct {
+	TicketID int64
+	SeatNo   string
+	Cabin    string
+}
+
+// FlightSeating holds the flight attributes the seating module needs.
+type FlightSeating struct {
+	FlightID      int64
+	TotalSeats    int
+	CabinLayoutID *int64
+}
+
+// CreateLayoutRequest defines the body for creating a cabin layout.
+type CreateLayoutRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Rows         int      `json:"rows" binding:"required,min=1,max=120"`
+	SeatLetters  string   `json:"seat_letters" binding:"required,min=1,max=12"`
+	BlockedSeats []string `json:"blocked_seats"`
+	ExitRows     []int64  `json:"exit_rows"`
+	FirstRows    int      `json:"first_rows" binding:"min=0"`
+	BusinessRows int      `json:"business_rows" binding:"min=0"`
+}
+
+// HoldSeatRequest defines the body for holding a seat.
+type HoldSeatRequest struct {
+	SeatNo  string `json:"seat_no" binding:"required"`
+	Minutes int    `json:"minutes"` // Optional: defaults to DefaultHoldMinutes
+}
@@ -0,0 +1,296 @@
+package seating
+
+import (
+	"airport-system/platform/apperror"
+	"airport-system/platform/database"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrFlightNotFound is returned when the flight does not exist.
+	ErrFlightNotFound = apperror.NotFound("flight_not_found", "flight not found")
+	// ErrLayoutNotFound is returned when the cabin layout does not exist.
+	ErrLayoutNotFound = apperror.NotFound("cabin_layout_not_found", "cabin layout not found")
+	// ErrInvalidLayout is returned when a cabin layout definition is malformed.
+	ErrInvalidLayout = apperror.Validation("invalid_cabin_layout", "invalid cabin layout")
+	// ErrLayoutConflict is returned when a layout cannot hold the flight's existing seat assignments.
+	ErrLayoutConflict = apperror.Conflict("cabin_layout_conflict", "cabin layout does not fit sold seats")
+	// ErrInvalidSeat is returned for seats that do not exist on the flight or bad hold requests.
+	ErrInvalidSeat = apperror.Validation("invalid_seat", "invalid seat")
+	// ErrSeatTaken is returned when an active ticket already has the seat.
+	ErrSeatTaken = apperror.Conflict("seat_taken", "seat is already taken")
+	// ErrSeatHeld is returned when another hold already exists for the seat.
+	ErrSeatHeld = apperror.Conflict("seat_held", "seat is already held")
+	// ErrHoldNotFound is returned when the seat hold does not exist or belongs to another flight.
+	ErrHoldNotFound = apperror.NotFound("seat_hold_not_found", "seat hold not found")
+	// ErrHoldForbidden is returned when releasing another user's hold.
+	ErrHoldForbidden = apperror.Forbidden("seat_hold_forbidden", "seat hold belongs to another user")
+	// ErrHoldExpired is returned when booking with an expired hold.
+	ErrHoldExpired = apperror.Conflict("seat_hold_expired", "seat hold has expired")
+	// ErrNoSeatsAvailable is returned when no seat is left to auto-assign.
+	ErrNoSeatsAvailable = apperror.Conflict("no_seats_available", "no seats available")
+)
+
+// Repository handles database interactions for seat maps and holds.
+type Repository struct {
+	DB *sql.DB
+}
+
+// NewRepository creates a new seating repository.
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{DB: db}
+}
+
+func (r *Repository) executor(ctx context.Context) database.Executor {
+	if tx := database.GetTx(ctx); tx != nil {
+		return tx
+	}
+	return r.DB
+}
+
+// layoutColumns is the column list read by scanLayout.
+const layoutColumns = `id, name, row_count, seat_letters, blocked_seats, exit_rows, first_rows, business_rows, created_at`
+
+func scanLayout(row interface{ Scan(...any) error }, l *CabinLayout) error {
+	return row.Scan(
+		&l.ID, &l.Name, &l.Rows, &l.SeatLetters, (*pq.StringArray)(&l.BlockedSeats), (*pq.Int64Array)(&l.ExitRows),
+		&l.FirstRows, &l.BusinessRows, &l.CreatedAt,
+	)
+}
+
+// CreateLayout inserts a new cabin layout.
+func (r *Repository) CreateLayout(ctx context.Context, l *CabinLayout) (int64, error) {
+	query := `
+		INSERT INTO cabin_layouts (name, row_count, seat_letters, blocked_seats, exit_rows, first_rows, business_rows, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id, created_at
+	`
+	var id int64
+	err := r.executor(ctx).QueryRowContext(ctx, query,
+		l.Name, l.Rows, l.SeatLetters, pq.Array(l.BlockedSeats), pq.Array(l.ExitRows), l.FirstRows, l.BusinessRows,
+	).Scan(&id, &l.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create cabin layout: %w", err)
+	}
+	return id, nil
+}
+
+// ListLayouts returns all cabin layouts.
+func (r *Repository) ListLayouts(ctx context.Context) ([]CabinLayout, error) {
+	query := `SELECT ` + layoutColumns + ` FROM cabin_layouts ORDER BY name`
+	rows, err := r.executor(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cabin layouts: %w", err)
+	}
+	defer rows.Close()
+
+	var layouts []CabinLayout
+	for rows.Next() {
+		var l CabinLayout
+		if err := scanLayout(rows, &l); err != nil {
+			return nil, fmt.Errorf("failed to scan cabin layout: %w", err)
+		}
+		layouts = append(layouts, l)
+	}
+	return layouts, nil
+}
+
+// GetLayout retrieves a cabin layout by ID.
+func (r *Repository) GetLayout(ctx context.Context, id int64) (*CabinLayout, error) {
+	query := `SELECT ` + layoutColumns + ` FROM cabin_layouts WHERE id = $1`
+	var l CabinLayout
+	err := scanLayout(r.executor(ctx).QueryRowContext(ctx, query, id), &l)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cabin layout: %w", err)
+	}
+	return &l, nil
+}
+
+// GetFlightSeating retrieves the capacity and layout of a flight.
+func (r *Repository) GetFlightSeating(ctx context.Context, flightID int64) (*FlightSeating, error) {
+	query := `SELECT id, total_seats, cabin_layout_id FROM flights WHERE id = $1`
+	var fs FlightSeating
+	err := r.executor(ctx).QueryRowContext(ctx, query, flightID).Scan(&fs.FlightID, &fs.TotalSeats, &fs.CabinLayoutID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get flight seating: %w", err)
+	}
+	return &fs, nil
+}
+
+// ListOccupiedSeats returns the seat numbers of active tickets on a flight.
+func (r *Repository) ListOccupiedSeats(ctx context.Context, flightID int64) ([]string, error) {
+	query := `SELECT seat_no FROM tickets WHERE flight_id = $1 AND status IN ('ACTIVE', 'PENDING_PAYMENT') AND seat_no IS NOT NULL`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list occupied seats: %w", err)
+	}
+	defer rows.Close()
+
+	var seats []string
+	for rows.Next() {
+		var seatNo string
+		if err := rows.Scan(&seatNo); err != nil {
+			return nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		seats = append(seats, seatNo)
+	}
+	return seats, nil
+}
+
+// ListSeatedTickets returns the seats assigned to active tickets on a flight with their cabins.
+func (r *Repository) ListSeatedTickets(ctx context.Context, flightID int64) ([]SeatedTicket, error) {
+	query := `
+		SELECT id, seat_no, fare_class
+		FROM tickets
+		WHERE flight_id = $1 AND status IN ('ACTIVE', 'PENDING_PAYMENT') AND seat_no IS NOT NULL
+		ORDER BY id
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seated tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var seated []SeatedTicket
+	for rows.Next() {
+		var t SeatedTicket
+		if err := rows.Scan(&t.TicketID, &t.SeatNo, &t.Cabin); err != nil {
+			return nil, fmt.Errorf("failed to scan seated ticket: %w", err)
+		}
+		seated = append(seated, t)
+	}
+	return seated, nil
+}
+
+// SetReseatRequired flags the given active tickets of a flight as needing a new seat and
+// clears the flag on all its other tickets.
+func (r *Repository) SetReseatRequired(ctx context.Context, flightID int64, ticketIDs []int64) error {
+	query := `
+		UPDATE tickets
+		SET reseat_required = (id = ANY($2))
+		WHERE flight_id = $1 AND (reseat_required OR id = ANY($2))
+	`
+	if _, err := r.executor(ctx).ExecContext(ctx, query, flightID, pq.Array(ticketIDs)); err != nil {
+		return fmt.Errorf("failed to flag tickets for reseating: %w", err)
+	}
+	return nil
+}
+
+// IsSeatOccupied reports whether an active ticket already has the seat.
+func (r *Repository) IsSeatOccupied(ctx context.Context, flightID int64, seatNo string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM tickets WHERE flight_id = $1 AND seat_no = $2 AND status IN ('ACTIVE', 'PENDING_PAYMENT'))`
+	var occupied bool
+	if err := r.executor(ctx).QueryRowContext(ctx, query, flightID, seatNo).Scan(&occupied); err != nil {
+		return false, fmt.Errorf("failed to check seat: %w", err)
+	}
+	return occupied, nil
+}
+
+// ListActiveHolds returns unexpired holds on a flight.
+func (r *Repository) ListActiveHolds(ctx context.Context, flightID int64) ([]SeatHold, error) {
+	query := `
+		SELECT id, flight_id, seat_no, user_id, expires_at, created_at
+		FROM seat_holds
+		WHERE flight_id = $1 AND expires_at > NOW()
+	`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, flightID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seat holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []SeatHold
+	for rows.Next() {
+		var h SeatHold
+		if err := rows.Scan(&h.ID, &h.FlightID, &h.SeatNo, &h.UserID, &h.ExpiresAt, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan seat hold: %w", err)
+		}
+		holds = append(holds, h)
+	}
+	return holds, nil
+}
+
+// CreateHold inserts a hold, replacing any expired hold on the same seat.
+// Returns ErrSeatHeld if an unexpired hold already exists.
+func (r *Repository) CreateHold(ctx context.Context, h *SeatHold) (int64, error) {
+	exec := r.executor(ctx)
+
+	if _, err := exec.ExecContext(ctx,
+		`DELETE FROM seat_holds WHERE flight_id = $1 AND seat_no = $2 AND expires_at <= NOW()`,
+		h.FlightID, h.SeatNo,
+	); err != nil {
+		return 0, fmt.Errorf("failed to clear expired hold: %w", err)
+	}
+
+	query := `
+		INSERT INTO seat_holds (flight_id, seat_no, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (flight_id, seat_no) DO NOTHING
+		RETURNING id, created_at
+	`
+	var id int64
+	err := exec.QueryRowContext(ctx, query, h.FlightID, h.SeatNo, h.UserID, h.ExpiresAt).Scan(&id, &h.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrSeatHeld
+		}
+		return 0, fmt.Errorf("failed to create seat hold: %w", err)
+	}
+	return id, nil
+}
+
+// GetHoldForUpdate retrieves a hold by ID and locks it for the current transaction.
+func (r *Repository) GetHoldForUpdate(ctx context.Context, id int64) (*SeatHold, error) {
+	query := `
+		SELECT id, flight_id, seat_no, user_id, expires_at, created_at
+		FROM seat_holds
+		WHERE id = $1
+		FOR UPDATE
+	`
+	var h SeatHold
+	err := r.executor(ctx).QueryRowContext(ctx, query, id).Scan(&h.ID, &h.FlightID, &h.SeatNo, &h.UserID, &h.ExpiresAt, &h.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get seat hold: %w", err)
+	}
+	return &h, nil
+}
+
+// GetSeatHoldForUpdate retrieves the hold on a specific seat and locks it.
+func (r *Repository) GetSeatHoldForUpdate(ctx context.Context, flightID int64, seatNo string) (*SeatHold, error) {
+	query := `
+		SELECT id, flight_id, seat_no, user_id, expires_at, created_at
+		FROM seat_holds
+		WHERE flight_id = $1 AND seat_no = $2
+		FOR UPDATE
+	`
+	var h SeatHold
+	err := r.executor(ctx).QueryRowContext(ctx, query, flightID, seatNo).Scan(&h.ID, &h.FlightID, &h.SeatNo, &h.UserID, &h.ExpiresAt, &h.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get seat hold: %w", err)
+	}
+	return &h, nil
+}
+
+// DeleteHold removes a hold.
+func (r *Repository) DeleteHold(ctx context.Context, id int64) error {
+	if _, err := r.executor(ctx).ExecContext(ctx, `DELETE FROM seat_holds WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete seat hold: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package seating
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes sets up the seat map, seat hold and cabin layout routes.
+func RegisterRoutes(r *gin.RouterGroup, h *Handler, authMiddleware gin.HandlerFunc) {
+	seatGroup := r.Group("/flights/:id/seats")
+	{
+		seatGroup.GET("", h.GetSeatMap)
+
+		// Protected routes
+		seatGroup.POST("/holds", authMiddleware, h.HoldSeat)
+		seatGroup.DELETE("/holds/:holdId", authMiddleware, h.ReleaseHold)
+	}
+
+	layoutGroup := r.Group("/cabin-layouts")
+	layoutGroup.Use(authMiddleware)
+	{
+		layoutGroup.POST("", h.CreateLayout)
+		layoutGroup.GET("", h.ListLayouts)
+	}
+}
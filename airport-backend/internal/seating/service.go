@@ -0,0 +1,360 @@
+package seating
+
+import (
+	"airport-system/platform/database"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Service handles seat map and seat hold business logic.
+type Service struct {
+	repo      *Repository
+	txManager database.TxManager
+	log       *slog.Logger
+}
+
+// NewService creates a new seating service.
+func NewService(repo *Repository, txManager database.TxManager, log *slog.Logger) *Service {
+	return &Service{
+		repo:      repo,
+		txManager: txManager,
+		log:       log,
+	}
+}
+
+// CreateLayout validates and stores a new cabin layout.
+func (s *Service) CreateLayout(ctx context.Context, req CreateLayoutRequest) (*CabinLayout, error) {
+	letters := strings.ToUpper(strings.TrimSpace(req.SeatLetters))
+	seen := make(map[rune]bool)
+	for _, ch := range letters {
+		if ch < 'A' || ch > 'Z' {
+			return nil, fmt.Errorf("%w: invalid seat letter %q", ErrInvalidLayout, ch)
+		}
+		if seen[ch] {
+			return nil, fmt.Errorf("%w: duplicate seat letter %q", ErrInvalidLayout, ch)
+		}
+		seen[ch] = true
+	}
+
+	layout := &CabinLayout{
+		Name:         strings.TrimSpace(req.Name),
+		Rows:         req.Rows,
+		SeatLetters:  letters,
+		BlockedSeats: []string{},
+		ExitRows:     []int64{},
+		FirstRows:    req.FirstRows,
+		BusinessRows: req.BusinessRows,
+	}
+	if layout.FirstRows+layout.BusinessRows > layout.Rows {
+		return nil, fmt.Errorf("%w: first and business class rows exceed the layout's %d rows", ErrInvalidLayout, layout.Rows)
+	}
+
+	for _, seatNo := range req.BlockedSeats {
+		seatNo = NormalizeSeatNo(seatNo)
+		if !layout.inGrid(seatNo) {
+			return nil, fmt.Errorf("%w: blocked seat %s is outside the layout", ErrInvalidLayout, seatNo)
+		}
+		layout.BlockedSeats = append(layout.BlockedSeats, seatNo)
+	}
+	for _, row := range req.ExitRows {
+		if row < 1 || int(row) > layout.Rows {
+			return nil, fmt.Errorf("%w: exit row %d is outside the layout", ErrInvalidLayout, row)
+		}
+		layout.ExitRows = append(layout.ExitRows, row)
+	}
+	if layout.SeatCount() == 0 {
+		return nil, fmt.Errorf("%w: layout has no sellable seats", ErrInvalidLayout)
+	}
+
+	id, err := s.repo.CreateLayout(ctx, layout)
+	if err != nil {
+		return nil, err
+	}
+	layout.ID = id
+	return layout, nil
+}
+
+// ListLayouts returns all cabin layouts.
+func (s *Service) ListLayouts(ctx context.Context) ([]CabinLayout, error) {
+	return s.repo.ListLayouts(ctx)
+}
+
+// GetLayout retrieves a cabin layout by ID.
+func (s *Service) GetLayout(ctx context.Context, id int64) (*CabinLayout, error) {
+	layout, err := s.repo.GetLayout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if layout == nil {
+		return nil, ErrLayoutNotFound
+	}
+	return layout, nil
+}
+
+// Displaced returns the seated tickets of a flight whose seat would not exist, or would be in
+// another cabin, under the given layout.
+func (s *Service) Displaced(ctx context.Context, flightID int64, layout *CabinLayout) ([]SeatedTicket, error) {
+	seated, err := s.repo.ListSeatedTickets(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	displaced := []SeatedTicket{}
+	for _, t := range seated {
+		if !layout.HasSeatIn(t.SeatNo, t.Cabin) {
+			displaced = append(displaced, t)
+		}
+	}
+	return displaced, nil
+}
+
+// FlagReseat marks the given tickets of a flight as needing a new seat and clears the flag on
+// its other tickets. It must run inside the caller's transaction.
+func (s *Service) FlagReseat(ctx context.Context, flightID int64, ticketIDs []int64) error {
+	return s.repo.SetReseatRequired(ctx, flightID, ticketIDs)
+}
+
+// GetSeatMap builds the seat availability map for a flight.
+func (s *Service) GetSeatMap(ctx context.Context, flightID int64) (*SeatMap, error) {
+	layout, err := s.FlightLayout(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+
+	occupied, err := s.repo.ListOccupiedSeats(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	holds, err := s.repo.ListActiveHolds(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]string, len(occupied)+len(holds))
+	for _, h := range holds {
+		taken[h.SeatNo] = SeatHeld
+	}
+	for _, seatNo := range occupied {
+		taken[seatNo] = SeatOccupied
+	}
+
+	seatMap := &SeatMap{FlightID: flightID, Layout: layout, Seats: []Seat{}}
+	for row := 1; row <= layout.Rows; row++ {
+		for _, letter := range layout.SeatLetters {
+			seatNo := fmt.Sprintf("%d%c", row, letter)
+			seat := Seat{
+				SeatNo:  seatNo,
+				Row:     row,
+				Letter:  string(letter),
+				ExitRow: layout.IsExitRow(row),
+				Cabin:   layout.CabinOf(row),
+				Status:  SeatAvailable,
+			}
+			if layout.isBlocked(seatNo) {
+				seat.Status = SeatBlocked
+			} else if status, ok := taken[seatNo]; ok {
+				seat.Status = status
+			} else {
+				seatMap.Available++
+			}
+			seatMap.Seats = append(seatMap.Seats, seat)
+		}
+	}
+	return seatMap, nil
+}
+
+// HoldSeat reserves a seat for the user for a limited number of minutes.
+func (s *Service) HoldSeat(ctx context.Context, userID, flightID int64, req HoldSeatRequest) (*SeatHold, error) {
+	minutes := req.Minutes
+	if minutes == 0 {
+		minutes = DefaultHoldMinutes
+	}
+	if minutes < 1 || minutes > MaxHoldMinutes {
+		return nil, fmt.Errorf("%w: hold minutes must be between 1 and %d", ErrInvalidSeat, MaxHoldMinutes)
+	}
+
+	seatNo := NormalizeSeatNo(req.SeatNo)
+	layout, err := s.FlightLayout(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if !layout.HasSeat(seatNo) {
+		return nil, fmt.Errorf("%w: seat %s does not exist on this flight", ErrInvalidSeat, seatNo)
+	}
+
+	hold := &SeatHold{
+		FlightID:  flightID,
+		SeatNo:    seatNo,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Duration(minutes) * time.Minute),
+	}
+
+	err = s.txManager.Run(ctx, func(ctx context.Context) error {
+		occupied, err := s.repo.IsSeatOccupied(ctx, flightID, seatNo)
+		if err != nil {
+			return err
+		}
+		if occupied {
+			return ErrSeatTaken
+		}
+
+		id, err := s.repo.CreateHold(ctx, hold)
+		if err != nil {
+			return err
+		}
+		hold.ID = id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Seat held", "flight_id", flightID, "seat_no", seatNo, "user_id", userID, "expires_at", hold.ExpiresAt)
+	return hold, nil
+}
+
+// ReleaseHold removes a hold owned by the user.
+func (s *Service) ReleaseHold(ctx context.Context, userID, flightID, holdID int64) error {
+	return s.txManager.Run(ctx, func(ctx context.Context) error {
+		hold, err := s.repo.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		if hold == nil || hold.FlightID != flightID {
+			return ErrHoldNotFound
+		}
+		if hold.UserID != userID {
+			return ErrHoldForbidden
+		}
+		return s.repo.DeleteHold(ctx, holdID)
+	})
+}
+
+// HoldFor holds a seat for a user until expiresAt on the system's behalf, e.g. for a waitlist
+// offer, bypassing the limits on holds users request themselves. It must run inside the
+// caller's transaction.
+func (s *Service) HoldFor(ctx context.Context, userID, flightID int64, seatNo string, expiresAt time.Time) (*SeatHold, error) {
+	hold := &SeatHold{
+		FlightID:  flightID,
+		SeatNo:    NormalizeSeatNo(seatNo),
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+	id, err := s.repo.CreateHold(ctx, hold)
+	if err != nil {
+		return nil, err
+	}
+	hold.ID = id
+	return hold, nil
+}
+
+// DropHold removes the user's hold on a seat, if any. It must run inside the caller's transaction.
+func (s *Service) DropHold(ctx context.Context, userID, flightID int64, seatNo string) error {
+	hold, err := s.repo.GetSeatHoldForUpdate(ctx, flightID, NormalizeSeatNo(seatNo))
+	if err != nil {
+		return err
+	}
+	if hold == nil || hold.UserID != userID {
+		return nil
+	}
+	return s.repo.DeleteHold(ctx, hold.ID)
+}
+
+// ClaimSeat resolves the seat a booking in the given cabin should receive and consumes the
+// user's hold on it. When neither a hold nor a seat is requested, the first available seat of
+// the cabin is assigned. It must run inside the booking transaction; the unique seat index on
+// tickets is the final guard against two tickets sharing a seat.
+func (s *Service) ClaimSeat(ctx context.Context, userID, flightID int64, holdID *int64, seatNo, cabin string) (string, error) {
+	layout, err := s.FlightLayout(ctx, flightID)
+	if err != nil {
+		return "", err
+	}
+
+	if holdID != nil {
+		hold, err := s.repo.GetHoldForUpdate(ctx, *holdID)
+		if err != nil {
+			return "", err
+		}
+		if hold == nil || hold.FlightID != flightID || hold.UserID != userID {
+			return "", ErrHoldNotFound
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return "", ErrHoldExpired
+		}
+		if seatNo != "" && NormalizeSeatNo(seatNo) != hold.SeatNo {
+			return "", fmt.Errorf("%w: seat_no does not match the held seat", ErrInvalidSeat)
+		}
+		if !layout.HasSeatIn(hold.SeatNo, cabin) {
+			return "", fmt.Errorf("%w: held seat %s is not in cabin %s", ErrInvalidSeat, hold.SeatNo, cabin)
+		}
+		if err := s.repo.DeleteHold(ctx, hold.ID); err != nil {
+			return "", err
+		}
+		return hold.SeatNo, nil
+	}
+
+	if seatNo == "" {
+		return s.firstAvailableSeat(ctx, flightID, cabin)
+	}
+
+	seatNo = NormalizeSeatNo(seatNo)
+	if !layout.HasSeat(seatNo) {
+		return "", fmt.Errorf("%w: seat %s does not exist on this flight", ErrInvalidSeat, seatNo)
+	}
+	if !layout.HasSeatIn(seatNo, cabin) {
+		return "", fmt.Errorf("%w: seat %s is not in cabin %s", ErrInvalidSeat, seatNo, cabin)
+	}
+
+	hold, err := s.repo.GetSeatHoldForUpdate(ctx, flightID, seatNo)
+	if err != nil {
+		return "", err
+	}
+	if hold != nil {
+		if hold.UserID != userID && hold.ExpiresAt.After(time.Now()) {
+			return "", ErrSeatHeld
+		}
+		if err := s.repo.DeleteHold(ctx, hold.ID); err != nil {
+			return "", err
+		}
+	}
+	return seatNo, nil
+}
+
+// firstAvailableSeat returns the first seat of a cabin in row order that is neither held nor occupied.
+func (s *Service) firstAvailableSeat(ctx context.Context, flightID int64, cabin string) (string, error) {
+	seatMap, err := s.GetSeatMap(ctx, flightID)
+	if err != nil {
+		return "", err
+	}
+	for _, seat := range seatMap.Seats {
+		if seat.Status == SeatAvailable && seat.Cabin == cabin {
+			return seat.SeatNo, nil
+		}
+	}
+	return "", ErrNoSeatsAvailable
+}
+
+// FlightLayout returns the flight's cabin layout, or a default grid sized to its capacity.
+func (s *Service) FlightLayout(ctx context.Context, flightID int64) (*CabinLayout, error) {
+	fs, err := s.repo.GetFlightSeating(ctx, flightID)
+	if err != nil {
+		return nil, err
+	}
+	if fs == nil {
+		return nil, ErrFlightNotFound
+	}
+	if fs.CabinLayoutID == nil {
+		return DefaultLayout(fs.TotalSeats), nil
+	}
+
+	layout, err := s.repo.GetLayout(ctx, *fs.CabinLayoutID)
+	if err != nil {
+		return nil, err
+	}
+	if layout == nil {
+		return nil, ErrLayoutNotFound
+	}
+	return layout, nil
+}
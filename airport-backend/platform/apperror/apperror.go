@@ -0,0 +1,133 @@
+// Package apperror defines typed domain errors that carry an HTTP-independent kind and a
+// machine-readable code. Services declare them as sentinel values and may wrap them with
+// fmt.Errorf("%w: ...") to add detail; middleware.ErrorHandler renders them for clients.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind classifies an error for clients.
+type Kind int
+
+const (
+	// KindInternal is any error that is not a typed domain error. Its message is never shown.
+	KindInternal Kind = iota
+	// KindValidation means the request is malformed or breaks a business rule.
+	KindValidation
+	// KindUnauthorized means the caller is not authenticated.
+	KindUnauthorized
+	// KindForbidden means the caller may not perform the action.
+	KindForbidden
+	// KindNotFound means the requested resource does not exist.
+	KindNotFound
+	// KindConflict means the request conflicts with the current state of a resource.
+	KindConflict
+	// KindUnavailable means a dependency is temporarily unavailable and the request may be retried.
+	KindUnavailable
+	// KindPaymentRequired means a payment was declined or is still owed.
+	KindPaymentRequired
+)
+
+// Status returns the HTTP status code for the kind.
+func (k Kind) Status() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	case KindPaymentRequired:
+		return http.StatusPaymentRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is a typed domain error.
+type Error struct {
+	Kind    Kind
+	Code    string // Machine-readable, e.g. "flight_not_found"
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New creates a typed error.
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Validation creates a validation error.
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+// Unauthorized creates an authentication error.
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// Forbidden creates an authorization error.
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+// NotFound creates a not-found error.
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+// Conflict creates a state conflict error.
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// Unavailable creates a temporary unavailability error.
+func Unavailable(code, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+// PaymentRequired creates a payment error.
+func PaymentRequired(code, message string) *Error {
+	return New(KindPaymentRequired, code, message)
+}
+
+// As returns the typed error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// Common errors shared by several modules.
+var (
+	// ErrInvalidRequest is used for request bodies and query strings that fail to bind.
+	ErrInvalidRequest = Validation("invalid_request", "invalid request")
+	// ErrInvalidID is used for malformed path IDs.
+	ErrInvalidID = Validation("invalid_id", "invalid id")
+	// ErrForbidden is used when the caller's role does not allow the action.
+	ErrForbidden = Forbidden("forbidden", "forbidden")
+	// ErrUnauthenticated is used when no valid session accompanies the request.
+	ErrUnauthenticated = Unauthorized("unauthenticated", "authentication required")
+	// ErrUnavailable is used when the database or another dependency cannot be reached.
+	ErrUnavailable = Unavailable("service_unavailable", "service temporarily unavailable, please retry")
+)
+
+// BadRequest wraps a request binding or parsing error as a validation error.
+func BadRequest(err error) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+}
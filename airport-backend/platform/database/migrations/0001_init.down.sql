@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS baggage;
+DROP TABLE IF EXISTS tickets;
+DROP TABLE IF EXISTS flights;
+DROP TABLE IF EXISTS gates;
+DROP TABLE IF EXISTS passengers;
+DROP TABLE IF EXISTS users;
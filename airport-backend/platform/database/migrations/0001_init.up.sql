@@ -0,0 +1,69 @@
+CREATE TABLE IF NOT EXISTS users (
+    id            BIGSERIAL PRIMARY KEY,
+    full_name     VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    role          VARCHAR(32)  NOT NULL DEFAULT 'PASSENGER',
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS passengers (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT      NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    passport_no VARCHAR(32) NOT NULL,
+    phone       VARCHAR(32) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS gates (
+    id          BIGSERIAL PRIMARY KEY,
+    terminal_id BIGINT      NOT NULL,
+    code        VARCHAR(16) NOT NULL,
+    status      VARCHAR(32) NOT NULL DEFAULT 'OPEN',
+    UNIQUE (terminal_id, code)
+);
+
+CREATE TABLE IF NOT EXISTS flights (
+    id             BIGSERIAL PRIMARY KEY,
+    flight_no      VARCHAR(16)    NOT NULL,
+    origin         CHAR(3)        NOT NULL,
+    destination    CHAR(3)        NOT NULL,
+    gate_id        BIGINT         REFERENCES gates(id) ON DELETE SET NULL,
+    departure_time TIMESTAMPTZ    NOT NULL,
+    arrival_time   TIMESTAMPTZ    NOT NULL,
+    status         VARCHAR(32)    NOT NULL DEFAULT 'SCHEDULED',
+    version        INT            NOT NULL DEFAULT 1,
+    total_seats    INT            NOT NULL DEFAULT 150,
+    base_price     NUMERIC(12, 2) NOT NULL DEFAULT 32000.00,
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    CHECK (arrival_time > departure_time),
+    CHECK (origin <> destination)
+);
+
+CREATE INDEX IF NOT EXISTS idx_flights_route_departure ON flights (origin, destination, departure_time);
+CREATE INDEX IF NOT EXISTS idx_flights_departure ON flights (departure_time);
+
+CREATE TABLE IF NOT EXISTS tickets (
+    id           BIGSERIAL PRIMARY KEY,
+    flight_id    BIGINT         NOT NULL REFERENCES flights(id),
+    passenger_id BIGINT         NOT NULL REFERENCES passengers(id),
+    seat_no      VARCHAR(8),
+    price        NUMERIC(12, 2) NOT NULL,
+    status       VARCHAR(32)    NOT NULL DEFAULT 'ACTIVE',
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tickets_flight_status ON tickets (flight_id, status);
+CREATE INDEX IF NOT EXISTS idx_tickets_passenger ON tickets (passenger_id);
+
+CREATE TABLE IF NOT EXISTS baggage (
+    id         BIGSERIAL PRIMARY KEY,
+    ticket_id  BIGINT      NOT NULL REFERENCES tickets(id),
+    tag_code   VARCHAR(32) NOT NULL UNIQUE,
+    status     VARCHAR(32) NOT NULL DEFAULT 'RECEIVED',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_baggage_ticket ON baggage (ticket_id);
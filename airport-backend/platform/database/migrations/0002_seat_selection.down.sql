@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS uq_tickets_flight_seat;
+DROP TABLE IF EXISTS seat_holds;
+ALTER TABLE flights DROP COLUMN IF EXISTS cabin_layout_id;
+DROP TABLE IF EXISTS cabin_layouts;
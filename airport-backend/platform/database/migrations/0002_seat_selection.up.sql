@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS cabin_layouts (
+    id            BIGSERIAL PRIMARY KEY,
+    name          VARCHAR(64) NOT NULL UNIQUE,
+    row_count     INT         NOT NULL CHECK (row_count > 0),
+    seat_letters  VARCHAR(12) NOT NULL,
+    blocked_seats TEXT[]      NOT NULL DEFAULT '{}',
+    exit_rows     INT[]       NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS cabin_layout_id BIGINT REFERENCES cabin_layouts(id);
+
+CREATE TABLE IF NOT EXISTS seat_holds (
+    id         BIGSERIAL PRIMARY KEY,
+    flight_id  BIGINT      NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    seat_no    VARCHAR(8)  NOT NULL,
+    user_id    BIGINT      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (flight_id, seat_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seat_holds_user ON seat_holds (user_id);
+
+-- Two active tickets can never share a seat on the same flight.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tickets_flight_seat
+    ON tickets (flight_id, seat_no)
+    WHERE status = 'ACTIVE' AND seat_no IS NOT NULL;
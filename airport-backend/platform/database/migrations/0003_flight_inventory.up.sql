@@ -0,0 +1,16 @@
+-- One counter row per flight. Bookings increment it with a conditional UPDATE, which takes a
+-- row lock and re-checks the capacity, so concurrent bookings can never oversell a flight.
+CREATE TABLE IF NOT EXISTS flight_inventory (
+    flight_id  BIGINT      PRIMARY KEY REFERENCES flights(id) ON DELETE CASCADE,
+    capacity   INT         NOT NULL CHECK (capacity >= 0),
+    sold       INT         NOT NULL DEFAULT 0 CHECK (sold >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO flight_inventory (flight_id, capacity, sold, updated_at)
+SELECT f.id,
+       f.total_seats,
+       (SELECT COUNT(*) FROM tickets t WHERE t.flight_id = f.id AND t.status = 'ACTIVE'),
+       NOW()
+FROM flights f
+ON CONFLICT (flight_id) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS flight_status_history;
+ALTER TABLE flights DROP COLUMN IF EXISTS estimated_arrival_time;
+ALTER TABLE flights DROP COLUMN IF EXISTS estimated_departure_time;
@@ -0,0 +1,15 @@
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS estimated_departure_time TIMESTAMPTZ;
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS estimated_arrival_time TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS flight_status_history (
+    id          BIGSERIAL PRIMARY KEY,
+    flight_id   BIGINT      NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    from_status VARCHAR(32) NOT NULL,
+    to_status   VARCHAR(32) NOT NULL,
+    version     INT         NOT NULL,
+    changed_by  BIGINT      REFERENCES users(id) ON DELETE SET NULL,
+    reason      TEXT        NOT NULL DEFAULT '',
+    changed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_flight_status_history_flight ON flight_status_history (flight_id, changed_at);
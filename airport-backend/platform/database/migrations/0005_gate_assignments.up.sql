@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS gate_assignments (
+    id             BIGSERIAL PRIMARY KEY,
+    gate_id        BIGINT      NOT NULL REFERENCES gates(id),
+    flight_id      BIGINT      NOT NULL UNIQUE REFERENCES flights(id) ON DELETE CASCADE,
+    occupied_from  TIMESTAMPTZ NOT NULL,
+    occupied_until TIMESTAMPTZ NOT NULL,
+    assigned_by    BIGINT      REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (occupied_until > occupied_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gate_assignments_gate_window ON gate_assignments (gate_id, occupied_from, occupied_until);
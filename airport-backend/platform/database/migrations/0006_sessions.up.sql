@@ -0,0 +1,18 @@
+-- A session is one login. Its refresh token rotates on every use; only the SHA-256 hash of the
+-- current and previous token is stored so a leaked table cannot be replayed.
+CREATE TABLE IF NOT EXISTS sessions (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_hash  CHAR(64)    NOT NULL UNIQUE,
+    previous_token_hash CHAR(64),
+    user_agent          TEXT        NOT NULL DEFAULT '',
+    ip_address          VARCHAR(64) NOT NULL DEFAULT '',
+    expires_at          TIMESTAMPTZ NOT NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at          TIMESTAMPTZ,
+    revoked_at          TIMESTAMPTZ,
+    revoked_reason      VARCHAR(64)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_previous_token ON sessions (previous_token_hash);
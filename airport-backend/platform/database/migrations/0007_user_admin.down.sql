@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS user_invitations;
+DROP INDEX IF EXISTS idx_users_full_name_lower;
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
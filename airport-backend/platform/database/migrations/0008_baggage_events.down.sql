@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS baggage_events;
+ALTER TABLE baggage DROP CONSTRAINT IF EXISTS chk_baggage_status;
@@ -0,0 +1,25 @@
+-- Map statuses written before the state machine existed onto the new lifecycle
+UPDATE baggage SET status = 'RECEIVED' WHERE status = 'CHECKED_IN';
+UPDATE baggage SET status = 'CLAIMED' WHERE status = 'DELIVERED';
+UPDATE baggage SET status = 'MISHANDLED'
+WHERE status NOT IN ('RECEIVED', 'SCREENED', 'SORTED', 'LOADED', 'IN_TRANSIT', 'ARRIVED', 'ON_CAROUSEL', 'CLAIMED', 'MISHANDLED');
+
+ALTER TABLE baggage ADD CONSTRAINT chk_baggage_status
+    CHECK (status IN ('RECEIVED', 'SCREENED', 'SORTED', 'LOADED', 'IN_TRANSIT', 'ARRIVED', 'ON_CAROUSEL', 'CLAIMED', 'MISHANDLED'));
+
+CREATE TABLE IF NOT EXISTS baggage_events (
+    id          BIGSERIAL PRIMARY KEY,
+    baggage_id  BIGINT      NOT NULL REFERENCES baggage(id) ON DELETE CASCADE,
+    from_status VARCHAR(32),
+    to_status   VARCHAR(32) NOT NULL,
+    location    VARCHAR(64) NOT NULL DEFAULT '',
+    actor_id    BIGINT      REFERENCES users(id) ON DELETE SET NULL,
+    note        TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_baggage_events_baggage ON baggage_events (baggage_id, created_at);
+
+-- Existing bags start their history at their current status
+INSERT INTO baggage_events (baggage_id, from_status, to_status, note, created_at)
+SELECT id, NULL, status, 'backfilled', updated_at FROM baggage;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS baggage_allowances;
+
+ALTER TABLE baggage DROP COLUMN IF EXISTS fee_reason;
+ALTER TABLE baggage DROP COLUMN IF EXISTS excess_fee;
+ALTER TABLE baggage DROP COLUMN IF EXISTS height_cm;
+ALTER TABLE baggage DROP COLUMN IF EXISTS width_cm;
+ALTER TABLE baggage DROP COLUMN IF EXISTS length_cm;
+ALTER TABLE baggage DROP COLUMN IF EXISTS weight_kg;
+
+ALTER TABLE tickets DROP COLUMN IF EXISTS baggage_fees;
+ALTER TABLE tickets DROP COLUMN IF EXISTS fare_class;
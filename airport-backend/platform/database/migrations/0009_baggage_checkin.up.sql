@@ -0,0 +1,28 @@
+-- Fare class drives the baggage allowance; every existing ticket is economy
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS fare_class CHAR(1) NOT NULL DEFAULT 'Y';
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS baggage_fees NUMERIC(12, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE baggage ADD COLUMN IF NOT EXISTS weight_kg NUMERIC(5, 1) NOT NULL DEFAULT 0;
+ALTER TABLE baggage ADD COLUMN IF NOT EXISTS length_cm INT NOT NULL DEFAULT 0;
+ALTER TABLE baggage ADD COLUMN IF NOT EXISTS width_cm INT NOT NULL DEFAULT 0;
+ALTER TABLE baggage ADD COLUMN IF NOT EXISTS height_cm INT NOT NULL DEFAULT 0;
+ALTER TABLE baggage ADD COLUMN IF NOT EXISTS excess_fee NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE baggage ADD COLUMN IF NOT EXISTS fee_reason TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS baggage_allowances (
+    fare_class     CHAR(1)        PRIMARY KEY,
+    free_bags      INT            NOT NULL CHECK (free_bags >= 0),
+    max_weight_kg  NUMERIC(5, 1)  NOT NULL CHECK (max_weight_kg > 0),
+    max_linear_cm  INT            NOT NULL CHECK (max_linear_cm > 0),
+    excess_bag_fee NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    overweight_fee NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    oversize_fee   NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    updated_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO baggage_allowances (fare_class, free_bags, max_weight_kg, max_linear_cm, excess_bag_fee, overweight_fee, oversize_fee)
+VALUES
+    ('Y', 1, 23, 158, 60, 75, 100),
+    ('C', 2, 32, 158, 60, 75, 100),
+    ('F', 3, 32, 158, 60, 75, 100)
+ON CONFLICT (fare_class) DO NOTHING;
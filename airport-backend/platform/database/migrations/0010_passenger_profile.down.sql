@@ -0,0 +1,3 @@
+ALTER TABLE passengers DROP COLUMN IF EXISTS passport_expiry;
+ALTER TABLE passengers DROP COLUMN IF EXISTS date_of_birth;
+ALTER TABLE passengers DROP COLUMN IF EXISTS nationality;
@@ -0,0 +1,3 @@
+ALTER TABLE passengers ADD COLUMN IF NOT EXISTS nationality CHAR(2);
+ALTER TABLE passengers ADD COLUMN IF NOT EXISTS date_of_birth DATE;
+ALTER TABLE passengers ADD COLUMN IF NOT EXISTS passport_expiry DATE;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_tickets_booking;
+ALTER TABLE tickets DROP COLUMN IF EXISTS booking_id;
+DROP TABLE IF EXISTS bookings;
+
+-- Companion profiles cannot be represented once user_id is unique again.
+-- This fails while companions still hold tickets, rather than deleting bookings.
+DELETE FROM passengers WHERE NOT is_primary;
+ALTER TABLE passengers DROP CONSTRAINT IF EXISTS chk_passengers_companion_name;
+DROP INDEX IF EXISTS idx_passengers_user;
+DROP INDEX IF EXISTS uq_passengers_primary;
+ALTER TABLE passengers ADD CONSTRAINT passengers_user_id_key UNIQUE (user_id);
+ALTER TABLE passengers DROP COLUMN IF EXISTS is_primary;
+ALTER TABLE passengers DROP COLUMN IF EXISTS full_name;
@@ -0,0 +1,23 @@
+-- A user account can hold several travellers: its own profile (is_primary) and companions
+ALTER TABLE passengers ADD COLUMN IF NOT EXISTS full_name VARCHAR(255);
+ALTER TABLE passengers ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE passengers DROP CONSTRAINT IF EXISTS passengers_user_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_passengers_primary ON passengers (user_id) WHERE is_primary;
+CREATE INDEX IF NOT EXISTS idx_passengers_user ON passengers (user_id);
+ALTER TABLE passengers ADD CONSTRAINT chk_passengers_companion_name CHECK (is_primary OR full_name IS NOT NULL);
+
+-- Bookings (PNRs) group tickets under a record locator
+CREATE TABLE IF NOT EXISTS bookings (
+    id             BIGSERIAL PRIMARY KEY,
+    record_locator CHAR(6)     NOT NULL UNIQUE,
+    user_id        BIGINT      NOT NULL REFERENCES users(id),
+    status         VARCHAR(32) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'CANCELLED')),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_user ON bookings (user_id, created_at);
+
+-- Tickets sold before PNRs existed keep a NULL booking_id
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS booking_id BIGINT REFERENCES bookings(id);
+CREATE INDEX IF NOT EXISTS idx_tickets_booking ON tickets (booking_id);
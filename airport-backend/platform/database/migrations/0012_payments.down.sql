@@ -0,0 +1,18 @@
+-- Tickets still awaiting payment cannot be represented without intents; release them.
+UPDATE flight_inventory fi
+SET sold = sold - t.pending, updated_at = NOW()
+FROM (SELECT flight_id, COUNT(*) AS pending FROM tickets WHERE status = 'PENDING_PAYMENT' GROUP BY flight_id) t
+WHERE fi.flight_id = t.flight_id;
+UPDATE tickets SET status = 'CANCELLED' WHERE status = 'PENDING_PAYMENT';
+
+DROP INDEX IF EXISTS uq_tickets_flight_seat;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tickets_flight_seat
+    ON tickets (flight_id, seat_no)
+    WHERE status = 'ACTIVE' AND seat_no IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_tickets_payment_intent;
+ALTER TABLE tickets DROP COLUMN IF EXISTS payment_intent_id;
+
+DROP TABLE IF EXISTS payment_webhook_events;
+DROP TABLE IF EXISTS payment_captures;
+DROP TABLE IF EXISTS payment_intents;
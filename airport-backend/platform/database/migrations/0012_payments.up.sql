@@ -0,0 +1,51 @@
+-- A payment intent collects the price of the tickets issued in one booking step.
+-- Its tickets stay PENDING_PAYMENT (holding seat and capacity) until the intent settles.
+CREATE TABLE IF NOT EXISTS payment_intents (
+    id           BIGSERIAL PRIMARY KEY,
+    booking_id   BIGINT         NOT NULL REFERENCES bookings(id),
+    user_id      BIGINT         NOT NULL REFERENCES users(id),
+    amount       NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+    currency     CHAR(3)        NOT NULL,
+    status       VARCHAR(32)    NOT NULL DEFAULT 'REQUIRES_PAYMENT'
+                 CHECK (status IN ('REQUIRES_PAYMENT', 'PROCESSING', 'SUCCEEDED', 'FAILED', 'EXPIRED', 'CANCELLED')),
+    gateway      VARCHAR(32)    NOT NULL,
+    gateway_ref  VARCHAR(128),
+    card_last4   CHAR(4),
+    failure_code VARCHAR(64),
+    expires_at   TIMESTAMPTZ    NOT NULL,
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    UNIQUE (gateway, gateway_ref)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_intents_booking ON payment_intents (booking_id);
+CREATE INDEX IF NOT EXISTS idx_payment_intents_open ON payment_intents (expires_at)
+    WHERE status IN ('REQUIRES_PAYMENT', 'PROCESSING');
+
+CREATE TABLE IF NOT EXISTS payment_captures (
+    id          BIGSERIAL PRIMARY KEY,
+    intent_id   BIGINT         NOT NULL UNIQUE REFERENCES payment_intents(id),
+    amount      NUMERIC(12, 2) NOT NULL,
+    gateway_ref VARCHAR(128)   NOT NULL,
+    created_at  TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+-- Every webhook delivery is recorded once; redeliveries of the same event are ignored.
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    gateway     VARCHAR(32)  NOT NULL,
+    event_id    VARCHAR(128) NOT NULL,
+    event_type  VARCHAR(64)  NOT NULL,
+    intent_id   BIGINT       REFERENCES payment_intents(id),
+    payload     JSONB        NOT NULL,
+    received_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (gateway, event_id)
+);
+
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS payment_intent_id BIGINT REFERENCES payment_intents(id);
+CREATE INDEX IF NOT EXISTS idx_tickets_payment_intent ON tickets (payment_intent_id);
+
+-- Tickets awaiting payment already own their seat.
+DROP INDEX IF EXISTS uq_tickets_flight_seat;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tickets_flight_seat
+    ON tickets (flight_id, seat_no)
+    WHERE status IN ('ACTIVE', 'PENDING_PAYMENT') AND seat_no IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS payment_refunds;
+ALTER TABLE tickets DROP COLUMN IF EXISTS fare_rule_id;
+DROP TABLE IF EXISTS fare_rules;
@@ -0,0 +1,47 @@
+-- Fare rules are versioned by insertion: a ticket keeps the rule it was sold under, and new
+-- tickets get the newest rule of their fare class.
+CREATE TABLE IF NOT EXISTS fare_rules (
+    id               BIGSERIAL PRIMARY KEY,
+    fare_class       CHAR(1)        NOT NULL,
+    refundable       BOOLEAN        NOT NULL,
+    cancellation_fee NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (cancellation_fee >= 0),
+    change_fee       NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (change_fee >= 0),
+    cutoff_hours     INT            NOT NULL DEFAULT 0 CHECK (cutoff_hours >= 0),
+    no_show_penalty  NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (no_show_penalty >= 0),
+    created_at       TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fare_rules_class ON fare_rules (fare_class, id);
+
+INSERT INTO fare_rules (fare_class, refundable, cancellation_fee, change_fee, cutoff_hours, no_show_penalty)
+SELECT v.* FROM (VALUES
+    ('Y', TRUE, 8000.00, 5000.00, 24, 16000.00),
+    ('C', TRUE, 0.00, 0.00, 3, 8000.00),
+    ('F', TRUE, 0.00, 0.00, 1, 0.00)
+) AS v(fare_class, refundable, cancellation_fee, change_fee, cutoff_hours, no_show_penalty)
+WHERE NOT EXISTS (SELECT 1 FROM fare_rules);
+
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS fare_rule_id BIGINT REFERENCES fare_rules(id);
+UPDATE tickets t
+SET fare_rule_id = (SELECT MAX(r.id) FROM fare_rules r WHERE r.fare_class = t.fare_class)
+WHERE t.fare_rule_id IS NULL;
+
+-- One refund per cancelled ticket. MANUAL refunds have no captured payment to return
+-- (tickets sold before payments existed) and are settled outside the system.
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    id          BIGSERIAL PRIMARY KEY,
+    ticket_id   BIGINT         NOT NULL UNIQUE REFERENCES tickets(id),
+    intent_id   BIGINT         REFERENCES payment_intents(id),
+    amount      NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+    penalty     NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (penalty >= 0),
+    currency    CHAR(3)        NOT NULL,
+    status      VARCHAR(32)    NOT NULL DEFAULT 'PENDING'
+                CHECK (status IN ('PENDING', 'SUCCEEDED', 'FAILED', 'MANUAL')),
+    reason      VARCHAR(64)    NOT NULL,
+    gateway_ref VARCHAR(128),
+    created_at  TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_pending ON payment_refunds (created_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_intent ON payment_refunds (intent_id);
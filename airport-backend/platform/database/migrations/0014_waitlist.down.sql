@@ -0,0 +1,7 @@
+-- Seats held for open offers are released back to inventory.
+UPDATE flight_inventory fi
+SET sold = sold - w.offered, updated_at = NOW()
+FROM (SELECT flight_id, COUNT(*) AS offered FROM waitlist_entries WHERE status = 'OFFERED' GROUP BY flight_id) w
+WHERE fi.flight_id = w.flight_id;
+
+DROP TABLE IF EXISTS waitlist_entries;
@@ -0,0 +1,26 @@
+-- Passengers queue for sold-out flights. A freed seat is offered to the first eligible entry
+-- (highest priority, then earliest), which keeps the seat held until offer_expires_at.
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id               BIGSERIAL PRIMARY KEY,
+    flight_id        BIGINT      NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    user_id          BIGINT      NOT NULL REFERENCES users(id),
+    passenger_id     BIGINT      NOT NULL REFERENCES passengers(id),
+    fare_class       CHAR(1)     NOT NULL DEFAULT 'Y',
+    priority         INT         NOT NULL DEFAULT 0,
+    status           VARCHAR(32) NOT NULL DEFAULT 'WAITING'
+                     CHECK (status IN ('WAITING', 'OFFERED', 'CONFIRMED', 'EXPIRED', 'CANCELLED')),
+    offered_seat_no  VARCHAR(8),
+    offer_expires_at TIMESTAMPTZ,
+    ticket_id        BIGINT      REFERENCES tickets(id),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (status <> 'OFFERED' OR offer_expires_at IS NOT NULL)
+);
+
+-- A passenger queues at most once per flight.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_waitlist_flight_passenger
+    ON waitlist_entries (flight_id, passenger_id)
+    WHERE status IN ('WAITING', 'OFFERED');
+CREATE INDEX IF NOT EXISTS idx_waitlist_queue ON waitlist_entries (flight_id, priority DESC, id) WHERE status = 'WAITING';
+CREATE INDEX IF NOT EXISTS idx_waitlist_offers ON waitlist_entries (offer_expires_at) WHERE status = 'OFFERED';
+CREATE INDEX IF NOT EXISTS idx_waitlist_user ON waitlist_entries (user_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS price_quotes;
+DROP TABLE IF EXISTS pricing_rules;
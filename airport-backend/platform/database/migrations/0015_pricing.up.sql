@@ -0,0 +1,55 @@
+-- A pricing rule multiplies a flight's base price when all of its conditions match the flight.
+-- Conditions left NULL (or an empty days_of_week) match any flight. Only PUBLISHED rules
+-- price tickets; DRAFT rules can be simulated before they are published.
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id                    BIGSERIAL PRIMARY KEY,
+    name                  VARCHAR(100)  NOT NULL,
+    origin                CHAR(3),
+    destination           CHAR(3),
+    min_load_factor       NUMERIC(5, 2) CHECK (min_load_factor BETWEEN 0 AND 100),
+    max_load_factor       NUMERIC(5, 2) CHECK (max_load_factor BETWEEN 0 AND 100),
+    min_days_to_departure INT           CHECK (min_days_to_departure >= 0),
+    max_days_to_departure INT           CHECK (max_days_to_departure >= 0),
+    days_of_week          SMALLINT[]    NOT NULL DEFAULT '{}', -- 0 = Sunday
+    multiplier            NUMERIC(6, 3) NOT NULL CHECK (multiplier > 0),
+    status                VARCHAR(16)   NOT NULL DEFAULT 'DRAFT'
+                          CHECK (status IN ('DRAFT', 'PUBLISHED', 'RETIRED')),
+    created_at            TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    CHECK (min_load_factor IS NULL OR max_load_factor IS NULL OR min_load_factor <= max_load_factor),
+    CHECK (min_days_to_departure IS NULL OR max_days_to_departure IS NULL OR min_days_to_departure <= max_days_to_departure)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pricing_rules_status ON pricing_rules (status);
+
+-- Starter rules are drafts so prices do not change until an ADMIN publishes them.
+INSERT INTO pricing_rules (name, min_load_factor, max_load_factor, min_days_to_departure, max_days_to_departure, days_of_week, multiplier)
+SELECT v.* FROM (VALUES
+    ('High demand', 80.00, NULL, NULL, NULL, '{}'::SMALLINT[], 1.250),
+    ('Low demand', NULL, 30.00, 14, NULL, '{}'::SMALLINT[], 0.900),
+    ('Last minute', NULL, NULL, NULL, 3, '{}'::SMALLINT[], 1.300),
+    ('Early bird', NULL, NULL, 60, NULL, '{}'::SMALLINT[], 0.850),
+    ('Weekend peak', NULL, NULL, NULL, NULL, '{0,5}'::SMALLINT[], 1.100)
+) AS v(name, min_load_factor, max_load_factor, min_days_to_departure, max_days_to_departure, days_of_week, multiplier)
+WHERE NOT EXISTS (SELECT 1 FROM pricing_rules);
+
+-- A price quote locks the price of a flight for one user until expires_at. Booking with the
+-- quote uses it up.
+CREATE TABLE IF NOT EXISTS price_quotes (
+    id                BIGSERIAL PRIMARY KEY,
+    flight_id         BIGINT         NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    user_id           BIGINT         NOT NULL REFERENCES users(id),
+    base_price        NUMERIC(12, 2) NOT NULL,
+    multiplier        NUMERIC(8, 4)  NOT NULL,
+    amount            NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+    currency          CHAR(3)        NOT NULL,
+    load_factor       NUMERIC(5, 2)  NOT NULL,
+    days_to_departure INT            NOT NULL,
+    rule_ids          BIGINT[]       NOT NULL DEFAULT '{}',
+    expires_at        TIMESTAMPTZ    NOT NULL,
+    used_at           TIMESTAMPTZ,
+    booking_id        BIGINT         REFERENCES bookings(id),
+    created_at        TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_quotes_user ON price_quotes (user_id, created_at);
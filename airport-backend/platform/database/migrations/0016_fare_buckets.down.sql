@@ -0,0 +1,6 @@
+ALTER TABLE price_quotes DROP COLUMN IF EXISTS booking_class;
+ALTER TABLE waitlist_entries DROP COLUMN IF EXISTS offered_booking_class;
+ALTER TABLE waitlist_entries DROP COLUMN IF EXISTS booking_class;
+ALTER TABLE tickets DROP COLUMN IF EXISTS booking_class;
+DROP TABLE IF EXISTS fare_buckets;
+DROP TABLE IF EXISTS booking_classes;
@@ -0,0 +1,65 @@
+-- Booking classes sold within each cabin (F first, C business, Y economy). Rank 1 is the
+-- highest class of its cabin; higher classes may sell seats allocated to lower ones (nesting).
+CREATE TABLE IF NOT EXISTS booking_classes (
+    code            CHAR(1)       PRIMARY KEY,
+    cabin           CHAR(1)       NOT NULL CHECK (cabin IN ('F', 'C', 'Y')),
+    rank            SMALLINT      NOT NULL CHECK (rank >= 1),
+    name            VARCHAR(64)   NOT NULL,
+    fare_multiplier NUMERIC(6, 3) NOT NULL CHECK (fare_multiplier > 0), -- Of the flight's base price
+    default_share   NUMERIC(5, 2) NOT NULL CHECK (default_share BETWEEN 0 AND 100), -- Percent of the cabin's seats
+    UNIQUE (cabin, rank)
+);
+
+INSERT INTO booking_classes (code, cabin, rank, name, fare_multiplier, default_share) VALUES
+    ('F', 'F', 1, 'First flexible', 5.000, 50.00),
+    ('A', 'F', 2, 'First saver', 4.000, 50.00),
+    ('J', 'C', 1, 'Business flexible', 3.500, 30.00),
+    ('C', 'C', 2, 'Business standard', 3.000, 40.00),
+    ('D', 'C', 3, 'Business saver', 2.500, 30.00),
+    ('Y', 'Y', 1, 'Economy flexible', 1.600, 15.00),
+    ('B', 'Y', 2, 'Economy standard', 1.300, 20.00),
+    ('M', 'Y', 3, 'Economy classic', 1.150, 30.00),
+    ('Q', 'Y', 4, 'Economy saver', 1.000, 35.00)
+ON CONFLICT (code) DO NOTHING;
+
+-- Per-flight inventory of each booking class. A cabin's capacity is the sum of its classes'
+-- allocations; the flight-wide counter in flight_inventory still caps the whole flight.
+CREATE TABLE IF NOT EXISTS fare_buckets (
+    flight_id     BIGINT         NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    booking_class CHAR(1)        NOT NULL REFERENCES booking_classes(code),
+    allocation    INT            NOT NULL CHECK (allocation >= 0),
+    sold          INT            NOT NULL DEFAULT 0 CHECK (sold >= 0),
+    fare          NUMERIC(12, 2) NOT NULL CHECK (fare >= 0),
+    closed        BOOLEAN        NOT NULL DEFAULT FALSE, -- Closed classes sell nothing; their seats stay open to higher classes
+    updated_at    TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (flight_id, booking_class)
+);
+
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS booking_class CHAR(1) REFERENCES booking_classes(code);
+UPDATE tickets SET booking_class = fare_class WHERE booking_class IS NULL;
+ALTER TABLE tickets ALTER COLUMN booking_class SET DEFAULT 'Y';
+ALTER TABLE tickets ALTER COLUMN booking_class SET NOT NULL;
+
+-- Existing flights sell economy only, split by the default shares; rounding leftovers go to
+-- the top class. Tickets already sold count against their booking class.
+INSERT INTO fare_buckets (flight_id, booking_class, allocation, sold, fare)
+SELECT f.id,
+       bc.code,
+       FLOOR(bc.default_share * fi.capacity / 100)
+           + CASE WHEN bc.rank = 1 THEN fi.capacity - (
+                 SELECT SUM(FLOOR(o.default_share * fi.capacity / 100))::INT FROM booking_classes o WHERE o.cabin = 'Y'
+             ) ELSE 0 END,
+       (SELECT COUNT(*) FROM tickets t
+        WHERE t.flight_id = f.id AND t.booking_class = bc.code AND t.status IN ('ACTIVE', 'PENDING_PAYMENT')),
+       ROUND(f.base_price * bc.fare_multiplier, 2)
+FROM flights f
+JOIN flight_inventory fi ON fi.flight_id = f.id
+CROSS JOIN booking_classes bc
+WHERE bc.cabin = 'Y'
+ON CONFLICT (flight_id, booking_class) DO NOTHING;
+
+-- Waitlisted passengers ask for a booking class; offers carry the class of the freed seat.
+ALTER TABLE waitlist_entries ADD COLUMN IF NOT EXISTS booking_class CHAR(1) NOT NULL DEFAULT 'Y' REFERENCES booking_classes(code);
+ALTER TABLE waitlist_entries ADD COLUMN IF NOT EXISTS offered_booking_class CHAR(1) REFERENCES booking_classes(code);
+
+ALTER TABLE price_quotes ADD COLUMN IF NOT EXISTS booking_class CHAR(1) NOT NULL DEFAULT 'Y' REFERENCES booking_classes(code);
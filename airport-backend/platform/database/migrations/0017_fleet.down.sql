@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS equipment_changes;
+DROP INDEX IF EXISTS idx_tickets_reseat;
+ALTER TABLE tickets DROP COLUMN IF EXISTS reseat_required;
+ALTER TABLE flights DROP COLUMN IF EXISTS aircraft_id;
+DROP TABLE IF EXISTS aircraft;
+DROP TABLE IF EXISTS aircraft_types;
+ALTER TABLE cabin_layouts DROP CONSTRAINT IF EXISTS chk_cabin_layouts_zones;
+ALTER TABLE cabin_layouts DROP COLUMN IF EXISTS business_rows;
+ALTER TABLE cabin_layouts DROP COLUMN IF EXISTS first_rows;
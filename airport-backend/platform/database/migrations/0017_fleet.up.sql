@@ -0,0 +1,51 @@
+-- Cabin zoning: the first first_rows rows are first class, the next business_rows rows are
+-- business class and the rest are economy.
+ALTER TABLE cabin_layouts ADD COLUMN IF NOT EXISTS first_rows INT NOT NULL DEFAULT 0 CHECK (first_rows >= 0);
+ALTER TABLE cabin_layouts ADD COLUMN IF NOT EXISTS business_rows INT NOT NULL DEFAULT 0 CHECK (business_rows >= 0);
+ALTER TABLE cabin_layouts ADD CONSTRAINT chk_cabin_layouts_zones CHECK (first_rows + business_rows <= row_count);
+
+CREATE TABLE IF NOT EXISTS aircraft_types (
+    id             BIGSERIAL PRIMARY KEY,
+    code           VARCHAR(4)    NOT NULL UNIQUE, -- ICAO type designator, e.g. A320
+    name           VARCHAR(64)   NOT NULL,
+    manufacturer   VARCHAR(64)   NOT NULL,
+    max_baggage_kg NUMERIC(8, 1) NOT NULL CHECK (max_baggage_kg > 0), -- Checked baggage the hold takes
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+-- Individual airframes. Each carries its own seat configuration as a cabin layout.
+CREATE TABLE IF NOT EXISTS aircraft (
+    id               BIGSERIAL PRIMARY KEY,
+    tail_number      VARCHAR(10) NOT NULL UNIQUE,
+    aircraft_type_id BIGINT      NOT NULL REFERENCES aircraft_types(id),
+    cabin_layout_id  BIGINT      NOT NULL REFERENCES cabin_layouts(id),
+    status           VARCHAR(16) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'RETIRED')),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_aircraft_type ON aircraft (aircraft_type_id);
+
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS aircraft_id BIGINT REFERENCES aircraft(id);
+CREATE INDEX IF NOT EXISTS idx_flights_aircraft ON flights (aircraft_id);
+
+-- Set when an equipment change leaves a ticket's seat missing or in another cabin; cleared
+-- when the passenger picks a new seat.
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS reseat_required BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_tickets_reseat ON tickets (flight_id) WHERE reseat_required;
+
+CREATE TABLE IF NOT EXISTS equipment_changes (
+    id               BIGSERIAL PRIMARY KEY,
+    flight_id        BIGINT      NOT NULL REFERENCES flights(id) ON DELETE CASCADE,
+    from_aircraft_id BIGINT      REFERENCES aircraft(id),
+    to_aircraft_id   BIGINT      REFERENCES aircraft(id),
+    from_layout_id   BIGINT      REFERENCES cabin_layouts(id),
+    to_layout_id     BIGINT      NOT NULL REFERENCES cabin_layouts(id),
+    from_capacity    INT         NOT NULL,
+    to_capacity      INT         NOT NULL,
+    reseat_count     INT         NOT NULL DEFAULT 0, -- Tickets flagged for a new seat
+    changed_by       BIGINT      REFERENCES users(id),
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_equipment_changes_flight ON equipment_changes (flight_id, created_at);
@@ -0,0 +1,17 @@
+-- Airport reference data, keyed by IATA code. Flights refer to airports by IATA code; the
+-- time zone decides what "a day" means when searching departures from the airport.
+CREATE TABLE IF NOT EXISTS airports (
+    id         BIGSERIAL PRIMARY KEY,
+    iata_code  CHAR(3)       NOT NULL UNIQUE,
+    icao_code  CHAR(4)       UNIQUE,
+    name       VARCHAR(128)  NOT NULL,
+    city       VARCHAR(64)   NOT NULL DEFAULT '',
+    country    VARCHAR(64)   NOT NULL DEFAULT '',
+    timezone   VARCHAR(64)   NOT NULL, -- IANA time zone, e.g. Europe/London
+    latitude   NUMERIC(9, 6) NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude  NUMERIC(9, 6) NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    created_at TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_airports_city ON airports (LOWER(city));
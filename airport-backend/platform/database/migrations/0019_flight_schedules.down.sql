@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_flights_review;
+ALTER TABLE flights DROP COLUMN IF EXISTS review_required;
+DROP INDEX IF EXISTS uq_flights_schedule_date;
+ALTER TABLE flights DROP COLUMN IF EXISTS schedule_date;
+ALTER TABLE flights DROP COLUMN IF EXISTS schedule_id;
+DROP TABLE IF EXISTS flight_schedules;
@@ -0,0 +1,31 @@
+-- Recurring schedules. A generator materialises one flight per operating day over a rolling
+-- horizon; departure_local is in the origin airport's time zone.
+CREATE TABLE IF NOT EXISTS flight_schedules (
+    id               BIGSERIAL PRIMARY KEY,
+    flight_no        VARCHAR(16)    NOT NULL,
+    origin           CHAR(3)        NOT NULL,
+    destination      CHAR(3)        NOT NULL,
+    departure_local  TIME           NOT NULL,
+    block_minutes    INT            NOT NULL CHECK (block_minutes > 0),
+    days_of_week     CHAR(7)        NOT NULL, -- Monday first, digit when operated and '.' when not, e.g. 1.3.5..
+    effective_from   DATE           NOT NULL,
+    effective_to     DATE           NOT NULL,
+    aircraft_type_id BIGINT         NOT NULL REFERENCES aircraft_types(id),
+    cabin_layout_id  BIGINT         REFERENCES cabin_layouts(id), -- Seat configuration of generated flights; 150 economy seats if unset
+    base_price       NUMERIC(12, 2) NOT NULL DEFAULT 32000.00,
+    version          INT            NOT NULL DEFAULT 1,
+    created_by       BIGINT         REFERENCES users(id),
+    created_at       TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    CHECK (effective_to >= effective_from),
+    CHECK (origin <> destination)
+);
+
+-- A schedule has at most one flight per operating date, whatever became of it.
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS schedule_id BIGINT REFERENCES flight_schedules(id);
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS schedule_date DATE;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_flights_schedule_date ON flights (schedule_id, schedule_date);
+
+-- Set when a schedule edit could not be applied to a flight because it is booked or under way.
+ALTER TABLE flights ADD COLUMN IF NOT EXISTS review_required BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_flights_review ON flights (schedule_id) WHERE review_required;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_flights_destination_departure;
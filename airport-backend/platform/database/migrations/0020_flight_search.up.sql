@@ -0,0 +1,3 @@
+-- Searches by destination alone; origin and origin-destination searches use
+-- idx_flights_route_departure.
+CREATE INDEX IF NOT EXISTS idx_flights_destination_departure ON flights (destination, departure_time);
@@ -0,0 +1 @@
+ALTER TABLE airports DROP COLUMN IF EXISTS min_connection_minutes;
@@ -0,0 +1,5 @@
+-- Minimum connection time at each airport: the least time between a leg's arrival and the
+-- next leg's departure for the two to be sold as one itinerary.
+ALTER TABLE airports
+    ADD COLUMN IF NOT EXISTS min_connection_minutes INT NOT NULL DEFAULT 45
+        CHECK (min_connection_minutes BETWEEN 0 AND 1440);
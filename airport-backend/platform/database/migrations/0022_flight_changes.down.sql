@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notifications;
+ALTER TABLE tickets DROP COLUMN IF EXISTS rebook_required;
@@ -0,0 +1,20 @@
+-- Set on the active tickets of a flight that was cancelled or retimed by three hours or more:
+-- the passenger may move to another flight on the route or cancel for a full refund. Cleared
+-- when the ticket is rebooked.
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS rebook_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Messages to account holders about changes to their bookings, such as retimed or cancelled
+-- flights.
+CREATE TABLE IF NOT EXISTS notifications (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind       VARCHAR(32) NOT NULL,
+    flight_id  BIGINT      REFERENCES flights(id) ON DELETE CASCADE,
+    ticket_id  BIGINT      REFERENCES tickets(id) ON DELETE CASCADE,
+    message    TEXT        NOT NULL,
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
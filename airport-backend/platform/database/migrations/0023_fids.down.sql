@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_flights_destination_arrival;
+DROP TRIGGER IF EXISTS trg_gate_assignments_fids ON gate_assignments;
+DROP TRIGGER IF EXISTS trg_flights_fids ON flights;
+DROP FUNCTION IF EXISTS notify_fids_gate_assignment();
+DROP FUNCTION IF EXISTS notify_fids_flight();
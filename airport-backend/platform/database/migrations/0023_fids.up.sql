@@ -0,0 +1,35 @@
+-- Flight information display boards follow flights and gate assignments as they change. Each
+-- change announces the flight's ID on the fids_changes channel when its transaction commits;
+-- the API relays them to the screens streaming the board.
+CREATE OR REPLACE FUNCTION notify_fids_flight() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('fids_changes', NEW.id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_fids_gate_assignment() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('fids_changes', OLD.flight_id::text);
+    ELSE
+        PERFORM pg_notify('fids_changes', NEW.flight_id::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_flights_fids ON flights;
+CREATE TRIGGER trg_flights_fids
+    AFTER INSERT OR UPDATE OF flight_no, departure_time, arrival_time, estimated_departure_time,
+        estimated_arrival_time, status, gate_id
+    ON flights
+    FOR EACH ROW EXECUTE FUNCTION notify_fids_flight();
+
+DROP TRIGGER IF EXISTS trg_gate_assignments_fids ON gate_assignments;
+CREATE TRIGGER trg_gate_assignments_fids
+    AFTER INSERT OR UPDATE OR DELETE ON gate_assignments
+    FOR EACH ROW EXECUTE FUNCTION notify_fids_gate_assignment();
+
+-- Arrivals boards list flights by destination and arrival time.
+CREATE INDEX IF NOT EXISTS idx_flights_destination_arrival ON flights (destination, arrival_time);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payment_refunds_late_payment;
+ALTER TABLE payment_refunds DROP CONSTRAINT IF EXISTS payment_refunds_ticket_or_intent;
+DELETE FROM payment_refunds WHERE ticket_id IS NULL;
+ALTER TABLE payment_refunds ALTER COLUMN ticket_id SET NOT NULL;
@@ -0,0 +1,7 @@
+-- A payment the gateway captures after its intent was closed is refunded in full. Such refunds
+-- belong to the intent rather than to a ticket, as the tickets were already released.
+ALTER TABLE payment_refunds ALTER COLUMN ticket_id DROP NOT NULL;
+ALTER TABLE payment_refunds ADD CONSTRAINT payment_refunds_ticket_or_intent
+    CHECK (ticket_id IS NOT NULL OR intent_id IS NOT NULL);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_refunds_late_payment ON payment_refunds (intent_id) WHERE ticket_id IS NULL;
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"airport-system/platform/apperror"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code is an extension member carrying the
+// machine-readable error code.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// ErrorHandler renders the last error a handler attached with c.Error as problem+json.
+// Typed apperror errors keep their message; anything else is logged and reported as a
+// generic internal error so SQL and other internals never reach clients.
+func ErrorHandler(log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		appErr, ok := apperror.As(err)
+		detail := err.Error()
+		if !ok {
+			appErr = apperror.New(apperror.KindInternal, "internal_error", "an unexpected error occurred")
+			if isUnavailable(err) {
+				appErr = apperror.ErrUnavailable
+			}
+			detail = appErr.Message
+			log.Error("Request failed", "method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+		}
+
+		status := appErr.Kind.Status()
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(status, Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(status),
+			Status:   status,
+			Detail:   detail,
+			Instance: c.Request.URL.Path,
+			Code:     appErr.Code,
+		})
+	}
+}
+
+// isUnavailable reports whether err is a transient database or network failure.
+func isUnavailable(err error) bool {
+	var netErr *net.OpError
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.As(err, &netErr)
+}
@@ -12,7 +12,35 @@ class Api {
         return headers;
     }
 
-    static async request(endpoint, method = 'GET', body = null) {
+    // Exchanges the stored refresh token for a new token pair. Returns false if it was rejected.
+    static async refreshSession() {
+        const refreshToken = localStorage.getItem('refresh_token');
+        if (!refreshToken) {
+            return false;
+        }
+
+        const response = await fetch(`${API_BASE_URL}/auth/refresh`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ refresh_token: refreshToken }),
+        });
+        if (!response.ok) {
+            return false;
+        }
+
+        const data = await response.json();
+        localStorage.setItem('token', data.token);
+        localStorage.setItem('refresh_token', data.refresh_token);
+        return true;
+    }
+
+    static clearSession() {
+        localStorage.removeItem('token');
+        localStorage.removeItem('refresh_token');
+        localStorage.removeItem('user');
+    }
+
+    static async request(endpoint, method = 'GET', body = null, retried = false) {
         const config = {
             method,
             headers: this.getHeaders(),
@@ -26,16 +54,22 @@ class Api {
             const response = await fetch(`${API_BASE_URL}${endpoint}`, config);
 
             if (response.status === 401) {
-                // Token expired or invalid
-                localStorage.removeItem('token');
-                localStorage.removeItem('user');
+                // Access token expired: try once to refresh it, then give up and re-login
+                if (!retried && !endpoint.startsWith('/auth/') && await this.refreshSession()) {
+                    return this.request(endpoint, method, body, true);
+                }
+                this.clearSession();
                 window.location.href = 'login.html';
                 return;
             }
 
             if (!response.ok) {
-                const errorData = await response.json();
-                throw new Error(errorData.error || `Request failed with status ${response.status}`);
+                // Errors are RFC 7807 problem+json: { type, title, status, detail, code }
+                const problem = await response.json().catch(() => ({}));
+                const error = new Error(problem.detail || problem.error || `Request failed with status ${response.status}`);
+                error.code = problem.code;
+                error.status = response.status;
+                throw error;
             }
 
             return await response.json();
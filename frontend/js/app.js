@@ -5,6 +5,7 @@ document.addEventListener('DOMContentLoaded', () => {
     const searchForm = document.getElementById('search-form');
     if (searchForm) {
         searchForm.addEventListener('submit', handleSearch);
+        document.getElementById('load-more').addEventListener('click', loadMoreFlights);
     }
 
     // Initial load (optional, maybe showing all upcoming flights?)
@@ -54,39 +55,72 @@ function updateNavbar() {
     }
 }
 
-function logout() {
-    localStorage.removeItem('token');
-    localStorage.removeItem('user');
+async function logout() {
+    try {
+        await Api.post('/auth/logout');
+    } catch (error) {
+        // Session is dropped locally either way
+    }
+    Api.clearSession();
     window.location.href = 'login.html';
 }
 
+// Search state for paging through results
+let searchParams = null;
+let nextCursor = null;
+
 async function handleSearch(e) {
     e.preventDefault();
     const origin = document.getElementById('origin').value;
     const destination = document.getElementById('destination').value;
     const date = document.getElementById('date').value;
+    const sort = document.getElementById('sort').value;
+    const maxPrice = document.getElementById('maxPrice').value;
+    const minSeats = document.getElementById('minSeats').value;
 
     // Build query string
     const params = new URLSearchParams();
     if (origin) params.append('origin', origin);
     if (destination) params.append('destination', destination);
     if (date) params.append('date', date);
+    if (sort) params.append('sort', sort);
+    if (maxPrice) params.append('max_price', maxPrice);
+    if (minSeats) params.append('min_seats', minSeats);
+
+    searchParams = params;
+    nextCursor = null;
+    document.getElementById('flights-container').innerHTML = '';
+    await fetchFlights();
+}
+
+async function loadMoreFlights() {
+    if (nextCursor) {
+        await fetchFlights();
+    }
+}
+
+async function fetchFlights() {
+    const params = new URLSearchParams(searchParams);
+    if (nextCursor) params.append('cursor', nextCursor);
 
     try {
-        const flights = await Api.get(`/flights?${params.toString()}`);
-        renderFlights(flights);
+        const result = await Api.get(`/flights?${params.toString()}`);
+        renderFlights(result.flights, Boolean(nextCursor));
+        nextCursor = result.next_cursor || null;
+        document.getElementById('load-more').classList.toggle('d-none', !nextCursor);
     } catch (error) {
         console.error('Search failed:', error);
         alert('Failed to search flights');
     }
 }
 
-function renderFlights(flights) {
+function renderFlights(flights, append) {
     const container = document.getElementById('flights-container');
-    container.innerHTML = '';
 
     if (!flights || flights.length === 0) {
-        container.innerHTML = '<div class="col-12 text-center text-muted">No flights found</div>';
+        if (!append) {
+            container.innerHTML = '<div class="col-12 text-center text-muted">No flights found</div>';
+        }
         return;
     }
 
@@ -97,12 +131,14 @@ function renderFlights(flights) {
         const statusBadge = getStatusBadge(flight.status);
         const date = new Date(flight.departure_time).toLocaleDateString();
         const time = new Date(flight.departure_time).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' });
+        const duration = `${Math.floor(flight.duration_minutes / 60)}h ${flight.duration_minutes % 60}m`;
+        const price = flight.lowest_fare != null ? `from ${flight.lowest_fare.toFixed(2)}` : 'Sold out';
 
         card.innerHTML = `
             <div class="card h-100">
                 <div class="card-body">
                     <div class="d-flex justify-content-between align-items-center mb-3">
-                        <h5 class="card-title mb-0 fw-bold">${flight.flight_number}</h5>
+                        <h5 class="card-title mb-0 fw-bold">${flight.flight_no}</h5>
                         <span class="badge ${statusBadge}">${flight.status}</span>
                     </div>
                     <div class="d-flex justify-content-between mb-2">
@@ -117,10 +153,14 @@ function renderFlights(flights) {
                     </div>
                     <div class="mb-3">
                         <small class="text-muted">Departure</small>
-                        <div class="fw-medium">${date} at ${time}</div>
+                        <div class="fw-medium">${date} at ${time} · ${duration}</div>
+                    </div>
+                    <div class="d-flex justify-content-between mb-3">
+                        <div class="fw-medium">${price}</div>
+                        <small class="text-muted">${flight.seats_available} seats left</small>
                     </div>
                     <div class="d-grid">
-                        <button class="btn btn-accent btn-sm" onclick="openBookingModal(${flight.id}, '${flight.flight_number}')">
+                        <button class="btn btn-accent btn-sm" onclick="openBookingModal(${flight.id}, '${flight.flight_no}')">
                             Book Flight
                         </button>
                     </div>
@@ -142,6 +182,7 @@ function getStatusBadge(status) {
 
 // Booking Modal Logic
 let currentFlightId = null;
+let currentQuoteId = null;
 
 window.openBookingModal = (flightId, flightNumber) => {
     const token = localStorage.getItem('token');
@@ -151,11 +192,47 @@ window.openBookingModal = (flightId, flightNumber) => {
     }
 
     currentFlightId = flightId;
+    currentQuoteId = null;
     document.getElementById('bookingFlightNumber').textContent = flightNumber;
     const modal = new bootstrap.Modal(document.getElementById('bookingModal'));
     modal.show();
+    loadFares(flightId);
 };
 
+// Lists the booking classes still on sale, cheapest economy fare first
+async function loadFares(flightId) {
+    const select = document.getElementById('bookingClass');
+    select.innerHTML = '';
+    try {
+        const fares = await Api.get(`/flights/${flightId}/fares`);
+        fares.filter(f => f.available > 0)
+            .sort((a, b) => a.fare - b.fare)
+            .forEach(f => select.add(new Option(`${f.name} (${f.booking_class}) - ${f.available} left`, f.booking_class)));
+    } catch (error) {
+        // Booking falls back to the cheapest economy class
+    }
+    lockPrice(flightId, select.value);
+}
+
+document.getElementById('bookingClass').addEventListener('change', (e) => {
+    lockPrice(currentFlightId, e.target.value);
+});
+
+// Locks the flight's current price so the booking is charged what was shown
+async function lockPrice(flightId, bookingClass) {
+    const priceEl = document.getElementById('bookingPrice');
+    priceEl.textContent = 'Fetching price...';
+    currentQuoteId = null;
+    try {
+        const quote = await Api.post(`/flights/${flightId}/price-quotes`, bookingClass ? { booking_class: bookingClass } : undefined);
+        currentQuoteId = quote.id;
+        const until = new Date(quote.expires_at).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' });
+        priceEl.textContent = `Price: ${quote.amount} ${quote.currency} (held until ${until})`;
+    } catch (error) {
+        priceEl.textContent = 'Price will be confirmed at booking.';
+    }
+}
+
 document.getElementById('confirmBookingBtn').addEventListener('click', async () => {
     const passport = document.getElementById('passport').value;
     const phone = document.getElementById('phone').value;
@@ -169,15 +246,31 @@ document.getElementById('confirmBookingBtn').addEventListener('click', async ()
         await Api.post('/bookings', {
             flight_id: currentFlightId,
             passport_number: passport,
-            phone: phone
+            phone: phone,
+            booking_class: document.getElementById('bookingClass').value || undefined,
+            quote_id: currentQuoteId
             // Add other fields if required by backend, e.g. seat_number? API spec says passport & phone.
         });
-        alert('Booking confirmed!');
+        alert('Booking created! Complete the payment in My Bookings before it expires.');
         bootstrap.Modal.getInstance(document.getElementById('bookingModal')).hide();
         // clear form
         document.getElementById('passport').value = '';
         document.getElementById('phone').value = '';
     } catch (error) {
+        if (error.code === 'flight_full' && confirm('This flight is full. Join its waitlist?')) {
+            joinWaitlist(currentFlightId);
+            return;
+        }
         alert('Booking failed: ' + error.message);
     }
 });
+
+async function joinWaitlist(flightId) {
+    try {
+        const entry = await Api.post(`/flights/${flightId}/waitlist`);
+        alert(`You are number ${entry.position} on the waitlist. Check My Bookings for a seat offer.`);
+        bootstrap.Modal.getInstance(document.getElementById('bookingModal')).hide();
+    } catch (error) {
+        alert('Failed to join waitlist: ' + error.message);
+    }
+}
@@ -1,6 +1,7 @@
 document.addEventListener('DOMContentLoaded', () => {
     const loginForm = document.getElementById('login-form');
     const registerForm = document.getElementById('register-form');
+    const acceptInviteForm = document.getElementById('accept-invite-form');
 
     if (loginForm) {
         loginForm.addEventListener('submit', async (e) => {
@@ -17,6 +18,7 @@ document.addEventListener('DOMContentLoaded', () => {
                 // Adjust based on actual API response structure
                 if (data.token) {
                     localStorage.setItem('token', data.token);
+                    localStorage.setItem('refresh_token', data.refresh_token);
                     if (data.user) {
                         localStorage.setItem('user', JSON.stringify(data.user));
                     }
@@ -47,4 +49,31 @@ document.addEventListener('DOMContentLoaded', () => {
             }
         });
     }
+
+    if (acceptInviteForm) {
+        // The invitation link carries its one-time token as ?token=
+        const token = new URLSearchParams(window.location.search).get('token');
+        if (!token) {
+            document.getElementById('invite-missing').classList.remove('d-none');
+            acceptInviteForm.classList.add('d-none');
+        }
+
+        acceptInviteForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            const password = document.getElementById('password').value;
+            const confirmPassword = document.getElementById('confirm_password').value;
+            if (password !== confirmPassword) {
+                alert('Passwords do not match');
+                return;
+            }
+
+            try {
+                await Api.post('/auth/invitations/accept', { token, password });
+                alert('Invitation accepted! Please login.');
+                window.location.href = 'login.html';
+            } catch (error) {
+                alert('Could not accept invitation: ' + error.message);
+            }
+        });
+    }
 });